@@ -1,3 +1,10 @@
-module go-uml-generator
+module github.com/nichtaru64/go-uml-generator
 
 go 1.22.3
+
+require golang.org/x/tools v0.30.0
+
+require (
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+)
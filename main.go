@@ -0,0 +1,8 @@
+// Kommandozeile von go-uml-generator, der Generator selbst liegt im Paket umlgen
+package main
+
+import "github.com/nichtaru64/go-uml-generator/umlgen"
+
+func main() {
+	umlgen.Main()
+}
@@ -0,0 +1,108 @@
+package umlgen
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxAltTextRelations begrenzt die Zahl der im Alternativtext genannten Beziehungen
+const maxAltTextRelations = 10
+
+// altTextRelationOrder gewichtet Beziehungsarten nach Aussagekraft
+var altTextRelationOrder = map[string]int{
+	"extends":     0,
+	"implements":  1,
+	"composition": 2,
+	"aggregation": 3,
+	"association": 4,
+	"creates":     5,
+	"exposes":     6,
+	"builds":      7,
+	"alias":       8,
+	"uses":        9,
+	"casts":       10,
+	"constrains":  11,
+}
+
+// describeRelation formuliert eine Beziehung als kurzen Satzteil
+func describeRelation(relation Relation) string {
+	switch relation.Type {
+	case "extends":
+		return fmt.Sprintf("%s bettet %s ein", relation.From, relation.To)
+	case "implements":
+		return fmt.Sprintf("%s implementiert %s", relation.From, relation.To)
+	case "composition":
+		return fmt.Sprintf("%s enthält %s", relation.From, relation.To)
+	case "aggregation":
+		return fmt.Sprintf("%s verweist auf %s", relation.From, relation.To)
+	case "association":
+		return fmt.Sprintf("%s ist verbunden mit %s", relation.From, relation.To)
+	case "uses":
+		return fmt.Sprintf("%s verwendet %s", relation.From, relation.To)
+	case "casts":
+		return fmt.Sprintf("%s prüft auf %s", relation.From, relation.To)
+	case "creates":
+		return fmt.Sprintf("%s erzeugt %s", relation.From, relation.To)
+	case "exposes":
+		return fmt.Sprintf("%s liefert %s", relation.From, relation.To)
+	case "builds":
+		return fmt.Sprintf("%s baut %s", relation.From, relation.To)
+	case "alias":
+		return fmt.Sprintf("%s ist ein Alias für %s", relation.From, relation.To)
+	case "constrains":
+		return fmt.Sprintf("%s beschränkt %s durch %s", relation.From, relation.Label, relation.To)
+	}
+	return fmt.Sprintf("%s %s %s", relation.From, relation.Type, relation.To)
+}
+
+// AltText fasst das Diagramm in wenigen Sätzen zusammen: enthaltene Typen
+// und die wichtigsten Beziehungen, z.B. als Alternativtext in Dokumentation
+func (g *UMLGenerator) AltText() string {
+	types := g.Types()
+	if len(types) == 0 {
+		return "Leeres Klassendiagramm."
+	}
+
+	var sb strings.Builder
+	packages := g.packageNames()
+	packageWord := "Paket"
+	if len(packages) > 1 {
+		packageWord = "Paketen"
+	}
+	sb.WriteString(fmt.Sprintf("Klassendiagramm mit %d Typen aus %s %s.", len(types), packageWord, strings.Join(packages, ", ")))
+
+	var names []string
+	for _, typeInfo := range types {
+		names = append(names, fmt.Sprintf("%s (%s)", typeInfo.Name, typeInfo.Kind))
+	}
+	sb.WriteString(" Typen: " + strings.Join(names, ", ") + ".")
+
+	relations := append([]Relation{}, g.relations...)
+	sort.SliceStable(relations, func(i, j int) bool {
+		return altTextRelationOrder[relations[i].Type] < altTextRelationOrder[relations[j].Type]
+	})
+	if len(relations) > 0 {
+		var parts []string
+		for i, relation := range relations {
+			if i == maxAltTextRelations {
+				parts = append(parts, fmt.Sprintf("und %d weitere", len(relations)-maxAltTextRelations))
+				break
+			}
+			parts = append(parts, describeRelation(relation))
+		}
+		sb.WriteString(" Beziehungen: " + strings.Join(parts, "; ") + ".")
+	}
+
+	return sb.String()
+}
+
+// writeAltText schreibt den Alternativtext als <fileName>.alt.txt neben das Diagramm
+func (g *UMLGenerator) writeAltText(outputDir, fileName string) error {
+	path := filepath.Join(outputDir, fileName+".alt.txt")
+	if err := writeFileFrom(path, strings.NewReader(g.AltText()+"\n"), g.fileMode()); err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Alternativtexts: %v", err)
+	}
+	return g.recordArtifact(outputDir, path, "txt", "")
+}
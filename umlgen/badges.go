@@ -0,0 +1,75 @@
+package umlgen
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"strings"
+)
+
+// Badge-Farben im Stil von shields.io
+const (
+	badgeBlue   = "#007ec6"
+	badgeGreen  = "#4c1"
+	badgeYellow = "#dfb317"
+)
+
+// badgeCharWidth schätzt die Breite eines Zeichens in Verdana 11px
+const badgeCharWidth = 7
+
+// badgeSVG erzeugt ein flaches Badge mit Beschriftung und Wert
+func badgeSVG(label, value, color string) string {
+	labelWidth := len([]rune(label))*badgeCharWidth + 10
+	valueWidth := len([]rune(value))*badgeCharWidth + 10
+	width := labelWidth + valueWidth
+	label = html.EscapeString(label)
+	value = html.EscapeString(value)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`+"\n", width, label, value))
+	sb.WriteString(fmt.Sprintf(`<title>%s: %s</title>`+"\n", label, value))
+	sb.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>` + "\n")
+	sb.WriteString(fmt.Sprintf(`<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`+"\n", width))
+	sb.WriteString(`<g clip-path="url(#r)">` + "\n")
+	sb.WriteString(fmt.Sprintf(`<rect width="%d" height="20" fill="#555"/>`+"\n", labelWidth))
+	sb.WriteString(fmt.Sprintf(`<rect x="%d" width="%d" height="20" fill="%s"/>`+"\n", labelWidth, valueWidth, color))
+	sb.WriteString(fmt.Sprintf(`<rect width="%d" height="20" fill="url(#s)"/>`+"\n", width))
+	sb.WriteString("</g>\n")
+	sb.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">` + "\n")
+	sb.WriteString(fmt.Sprintf(`<text x="%d" y="14">%s</text>`+"\n", labelWidth/2, label))
+	sb.WriteString(fmt.Sprintf(`<text x="%d" y="14">%s</text>`+"\n", labelWidth+valueWidth/2, value))
+	sb.WriteString("</g>\n</svg>\n")
+	return sb.String()
+}
+
+// GenerateBadges schreibt Badges für Typ- und Paketanzahl sowie den Status
+// der Prüfungen (Warnungen) als badge_<name>.svg ins Ausgabeverzeichnis
+func (g *UMLGenerator) GenerateBadges(outputDir string) error {
+	stats := g.Statistics()
+
+	status, statusColor := "ok", badgeGreen
+	if stats.Warnings > 0 {
+		status, statusColor = fmt.Sprintf("%d Warnungen", stats.Warnings), badgeYellow
+	}
+
+	badges := []struct {
+		name, label, value, color string
+	}{
+		{"types", "Typen", fmt.Sprint(stats.Structs + stats.Interfaces + stats.NamedTypes), badgeBlue},
+		{"packages", "Pakete", fmt.Sprint(stats.Packages), badgeBlue},
+		{"relations", "Beziehungen", fmt.Sprint(stats.relationCount()), badgeBlue},
+		{"architecture", "Architektur", status, statusColor},
+	}
+
+	for _, badge := range badges {
+		path := filepath.Join(outputDir, "badge_"+badge.name+".svg")
+		if err := writeFileFrom(path, strings.NewReader(badgeSVG(badge.label, badge.value, badge.color)), g.fileMode()); err != nil {
+			return fmt.Errorf("Fehler beim Speichern des Badges: %v", err)
+		}
+		if err := g.recordArtifact(outputDir, path, "svg", ""); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Badges erstellt: %s\n", filepath.Join(outputDir, "badge_*.svg"))
+	return nil
+}
@@ -0,0 +1,273 @@
+package umlgen
+
+import (
+	"go/ast"
+)
+
+// analyzeBodies untersucht die Rümpfe aller Methoden (--analyze-bodies) und
+// ergänzt "uses"-Beziehungen zu bekannten Typen, die dort instanziiert
+// (Composite Literals) oder über Felder des Receivers aufgerufen werden.
+// Hat ein Paar bereits eine strukturelle Beziehung, erhöht jede Verwendung
+// deren Gewicht. Type Assertions und Type Switches auf bekannte Typen ergeben
+// "casts"-Beziehungen.
+func (g *UMLGenerator) analyzeBodies() {
+	existing := make(map[[2]string]int) // Paar -> Index der ersten Beziehung
+	for i, relation := range g.relations {
+		key := [2]string{relation.From, relation.To}
+		if _, ok := existing[key]; !ok {
+			existing[key] = i
+		}
+	}
+	casts := make(map[[2]string]int)
+
+	for _, filePath := range sortedMapKeys(g.files) {
+		file := g.files[filePath]
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 || funcDecl.Body == nil {
+				continue
+			}
+
+			typeName := g.typeKey(g.fileScope(file), receiverTypeName(funcDecl.Recv.List[0].Type))
+			if !g.isClassType(typeName) {
+				continue
+			}
+
+			for _, used := range g.usedTypes(file, typeName, funcDecl) {
+				key := [2]string{typeName, used.name}
+				if used.name == typeName {
+					continue
+				}
+
+				relationType, seen := "uses", existing
+				if used.cast {
+					relationType, seen = "casts", casts
+				}
+				if i, ok := seen[key]; ok {
+					g.relations[i].Weight = g.relations[i].weight() + 1
+					continue
+				}
+				seen[key] = len(g.relations)
+
+				g.relations = append(g.relations, Relation{
+					From:   typeName,
+					To:     used.name,
+					Type:   relationType,
+					Pos:    used.pos,
+					Weight: 1,
+				})
+			}
+		}
+	}
+}
+
+// usedType ist ein im Methodenrumpf verwendeter Typ samt Fundstelle
+type usedType struct {
+	name string
+	pos  Position
+	cast bool // Verwendung in Type Assertion oder Type Switch
+}
+
+// usedTypes sammelt die in einem Methodenrumpf verwendeten bekannten Typen
+func (g *UMLGenerator) usedTypes(file *ast.File, typeName string, funcDecl *ast.FuncDecl) []usedType {
+	// Receiver-Name, um Zugriffe wie r.db.Query() zu erkennen
+	receiverName := ""
+	if names := funcDecl.Recv.List[0].Names; len(names) > 0 {
+		receiverName = names[0].Name
+	}
+
+	// Feldtypen des Receivers
+	fieldTargets := make(map[string]string)
+	if structInfo, ok := g.structs[typeName]; ok {
+		for _, field := range structInfo.Fields {
+			fieldTargets[field.Name] = field.Target
+		}
+	}
+
+	var used []usedType
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.CompositeLit:
+			// Instanziierung: T{...} oder &T{...}
+			if node.Type != nil {
+				target, _ := containerTarget(node.Type)
+				if target = g.modelTarget(file, target); g.isKnownType(target) {
+					used = append(used, usedType{name: target, pos: g.position(node.Pos())})
+				}
+			}
+		case *ast.TypeAssertExpr:
+			// x.(T); bei Type Switches ist Type nil und die Fälle folgen unten
+			if node.Type != nil {
+				target, _ := containerTarget(node.Type)
+				if target = g.modelTarget(file, target); g.isKnownType(target) {
+					used = append(used, usedType{name: target, pos: g.position(node.Pos()), cast: true})
+				}
+			}
+		case *ast.TypeSwitchStmt:
+			for _, stmt := range node.Body.List {
+				clause, ok := stmt.(*ast.CaseClause)
+				if !ok {
+					continue
+				}
+				for _, expr := range clause.List {
+					target, _ := containerTarget(expr)
+					if target = g.modelTarget(file, target); g.isKnownType(target) {
+						used = append(used, usedType{name: target, pos: g.position(expr.Pos()), cast: true})
+					}
+				}
+			}
+		case *ast.CallExpr:
+			// Methodenaufruf auf einem Feld des Receivers: r.feld.Methode()
+			sel, ok := node.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			fieldSel, ok := sel.X.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if ident, ok := fieldSel.X.(*ast.Ident); ok && ident.Name == receiverName && receiverName != "" {
+				if target := fieldTargets[fieldSel.Sel.Name]; g.isKnownType(target) {
+					used = append(used, usedType{name: target, pos: g.position(node.Pos())})
+				}
+			}
+		}
+		return true
+	})
+
+	return used
+}
+
+// isKnownType prüft, ob ein Name ein Typ des Modells ist
+func (g *UMLGenerator) isKnownType(name string) bool {
+	if g.isClassType(name) {
+		return true
+	}
+	_, ok := g.interfaces[name]
+	return ok
+}
+
+// detectFactories erkennt Paketfunktionen, die ein bekanntes Interface
+// zurückgeben und dabei eine konkrete Struct erzeugen, und ergänzt
+// "creates"- und "exposes"-Beziehungen
+func (g *UMLGenerator) detectFactories() {
+	var factories []*FactoryInfo
+	scopes := make(map[*FactoryInfo]string)
+	declared := make(map[string][]string) // Funktionsname -> Pakete
+	for _, filePath := range sortedMapKeys(g.files) {
+		file := g.files[filePath]
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Recv != nil || funcDecl.Body == nil {
+				continue
+			}
+			if funcDecl.Type.Results == nil || len(funcDecl.Type.Results.List) == 0 {
+				continue
+			}
+
+			// Erster Rückgabewert muss ein bekanntes Interface sein, z.B. (Store, error)
+			exposes := g.modelTarget(file, getTypeString(funcDecl.Type.Results.List[0].Type))
+			if _, ok := g.interfaces[exposes]; !ok {
+				continue
+			}
+
+			creates := g.createdTypes(file, funcDecl.Body)
+			if len(creates) == 0 {
+				continue
+			}
+
+			factory := &FactoryInfo{
+				Name:    funcDecl.Name.Name,
+				Package: file.Name.Name,
+				Pos:     g.position(funcDecl.Pos()),
+				Exposes: exposes,
+				Creates: creates,
+			}
+			scope := g.fileScope(file)
+			factories = append(factories, factory)
+			scopes[factory] = scope
+			declared[factory.Name] = appendUnique(declared[factory.Name], scope)
+		}
+	}
+
+	// Gleichnamige Factories verschiedener Pakete werden wie Typen qualifiziert
+	for _, factory := range factories {
+		scope := scopes[factory]
+		key := factory.Name
+		if others := declared[factory.Name]; len(others) > 1 {
+			key = g.scopeQualifier(scope, others) + "." + factory.Name
+		}
+		g.factories[key] = factory
+		g.typePaths[key] = g.scopePaths[scope]
+
+		for _, created := range factory.Creates {
+			g.relations = append(g.relations, Relation{From: key, To: created, Type: "creates", Pos: factory.Pos})
+		}
+		g.relations = append(g.relations, Relation{From: key, To: factory.Exposes, Type: "exposes", Pos: factory.Pos})
+	}
+}
+
+// createdTypes liefert die konkreten Typen, die ein Funktionsrumpf
+// zurückgibt: direkt als Composite Literal (return &T{}) oder über eine
+// lokale Variable, der ein Composite Literal zugewiesen wurde
+func (g *UMLGenerator) createdTypes(file *ast.File, body *ast.BlockStmt) []string {
+	// Lokale Variablen, die mit einem Composite Literal belegt werden
+	locals := make(map[string]string)
+	ast.Inspect(body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != len(assign.Rhs) {
+			return true
+		}
+		for i, lhs := range assign.Lhs {
+			if ident, ok := lhs.(*ast.Ident); ok {
+				if name := g.literalType(file, assign.Rhs[i]); name != "" {
+					locals[ident.Name] = name
+				}
+			}
+		}
+		return true
+	})
+
+	seen := make(map[string]bool)
+	var creates []string
+	ast.Inspect(body, func(n ast.Node) bool {
+		// Rümpfe verschachtelter Funktionsliterale gehören nicht zur Factory
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) == 0 {
+			return true
+		}
+
+		name := g.literalType(file, ret.Results[0])
+		if ident, ok := ret.Results[0].(*ast.Ident); ok && name == "" {
+			name = locals[ident.Name]
+		}
+		if name != "" && !seen[name] {
+			seen[name] = true
+			creates = append(creates, name)
+		}
+		return true
+	})
+
+	return creates
+}
+
+// literalType liefert den Modellschlüssel der Struct eines Ausdrucks der
+// Form T{} oder &T{}
+func (g *UMLGenerator) literalType(file *ast.File, expr ast.Expr) string {
+	if unary, ok := expr.(*ast.UnaryExpr); ok {
+		expr = unary.X
+	}
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok || lit.Type == nil {
+		return ""
+	}
+	name, _ := containerTarget(lit.Type)
+	name = g.modelTarget(file, name)
+	if _, ok := g.structs[name]; !ok {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,54 @@
+package umlgen
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// startBudget beginnt das Zeitbudget eines Generierungslaufs (--budget).
+// Ohne Budget bleibt die Frist leer und overBudget liefert immer false.
+func (g *UMLGenerator) startBudget() {
+	g.deadline = time.Time{}
+	g.budgetFiles = 0
+	if g.options.Budget > 0 {
+		g.deadline = time.Now().Add(g.options.Budget)
+	}
+}
+
+// overBudget meldet, ob das Zeitbudget aufgebraucht ist
+func (g *UMLGenerator) overBudget() bool {
+	return !g.deadline.IsZero() && time.Now().After(g.deadline)
+}
+
+// withinBudget prüft vor einem teuren Durchlauf, ob noch Zeit bleibt. Ist
+// das Budget aufgebraucht, wird der Durchlauf als übersprungen vermerkt.
+func (g *UMLGenerator) withinBudget(pass string) bool {
+	if !g.overBudget() {
+		return true
+	}
+	g.budgetSkipped = append(g.budgetSkipped, pass)
+	g.warn(WarningBudget, Position{}, "Zeitbudget von %s überschritten, %s übersprungen", g.options.Budget, pass)
+	return false
+}
+
+// budgetNote liefert den Hinweis für den Footer eines Teildiagramms oder ""
+// für ein vollständiges Diagramm
+func (g *UMLGenerator) budgetNote() string {
+	if len(g.budgetSkipped) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Teildiagramm: Zeitbudget von %s überschritten, übersprungen: %s",
+		g.options.Budget, strings.Join(g.budgetSkipped, ", "))
+}
+
+// skippedForBudget meldet, ob ein Arbeitsschritt wegen des Zeitbudgets
+// übersprungen wurde, damit auch seine Konsolenberichte entfallen
+func (g *UMLGenerator) skippedForBudget(pass string) bool {
+	for _, skipped := range g.budgetSkipped {
+		if skipped == pass {
+			return true
+		}
+	}
+	return false
+}
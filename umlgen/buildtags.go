@@ -0,0 +1,37 @@
+package umlgen
+
+import (
+	"go/build"
+	"path/filepath"
+	"strings"
+)
+
+// buildContext liefert die Build-Konfiguration, nach der Dateien mit
+// //go:build-Bedingungen oder Suffixen wie _windows.go ausgewählt werden:
+// die des aktuellen Systems, überschrieben durch --goos, --goarch und --tags
+func buildContext(options Options) *build.Context {
+	ctx := build.Default
+	if options.GOOS != "" {
+		ctx.GOOS = options.GOOS
+	}
+	if options.GOARCH != "" {
+		ctx.GOARCH = options.GOARCH
+	}
+	if options.BuildTags != "" {
+		ctx.BuildTags = nil
+		for _, tag := range strings.Split(options.BuildTags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				ctx.BuildTags = append(ctx.BuildTags, tag)
+			}
+		}
+	}
+	return &ctx
+}
+
+// matchesBuild prüft, ob eine Datei zur Build-Konfiguration gehört. Dateien,
+// deren Kopf sich nicht lesen lässt, bleiben drin; der Parser meldet den
+// Fehler dann genauer.
+func matchesBuild(ctx *build.Context, path string) bool {
+	match, err := ctx.MatchFile(filepath.Dir(path), filepath.Base(path))
+	return err != nil || match
+}
@@ -0,0 +1,90 @@
+package umlgen
+
+import (
+	"fmt"
+	"go/ast"
+	"os"
+	"path/filepath"
+)
+
+// cardFileName liefert den Dateinamen der Karte eines Typs, z.B.
+// cards/auth.User
+func cardFileName(typeInfo TypeInfo) string {
+	return "cards/" + typeInfo.Package + "." + bareTypeName(typeInfo.Name)
+}
+
+// neighborhood liefert einen Generator mit den Typen aus members samt
+// Membern und ihren direkten Nachbarn, die nur mit Namen erscheinen. Es
+// bleiben nur die Beziehungen, an denen ein Typ aus members beteiligt ist.
+func (g *UMLGenerator) neighborhood(members map[string]bool) *UMLGenerator {
+	keep := make(map[string]bool)
+	for name := range members {
+		keep[name] = true
+	}
+	var relations []Relation
+	for _, relation := range g.relations {
+		if !members[relation.From] && !members[relation.To] {
+			continue
+		}
+		relations = append(relations, relation)
+		keep[relation.From] = true
+		keep[relation.To] = true
+	}
+
+	sub := g.subset(keep)
+	sub.relations = relations
+	for other := range keep {
+		if members[other] {
+			continue
+		}
+		if structInfo, ok := sub.structs[other]; ok {
+			sub.structs[other] = &StructInfo{Name: structInfo.Name, Package: structInfo.Package, Pos: structInfo.Pos,
+				TypeParams: structInfo.TypeParams, Stereotypes: structInfo.Stereotypes}
+		}
+		if interfaceInfo, ok := sub.interfaces[other]; ok {
+			sub.interfaces[other] = &InterfaceInfo{Name: interfaceInfo.Name, Package: interfaceInfo.Package, Pos: interfaceInfo.Pos,
+				TypeParams: interfaceInfo.TypeParams, Stereotypes: interfaceInfo.Stereotypes}
+		}
+		if namedInfo, ok := sub.namedTypes[other]; ok {
+			sub.namedTypes[other] = &NamedTypeInfo{Name: namedInfo.Name, Package: namedInfo.Package, Pos: namedInfo.Pos,
+				Underlying: namedInfo.Underlying, TypeParams: namedInfo.TypeParams, Alias: namedInfo.Alias,
+				Stereotypes: namedInfo.Stereotypes, Target: namedInfo.Target, Multiple: namedInfo.Multiple}
+		}
+	}
+	for other := range sub.factories {
+		if !keep[other] {
+			delete(sub.factories, other)
+		}
+	}
+	for other := range sub.elements {
+		if !keep[other] {
+			delete(sub.elements, other)
+		}
+	}
+	return sub
+}
+
+// GenerateCards schreibt für jeden exportierten Typ eine kleine Karte mit
+// seiner Klasse und den direkten Beziehungen nach cards/<paket>.<Typ>
+// (--cards), etwa zum Einbetten in die API-Dokumentation
+func (g *UMLGenerator) GenerateCards(outputDir string) error {
+	if err := os.MkdirAll(filepath.Join(outputDir, "cards"), g.dirMode()); err != nil {
+		return fmt.Errorf("Fehler beim Erstellen des Kartenverzeichnisses: %v", err)
+	}
+
+	count := 0
+	for _, typeInfo := range g.Types() {
+		if !ast.IsExported(bareTypeName(typeInfo.Name)) {
+			continue
+		}
+		sub := g.neighborhood(map[string]bool{typeInfo.Name: true})
+		err := sub.writeDiagram(outputDir, cardFileName(typeInfo), sub.GeneratePlantUML())
+		g.artifacts = append(g.artifacts, sub.artifacts...)
+		if err != nil {
+			return err
+		}
+		count++
+	}
+	fmt.Printf("Karten erstellt: %d\n", count)
+	return nil
+}
@@ -0,0 +1,237 @@
+package umlgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Cluster fasst eng verbundene Typen zusammen
+type Cluster struct {
+	ID    int
+	Name  string   // Benannt nach dem am stärksten vernetzten Typ
+	Types []string // Sortierte Typnamen
+}
+
+// maxLabelPropagationRounds begrenzt die Iterationen der Community-Erkennung
+const maxLabelPropagationRounds = 50
+
+// DetectClusters gruppiert die Typen per Label Propagation über den
+// ungerichteten Beziehungsgraphen. Typen ohne Beziehungen landen gemeinsam
+// im Cluster "unverbunden".
+func (g *UMLGenerator) DetectClusters() []Cluster {
+	// Ungerichteten Graphen aufbauen
+	neighbors := make(map[string]map[string]int)
+	var names []string
+	for name := range g.structs {
+		names = append(names, name)
+	}
+	for name := range g.interfaces {
+		names = append(names, name)
+	}
+	for name := range g.namedTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		neighbors[name] = make(map[string]int)
+	}
+	for _, relation := range g.relations {
+		if relation.From == relation.To {
+			continue
+		}
+		if _, ok := neighbors[relation.From]; !ok {
+			continue
+		}
+		if _, ok := neighbors[relation.To]; !ok {
+			continue
+		}
+		neighbors[relation.From][relation.To]++
+		neighbors[relation.To][relation.From]++
+	}
+
+	// Jeder Typ startet mit seinem eigenen Label und übernimmt dann
+	// wiederholt das häufigste Label seiner Nachbarn. Die feste Reihenfolge
+	// und der Tie-Break über den kleinsten Namen machen das Ergebnis stabil.
+	labels := make(map[string]string)
+	for _, name := range names {
+		labels[name] = name
+	}
+	for round := 0; round < maxLabelPropagationRounds; round++ {
+		changed := false
+		for _, name := range names {
+			if len(neighbors[name]) == 0 {
+				continue
+			}
+			weights := make(map[string]int)
+			for neighbor, weight := range neighbors[name] {
+				weights[labels[neighbor]] += weight
+			}
+			best := labels[name]
+			for label, weight := range weights {
+				if weight > weights[best] || (weight == weights[best] && label < best) {
+					best = label
+				}
+			}
+			if best != labels[name] {
+				labels[name] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	// Cluster aus den Labels bilden
+	members := make(map[string][]string)
+	var unconnected []string
+	for _, name := range names {
+		if len(neighbors[name]) == 0 {
+			unconnected = append(unconnected, name)
+			continue
+		}
+		members[labels[name]] = append(members[labels[name]], name)
+	}
+
+	var clusters []Cluster
+	for _, types := range members {
+		// Name nach dem Typ mit den meisten Verbindungen
+		center := types[0]
+		for _, name := range types {
+			if len(neighbors[name]) > len(neighbors[center]) {
+				center = name
+			}
+		}
+		clusters = append(clusters, Cluster{Name: center, Types: types})
+	}
+
+	// Größte Cluster zuerst
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].Types) != len(clusters[j].Types) {
+			return len(clusters[i].Types) > len(clusters[j].Types)
+		}
+		return clusters[i].Name < clusters[j].Name
+	})
+	if len(unconnected) > 0 {
+		clusters = append(clusters, Cluster{Name: "unverbunden", Types: unconnected})
+	}
+	for i := range clusters {
+		clusters[i].ID = i + 1
+	}
+
+	return clusters
+}
+
+// subset liefert einen Generator, der nur die angegebenen Typen enthält
+func (g *UMLGenerator) subset(keep map[string]bool) *UMLGenerator {
+	sub := NewUMLGenerator(g.options)
+	sub.renderers = g.renderChain()
+	if g.options.RenderQueue != "" {
+		sub.queue, _ = g.queueTarget() // Fehler meldet enqueueRender
+	}
+	sub.stamp = g.stamp
+	// Schlüssel und Importpfade werden nur gelesen und können geteilt werden
+	sub.typeKeys, sub.typePaths = g.typeKeys, g.typePaths
+	sub.scopePackages, sub.scopePaths, sub.pathScopes = g.scopePackages, g.scopePaths, g.pathScopes
+	for name, structInfo := range g.structs {
+		sub.structs[name] = structInfo
+	}
+	for name, interfaceInfo := range g.interfaces {
+		sub.interfaces[name] = interfaceInfo
+	}
+	for name, namedInfo := range g.namedTypes {
+		sub.namedTypes[name] = namedInfo
+	}
+	for name, factory := range g.factories {
+		sub.factories[name] = factory
+	}
+	for stereotype, color := range g.stereotypeColors {
+		sub.stereotypeColors[stereotype] = color
+	}
+	sub.relations = append(sub.relations, g.relations...)
+	sub.retainTypes(keep)
+	return sub
+}
+
+// clusterFileName liefert den Dateinamen des Detaildiagramms eines Clusters
+func clusterFileName(cluster Cluster) string {
+	return fmt.Sprintf("uml_cluster_%d", cluster.ID)
+}
+
+// GenerateClusterOverview erzeugt eine Übersicht mit einem Element pro Cluster
+// und gewichteten Kanten für die Beziehungen zwischen den Clustern
+func (g *UMLGenerator) GenerateClusterOverview(clusters []Cluster) string {
+	clusterOf := make(map[string]int)
+	for _, cluster := range clusters {
+		for _, name := range cluster.Types {
+			clusterOf[name] = cluster.ID
+		}
+	}
+
+	edges := make(map[[2]int]int)
+	for _, relation := range g.relations {
+		from, ok1 := clusterOf[relation.From]
+		to, ok2 := clusterOf[relation.To]
+		if ok1 && ok2 && from != to {
+			edges[[2]int{from, to}]++
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("@startuml\n\n")
+
+	for _, cluster := range clusters {
+		sb.WriteString(fmt.Sprintf("rectangle \"Cluster %d: %s\\n(%d Typen)\" as C%d [[%s.png]]\n",
+			cluster.ID, cluster.Name, len(cluster.Types), cluster.ID, clusterFileName(cluster)))
+	}
+	sb.WriteString("\n")
+
+	var keys [][2]int
+	for key := range edges {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	for _, key := range keys {
+		sb.WriteString(fmt.Sprintf("C%d --> C%d : %d\n", key[0], key[1], edges[key]))
+	}
+
+	sb.WriteString("\n@enduml")
+	return sb.String()
+}
+
+// GenerateClusterDiagrams schreibt die Cluster-Übersicht (uml_clusters) sowie
+// ein Detaildiagramm pro Cluster (uml_cluster_<n>)
+func (g *UMLGenerator) GenerateClusterDiagrams(outputDir string) error {
+	clusters := g.DetectClusters()
+	fmt.Printf("Gefundene Cluster: %d\n", len(clusters))
+
+	if err := g.writeDiagram(outputDir, "uml_clusters", g.GenerateClusterOverview(clusters)); err != nil {
+		return err
+	}
+
+	for _, cluster := range clusters {
+		keep := make(map[string]bool)
+		for _, name := range cluster.Types {
+			keep[name] = true
+		}
+		// Über den Teilgenerator schreiben, damit das Manifest nur die Pakete
+		// des Clusters nennt
+		sub := g.subset(keep)
+		err := sub.writeDiagram(outputDir, clusterFileName(cluster), sub.GeneratePlantUML())
+		if err == nil && g.options.AltText {
+			err = sub.writeAltText(outputDir, clusterFileName(cluster))
+		}
+		g.artifacts = append(g.artifacts, sub.artifacts...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package umlgen
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ComparedType stellt einen Typ des linken Pakets seinem Gegenstück im
+// rechten gegenüber. Eine Seite ist leer, wenn es kein Gegenstück gibt.
+type ComparedType struct {
+	Left, Right         string
+	OnlyLeft, OnlyRight []MethodInfo // Methoden, die der anderen Seite fehlen
+}
+
+// PackageComparison stellt zwei Pakete gegenüber, die dieselben Interfaces
+// implementieren, z.B. eine v1- und eine v2-Implementierung (--compare)
+type PackageComparison struct {
+	Left, Right string
+	Interfaces  []string // Von beiden Paketen implementierte Interfaces
+	Types       []ComparedType
+}
+
+// ComparePackages ermittelt die Interfaces, die Typen beider Pakete
+// implementieren, und ordnet deren Implementierungen einander zu: zuerst
+// nach gleichem Namen, sonst als jeweils einzige Implementierung desselben
+// Interfaces.
+func (g *UMLGenerator) ComparePackages(left, right string) PackageComparison {
+	comparison := PackageComparison{Left: left, Right: right}
+
+	implementers := make(map[string]map[string][]string) // Interface -> Paket -> Typen
+	for _, relation := range g.relations {
+		if relation.Type != "implements" || !g.providesInterface(relation.From, relation.To) {
+			continue
+		}
+		pkg, _ := g.typePackage(relation.From)
+		if pkg != left && pkg != right {
+			continue
+		}
+		if implementers[relation.To] == nil {
+			implementers[relation.To] = make(map[string][]string)
+		}
+		implementers[relation.To][pkg] = appendUnique(implementers[relation.To][pkg], relation.From)
+	}
+
+	var leftTypes, rightTypes []string
+	for _, name := range sortedMapKeys(implementers) {
+		byPackage := implementers[name]
+		if len(byPackage[left]) == 0 || len(byPackage[right]) == 0 {
+			continue
+		}
+		comparison.Interfaces = append(comparison.Interfaces, name)
+		for _, typeName := range byPackage[left] {
+			leftTypes = appendUnique(leftTypes, typeName)
+		}
+		for _, typeName := range byPackage[right] {
+			rightTypes = appendUnique(rightTypes, typeName)
+		}
+	}
+
+	counterpart := make(map[string]string) // Linker Typ -> rechter Typ
+	matched := make(map[string]bool)
+	for _, leftType := range leftTypes {
+		for _, rightType := range rightTypes {
+			if !matched[rightType] && bareTypeName(leftType) == bareTypeName(rightType) {
+				counterpart[leftType] = rightType
+				matched[rightType] = true
+				break
+			}
+		}
+	}
+	for _, name := range comparison.Interfaces {
+		byPackage := implementers[name]
+		if len(byPackage[left]) != 1 || len(byPackage[right]) != 1 {
+			continue
+		}
+		leftType, rightType := byPackage[left][0], byPackage[right][0]
+		if counterpart[leftType] == "" && !matched[rightType] {
+			counterpart[leftType] = rightType
+			matched[rightType] = true
+		}
+	}
+
+	for _, leftType := range leftTypes {
+		compared := ComparedType{Left: leftType, Right: counterpart[leftType]}
+		if compared.Right != "" {
+			leftMethods, rightMethods := g.typeMethods(compared.Left), g.typeMethods(compared.Right)
+			compared.OnlyLeft = missingMethods(leftMethods, rightMethods)
+			compared.OnlyRight = missingMethods(rightMethods, leftMethods)
+		}
+		comparison.Types = append(comparison.Types, compared)
+	}
+	for _, rightType := range rightTypes {
+		if !matched[rightType] {
+			comparison.Types = append(comparison.Types, ComparedType{Right: rightType})
+		}
+	}
+	return comparison
+}
+
+// typeMethods liefert die Methoden einer Struct oder eines benannten Typs
+func (g *UMLGenerator) typeMethods(name string) []MethodInfo {
+	if structInfo, ok := g.structs[name]; ok {
+		return structInfo.Methods
+	}
+	if namedInfo, ok := g.namedTypes[name]; ok {
+		return namedInfo.Methods
+	}
+	return nil
+}
+
+// missingMethods liefert die Methoden aus methods, für die others keine
+// gleichnamige Methode hat
+func missingMethods(methods, others []MethodInfo) []MethodInfo {
+	var missing []MethodInfo
+	for _, method := range methods {
+		found := false
+		for _, other := range others {
+			if other.Name == method.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, method)
+		}
+	}
+	return missing
+}
+
+// GenerateComparePlantUML zeichnet das linke Paket links, die gemeinsamen
+// Interfaces in der Mitte und das rechte Paket rechts. Methoden ohne
+// Gegenstück stehen in einem eigenen Abschnitt "nur in <paket>".
+func (g *UMLGenerator) GenerateComparePlantUML(comparison PackageComparison) string {
+	var sb strings.Builder
+	sb.WriteString("@startuml\n\n")
+	sb.WriteString("set namespaceSeparator none\n\n")
+
+	writeClass := func(name, pkg string, only []MethodInfo) {
+		sb.WriteString(fmt.Sprintf("class %s {\n", name))
+		for _, method := range missingMethods(g.typeMethods(name), only) {
+			sb.WriteString(formatMethodPlantUML(method))
+		}
+		if len(only) > 0 {
+			sb.WriteString(fmt.Sprintf("    .. nur in %s ..\n", pkg))
+			for _, method := range only {
+				sb.WriteString(formatMethodPlantUML(method))
+			}
+		}
+		sb.WriteString("}\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("package %s {\n", comparison.Left))
+	for _, compared := range comparison.Types {
+		if compared.Left != "" {
+			writeClass(compared.Left, comparison.Left, compared.OnlyLeft)
+		}
+	}
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("package \"gemeinsame Interfaces\" {\n")
+	for _, name := range comparison.Interfaces {
+		sb.WriteString(fmt.Sprintf("interface %s {\n", name))
+		for _, method := range g.interfaces[name].Methods {
+			sb.WriteString(formatMethodPlantUML(method))
+		}
+		sb.WriteString("}\n")
+	}
+	sb.WriteString("}\n\n")
+
+	sb.WriteString(fmt.Sprintf("package %s {\n", comparison.Right))
+	for _, compared := range comparison.Types {
+		if compared.Right != "" {
+			writeClass(compared.Right, comparison.Right, compared.OnlyRight)
+		}
+	}
+	sb.WriteString("}\n\n")
+
+	// Linke Implementierungen zeigen nach rechts auf die Interfaces, rechte
+	// nach links, so dass die Interfaces in der Mitte liegen
+	for _, relation := range g.relations {
+		if relation.Type != "implements" || !slices.Contains(comparison.Interfaces, relation.To) || !g.providesInterface(relation.From, relation.To) {
+			continue
+		}
+		switch pkg, _ := g.typePackage(relation.From); pkg {
+		case comparison.Left:
+			sb.WriteString(fmt.Sprintf("%s .right.|> %s\n", relation.From, relation.To))
+		case comparison.Right:
+			sb.WriteString(fmt.Sprintf("%s <|.left. %s\n", relation.To, relation.From))
+		}
+	}
+
+	sb.WriteString("\n@enduml")
+	return sb.String()
+}
@@ -0,0 +1,86 @@
+package umlgen
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// typeSetTerm liefert ein Element einer Typmenge in einem Interface wie
+// ~int | ~float64, ~string oder string, sonst "" für eingebettete Interfaces
+func typeSetTerm(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.BinaryExpr:
+		if t.Op == token.OR {
+			return getTypeString(t)
+		}
+	case *ast.UnaryExpr:
+		if t.Op == token.TILDE {
+			return getTypeString(t)
+		}
+	case *ast.Ident:
+		// Vordeklarierte Basistypen wie string; comparable und any bleiben
+		// eingebettete Interfaces
+		if obj, ok := types.Universe.Lookup(t.Name).(*types.TypeName); ok {
+			if _, basic := obj.Type().(*types.Basic); basic {
+				return t.Name
+			}
+		}
+	}
+	return ""
+}
+
+// constraintNames liefert die benannten Typen eines Constraints, z.B.
+// Number und constraints.Ordered bei Number | constraints.Ordered. Terme mit
+// ~ beschreiben zugrundeliegende Typen und keine Constraints.
+func constraintNames(expr ast.Expr) []string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if types.Universe.Lookup(t.Name) == nil {
+			return []string{t.Name}
+		}
+	case *ast.SelectorExpr:
+		return []string{getTypeString(t)}
+	case *ast.IndexExpr:
+		return constraintNames(t.X)
+	case *ast.IndexListExpr:
+		return constraintNames(t.X)
+	case *ast.ParenExpr:
+		return constraintNames(t.X)
+	case *ast.BinaryExpr:
+		return append(constraintNames(t.X), constraintNames(t.Y)...)
+	}
+	return nil
+}
+
+// constraintRelations kennzeichnet Interfaces mit Typmengen als
+// «constraint» und zieht von jedem generischen Typ eine «constrains»-Kante
+// zu den Interfaces des Modells, die seine Typparameter beschränken. Die
+// Kante trägt den Namen des Typparameters (--constraints).
+func (g *UMLGenerator) constraintRelations() {
+	for _, interfaceInfo := range g.interfaces {
+		if len(interfaceInfo.TypeSet) > 0 {
+			interfaceInfo.Stereotypes = appendUnique(interfaceInfo.Stereotypes, "constraint")
+		}
+	}
+
+	constrain := func(typeName string, params []TypeParamInfo, pos Position) {
+		for _, param := range params {
+			for _, target := range param.Targets {
+				if _, ok := g.interfaces[target]; !ok || target == typeName {
+					continue
+				}
+				g.relations = append(g.relations, Relation{From: typeName, To: target, Type: "constrains", Label: param.Name, Pos: pos})
+			}
+		}
+	}
+	for _, name := range sortedMapKeys(g.structs) {
+		constrain(name, g.structs[name].TypeParams, g.structs[name].Pos)
+	}
+	for _, name := range sortedMapKeys(g.interfaces) {
+		constrain(name, g.interfaces[name].TypeParams, g.interfaces[name].Pos)
+	}
+	for _, name := range sortedMapKeys(g.namedTypes) {
+		constrain(name, g.namedTypes[name].TypeParams, g.namedTypes[name].Pos)
+	}
+}
@@ -0,0 +1,182 @@
+package umlgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ContextKeyFlow beschreibt, welche Pakete einen Context-Schlüssel per
+// context.WithValue setzen und welche ihn per ctx.Value lesen
+type ContextKeyFlow struct {
+	Key     string
+	Setters map[string][]Position // Paket -> Fundstellen von context.WithValue
+	Readers map[string][]Position // Paket -> Fundstellen von ctx.Value
+}
+
+// ContextKeyFlows sammelt alle Context-Schlüssel des Codes. Schlüssel werden
+// paketqualifiziert verglichen (userKey in Paket auth entspricht auth.userKey).
+// Lesezugriffe werden nur für Schlüssel erfasst, die irgendwo gesetzt werden.
+func (g *UMLGenerator) ContextKeyFlows() []*ContextKeyFlow {
+	flows := make(map[string]*ContextKeyFlow)
+	type read struct {
+		key string
+		pkg string
+		pos Position
+	}
+	var reads []read
+
+	for _, filePath := range sortedMapKeys(g.files) {
+		file := g.files[filePath]
+		pkg := file.Name.Name
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+
+			// context.WithValue(ctx, key, value)
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == "context" && sel.Sel.Name == "WithValue" && len(call.Args) == 3 {
+				key := contextKeyName(pkg, call.Args[1])
+				flow, ok := flows[key]
+				if !ok {
+					flow = &ContextKeyFlow{Key: key, Setters: make(map[string][]Position), Readers: make(map[string][]Position)}
+					flows[key] = flow
+				}
+				flow.Setters[pkg] = append(flow.Setters[pkg], g.position(call.Pos()))
+				return true
+			}
+
+			// ctx.Value(key)
+			if sel.Sel.Name == "Value" && len(call.Args) == 1 {
+				reads = append(reads, read{key: contextKeyName(pkg, call.Args[0]), pkg: pkg, pos: g.position(call.Pos())})
+			}
+			return true
+		})
+	}
+
+	for _, r := range reads {
+		if flow, ok := flows[r.key]; ok {
+			flow.Readers[r.pkg] = append(flow.Readers[r.pkg], r.pos)
+		}
+	}
+
+	result := make([]*ContextKeyFlow, 0, len(flows))
+	for _, key := range sortedMapKeys(flows) {
+		result = append(result, flows[key])
+	}
+	return result
+}
+
+// contextKeyName qualifiziert einen Schlüsselausdruck mit seinem Paket
+func contextKeyName(pkg string, expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.SelectorExpr:
+		// Bereits qualifiziert, z.B. auth.UserKey
+		if _, ok := e.X.(*ast.Ident); ok {
+			return types.ExprString(e)
+		}
+	case *ast.CallExpr:
+		// Typkonvertierung wie ctxKey("user") oder auth.ctxKey("user")
+		if _, ok := e.Fun.(*ast.SelectorExpr); ok {
+			return types.ExprString(e)
+		}
+	}
+	return pkg + "." + types.ExprString(expr)
+}
+
+// GenerateContextKeyReport erzeugt einen Textbericht über alle Context-Schlüssel
+func (g *UMLGenerator) GenerateContextKeyReport(flows []*ContextKeyFlow) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Context-Schlüssel: %d\n", len(flows)))
+
+	for _, flow := range flows {
+		sb.WriteString(fmt.Sprintf("\n%s\n", flow.Key))
+		for _, pkg := range sortedMapKeys(flow.Setters) {
+			sb.WriteString(fmt.Sprintf("  gesetzt in %s: %s\n", pkg, formatPositions(flow.Setters[pkg])))
+		}
+		for _, pkg := range sortedMapKeys(flow.Readers) {
+			sb.WriteString(fmt.Sprintf("  gelesen in %s: %s\n", pkg, formatPositions(flow.Readers[pkg])))
+		}
+		if len(flow.Readers) == 0 {
+			sb.WriteString("  wird nirgends gelesen\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// formatPositions listet Fundstellen kurz als datei:zeile
+func formatPositions(positions []Position) string {
+	var parts []string
+	for _, pos := range positions {
+		parts = append(parts, fmt.Sprintf("%s:%d", filepath.Base(pos.File), pos.Line))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// GenerateContextKeyPlantUML zeichnet Pakete und Schlüssel mit "set"- und
+// "read"-Kanten
+func (g *UMLGenerator) GenerateContextKeyPlantUML(flows []*ContextKeyFlow) string {
+	var sb strings.Builder
+	sb.WriteString("@startuml\n\n")
+
+	packages := make(map[string]bool)
+	for _, flow := range flows {
+		for pkg := range flow.Setters {
+			packages[pkg] = true
+		}
+		for pkg := range flow.Readers {
+			packages[pkg] = true
+		}
+	}
+	for _, pkg := range sortedMapKeys(packages) {
+		sb.WriteString(fmt.Sprintf("package %s {\n}\n", pkg))
+	}
+	sb.WriteString("\n")
+
+	for i, flow := range flows {
+		sb.WriteString(fmt.Sprintf("card \"%s\" as key%d\n", flow.Key, i))
+	}
+	sb.WriteString("\n")
+
+	for i, flow := range flows {
+		for _, pkg := range sortedMapKeys(flow.Setters) {
+			sb.WriteString(fmt.Sprintf("%s --> key%d : set (%d)\n", pkg, i, len(flow.Setters[pkg])))
+		}
+		for _, pkg := range sortedMapKeys(flow.Readers) {
+			sb.WriteString(fmt.Sprintf("key%d --> %s : read (%d)\n", i, pkg, len(flow.Readers[pkg])))
+		}
+	}
+
+	sb.WriteString("\n@enduml")
+	return sb.String()
+}
+
+// GenerateContextKeyOutputs schreibt Bericht (context_keys.txt) und
+// Diagramm (uml_context_keys) der Context-Schlüssel
+func (g *UMLGenerator) GenerateContextKeyOutputs(outputDir string) error {
+	flows := g.ContextKeyFlows()
+
+	if err := os.MkdirAll(outputDir, g.dirMode()); err != nil {
+		return fmt.Errorf("Fehler beim Erstellen des Ausgabeverzeichnisses: %v", err)
+	}
+	reportPath := filepath.Join(outputDir, "context_keys.txt")
+	if err := writeFileFrom(reportPath, strings.NewReader(g.GenerateContextKeyReport(flows)), g.fileMode()); err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Context-Berichts: %v", err)
+	}
+	fmt.Printf("Context-Bericht erstellt: %s\n", reportPath)
+	if err := g.recordArtifact(outputDir, reportPath, "txt", ""); err != nil {
+		return err
+	}
+
+	return g.writeDiagram(outputDir, "uml_context_keys", g.GenerateContextKeyPlantUML(flows))
+}
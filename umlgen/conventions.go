@@ -0,0 +1,121 @@
+package umlgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// Convention ordnet Typen anhand von Namens- und Paketmustern einen
+// Stereotyp zu, z.B. «repository» für alles, was auf Repository endet
+type Convention struct {
+	Stereotype string `json:"stereotype"`
+	Name       string `json:"name,omitempty"`    // Regulärer Ausdruck für den Typnamen
+	Package    string `json:"package,omitempty"` // Regulärer Ausdruck für den Paketnamen
+	Color      string `json:"color,omitempty"`   // Hintergrundfarbe, z.B. #DDEEFF
+
+	name, pkg *regexp.Regexp
+}
+
+// defaultConventions sind die eingebauten Regeln für --conventions default.
+// Namensregeln stehen vor Paketregeln, damit UserService im Paket handlers
+// ein «service» bleibt.
+var defaultConventions = []Convention{
+	{Stereotype: "handler", Name: `(Handler|Controller)$`, Color: "#E3F2FD"},
+	{Stereotype: "service", Name: `Service$`, Color: "#E8F5E9"},
+	{Stereotype: "repository", Name: `(Repository|Repo|Store)$`, Color: "#FFF3E0"},
+	{Stereotype: "dto", Name: `(DTO|Dto|Request|Response)$`, Color: "#F3E5F5"},
+	{Stereotype: "handler", Package: `^(handlers?|controllers?|api|web)$`, Color: "#E3F2FD"},
+	{Stereotype: "service", Package: `^services?$`, Color: "#E8F5E9"},
+	{Stereotype: "repository", Package: `^(repository|repositories|repo|store|storage)$`, Color: "#FFF3E0"},
+	{Stereotype: "dto", Package: `^dtos?$`, Color: "#F3E5F5"},
+}
+
+// loadConventions liefert die Regeln für --conventions: "default" für die
+// eingebauten, sonst eine JSON-Datei mit einer Liste von Regeln
+func loadConventions(source string) ([]Convention, error) {
+	conventions := defaultConventions
+	if source != "default" {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("Fehler beim Lesen der Konventionen: %v", err)
+		}
+		conventions = nil
+		if err := json.Unmarshal(data, &conventions); err != nil {
+			return nil, fmt.Errorf("Fehler in den Konventionen %s: %v", source, err)
+		}
+	}
+
+	compiled := make([]Convention, len(conventions))
+	for i, convention := range conventions {
+		if convention.Stereotype == "" || (convention.Name == "" && convention.Package == "") {
+			return nil, fmt.Errorf("Fehler in den Konventionen %s: Regel %d braucht stereotype und name oder package", source, i+1)
+		}
+		var err error
+		if convention.Name != "" {
+			if convention.name, err = regexp.Compile(convention.Name); err != nil {
+				return nil, fmt.Errorf("Fehler in den Konventionen %s: Regel %d: %v", source, i+1, err)
+			}
+		}
+		if convention.Package != "" {
+			if convention.pkg, err = regexp.Compile(convention.Package); err != nil {
+				return nil, fmt.Errorf("Fehler in den Konventionen %s: Regel %d: %v", source, i+1, err)
+			}
+		}
+		compiled[i] = convention
+	}
+	return compiled, nil
+}
+
+// matches prüft, ob ein Typ alle gesetzten Muster der Regel erfüllt
+func (c Convention) matches(name, pkg string) bool {
+	if c.name != nil && !c.name.MatchString(bareTypeName(name)) {
+		return false
+	}
+	return c.pkg == nil || c.pkg.MatchString(pkg)
+}
+
+// ApplyConventions versieht Structs, Interfaces und benannte Typen mit dem
+// Stereotyp der ersten passenden Regel (--conventions). Farben werden als
+// skinparam je Stereotyp ins Diagramm geschrieben.
+func (g *UMLGenerator) ApplyConventions(source string) error {
+	conventions, err := loadConventions(source)
+	if err != nil {
+		return err
+	}
+
+	stereotype := func(name, pkg string) (string, bool) {
+		for _, convention := range conventions {
+			if convention.matches(name, pkg) {
+				if _, ok := g.stereotypeColors[convention.Stereotype]; !ok && convention.Color != "" {
+					g.stereotypeColors[convention.Stereotype] = convention.Color
+				}
+				return convention.Stereotype, true
+			}
+		}
+		return "", false
+	}
+	for _, name := range sortedMapKeys(g.structs) {
+		structInfo := g.structs[name]
+		if s, ok := stereotype(name, structInfo.Package); ok {
+			structInfo.Stereotypes = appendUnique(structInfo.Stereotypes, s)
+		}
+	}
+	for _, name := range sortedMapKeys(g.interfaces) {
+		interfaceInfo := g.interfaces[name]
+		if s, ok := stereotype(name, interfaceInfo.Package); ok {
+			interfaceInfo.Stereotypes = appendUnique(interfaceInfo.Stereotypes, s)
+		}
+	}
+	for _, name := range sortedMapKeys(g.namedTypes) {
+		namedInfo := g.namedTypes[name]
+		if namedInfo.Alias {
+			continue
+		}
+		if s, ok := stereotype(name, namedInfo.Package); ok {
+			namedInfo.Stereotypes = appendUnique(namedInfo.Stereotypes, s)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package umlgen
+
+import (
+	"fmt"
+	"go/ast"
+	"path/filepath"
+)
+
+// ContextFinding ist eine Methode, die einen context.Context annimmt, ihn
+// aber weder prüft (ctx.Done(), ctx.Err()) noch weiterreicht
+type ContextFinding struct {
+	Type   string
+	Method string
+	Param  string // Name des Parameters, "_" bei ausdrücklich ignoriertem Context
+	Pos    Position
+}
+
+// ContextAudit sucht Methoden bekannter Typen, deren Context-Parameter im
+// Rumpf nicht verwendet wird. Solche Methoden lassen sich weder abbrechen
+// noch mit einem Timeout versehen. Eine Zuweisung an _ zählt nicht als
+// Verwendung.
+func (g *UMLGenerator) ContextAudit() []ContextFinding {
+	var findings []ContextFinding
+	for _, filePath := range sortedMapKeys(g.files) {
+		file := g.files[filePath]
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 || funcDecl.Body == nil {
+				continue
+			}
+			typeName := g.typeKey(g.fileScope(file), receiverTypeName(funcDecl.Recv.List[0].Type))
+			if !g.isClassType(typeName) {
+				continue
+			}
+
+			for _, param := range funcDecl.Type.Params.List {
+				if getTypeString(param.Type) != "context.Context" {
+					continue
+				}
+				names := param.Names
+				if len(names) == 0 {
+					// Unbenannter Parameter: func (r *Repo) Get(context.Context)
+					names = []*ast.Ident{{Name: "_", NamePos: param.Pos()}}
+				}
+				for _, name := range names {
+					if name.Name != "_" && usesIdent(funcDecl.Body, name.Name) {
+						continue
+					}
+					findings = append(findings, ContextFinding{
+						Type:   typeName,
+						Method: funcDecl.Name.Name,
+						Param:  name.Name,
+						Pos:    g.position(name.Pos()),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// usesIdent prüft, ob ein Bezeichner im Rumpf gelesen wird. Feldzugriffe
+// wie x.ctx und Zuweisungen wie _ = ctx zählen nicht.
+func usesIdent(body *ast.BlockStmt, name string) bool {
+	used := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if used {
+			return false
+		}
+		switch node := n.(type) {
+		case *ast.SelectorExpr:
+			// Nur den linken Teil betrachten, x.ctx ist ein Feld
+			ast.Inspect(node.X, func(n ast.Node) bool {
+				if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+					used = true
+				}
+				return !used
+			})
+			return false
+		case *ast.AssignStmt:
+			if isBlankAssign(node) {
+				return false
+			}
+		case *ast.Ident:
+			used = node.Name == name
+		}
+		return true
+	})
+	return used
+}
+
+// isBlankAssign prüft, ob eine Zuweisung nur an _ geht, z.B. _ = ctx
+func isBlankAssign(assign *ast.AssignStmt) bool {
+	for _, lhs := range assign.Lhs {
+		if ident, ok := lhs.(*ast.Ident); !ok || ident.Name != "_" {
+			return false
+		}
+	}
+	return true
+}
+
+// markContextAudit hängt die Befunde als Notiz an die betroffenen Structs
+// (--ctx-audit diagram)
+func (g *UMLGenerator) markContextAudit() {
+	for _, finding := range g.ContextAudit() {
+		if structInfo, ok := g.structs[finding.Type]; ok {
+			structInfo.Notes = append(structInfo.Notes, fmt.Sprintf("ctx ungenutzt: %s (%s:%d)",
+				finding.Method, filepath.Base(finding.Pos.File), finding.Pos.Line))
+		}
+	}
+}
+
+// printContextAudit gibt die Befunde auf der Konsole aus
+func printContextAudit(findings []ContextFinding) {
+	if len(findings) == 0 {
+		fmt.Println("Alle Context-Parameter werden verwendet")
+		return
+	}
+	fmt.Printf("Ungenutzte Context-Parameter: %d\n", len(findings))
+	for _, finding := range findings {
+		fmt.Printf("  %s.%s(%s context.Context) %s\n", finding.Type, finding.Method, finding.Param, finding.Pos)
+	}
+}
@@ -0,0 +1,132 @@
+package umlgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// cycleStereotype markiert Typen, die Teil eines Zyklus sind (--cycles)
+const cycleStereotype = "zyklus"
+
+// TypeCycle ist eine Gruppe von Typen, die sich gegenseitig (direkt oder über
+// Umwege) referenzieren: eine stark zusammenhängende Komponente des
+// Beziehungsgraphen mit mehr als einem Typ
+type TypeCycle struct {
+	Types     []string   // Sortierte Typnamen
+	Relations []Relation // Beziehungen innerhalb der Gruppe
+}
+
+// TypeCycles sucht mit dem Algorithmus von Tarjan alle Zyklen zwischen
+// Typen. Selbstbezüge wie type Node struct { next *Node } zählen nicht.
+func (g *UMLGenerator) TypeCycles() []TypeCycle {
+	edges := make(map[string][]string)
+	for _, relation := range g.relations {
+		if relation.From != relation.To && g.isKnownType(relation.From) && g.isKnownType(relation.To) {
+			edges[relation.From] = appendUnique(edges[relation.From], relation.To)
+		}
+	}
+
+	index := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+
+	var visit func(name string)
+	visit = func(name string) {
+		index[name] = len(index)
+		lowlink[name] = index[name]
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, next := range edges[name] {
+			if _, seen := index[next]; !seen {
+				visit(next)
+				lowlink[name] = min(lowlink[name], lowlink[next])
+			} else if onStack[next] {
+				lowlink[name] = min(lowlink[name], index[next])
+			}
+		}
+
+		if lowlink[name] == index[name] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == name {
+					break
+				}
+			}
+			if len(component) > 1 {
+				components = append(components, component)
+			}
+		}
+	}
+	for _, name := range sortedMapKeys(edges) {
+		if _, seen := index[name]; !seen {
+			visit(name)
+		}
+	}
+
+	var cycles []TypeCycle
+	for _, component := range components {
+		sort.Strings(component)
+		members := make(map[string]bool)
+		for _, name := range component {
+			members[name] = true
+		}
+		cycle := TypeCycle{Types: component}
+		for _, relation := range g.relations {
+			if relation.From != relation.To && members[relation.From] && members[relation.To] {
+				cycle.Relations = append(cycle.Relations, relation)
+			}
+		}
+		cycles = append(cycles, cycle)
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i].Types[0] < cycles[j].Types[0] })
+	return cycles
+}
+
+// markCycles hebt die Typen und Beziehungen aller Zyklen im Diagramm hervor
+func (g *UMLGenerator) markCycles() {
+	cycles := g.TypeCycles()
+	component := make(map[string]int)
+	for i, cycle := range cycles {
+		for _, name := range cycle.Types {
+			component[name] = i + 1
+			if structInfo, ok := g.structs[name]; ok {
+				structInfo.Stereotypes = appendUnique(structInfo.Stereotypes, cycleStereotype)
+			} else if interfaceInfo, ok := g.interfaces[name]; ok {
+				interfaceInfo.Stereotypes = appendUnique(interfaceInfo.Stereotypes, cycleStereotype)
+			} else if namedInfo, ok := g.namedTypes[name]; ok {
+				namedInfo.Stereotypes = appendUnique(namedInfo.Stereotypes, cycleStereotype)
+			}
+		}
+	}
+	for i, relation := range g.relations {
+		if relation.From != relation.To && component[relation.From] != 0 && component[relation.From] == component[relation.To] {
+			g.relations[i].Cycle = true
+		}
+	}
+	g.hasCycles = len(cycles) > 0
+}
+
+// printCycles gibt die gefundenen Zyklen auf der Konsole aus
+func printCycles(cycles []TypeCycle) {
+	if len(cycles) == 0 {
+		fmt.Println("Keine Zyklen zwischen Typen")
+		return
+	}
+	fmt.Printf("Zyklen zwischen Typen: %d\n", len(cycles))
+	for _, cycle := range cycles {
+		var edges []string
+		for _, relation := range cycle.Relations {
+			edges = append(edges, fmt.Sprintf("%s -> %s (%s)", relation.From, relation.To, relation.Type))
+		}
+		fmt.Printf("  %s\n", strings.Join(cycle.Types, ", "))
+		fmt.Printf("    %s\n", strings.Join(edges, "\n    "))
+	}
+}
@@ -0,0 +1,181 @@
+package umlgen
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// maxDefaultLength begrenzt die Länge eines angezeigten Standardwerts
+const maxDefaultLength = 40
+
+// constructorDefaults liefert die Felder, die ein Konstruktor für typeName
+// mit festen Werten belegt: Schlüssel-Wert-Paare in X{...} oder &X{...} und
+// Zuweisungen x.Feld = Wert auf oberster Ebene des Rumpfs. Werte, die von
+// Parametern oder lokalen Variablen abhängen, sind keine Standardwerte.
+func constructorDefaults(funcDecl *ast.FuncDecl, typeName string) map[string]string {
+	if funcDecl.Body == nil {
+		return nil
+	}
+	locals := make(map[string]bool) // Parameter und lokale Variablen
+	for _, field := range funcDecl.Type.Params.List {
+		for _, name := range field.Names {
+			locals[name.Name] = true
+		}
+	}
+	instances := make(map[string]bool) // Variablen mit der neuen Instanz
+	defaults := make(map[string]string)
+
+	record := func(field string, value ast.Expr) {
+		if text, ok := defaultValue(value, locals); ok {
+			defaults[field] = text
+		}
+	}
+	fromLiteral := func(expr ast.Expr) bool {
+		lit := typeLiteral(expr, typeName)
+		if lit == nil {
+			return false
+		}
+		for _, elt := range lit.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				if key, ok := kv.Key.(*ast.Ident); ok {
+					record(key.Name, kv.Value)
+				}
+			}
+		}
+		return true
+	}
+	define := func(name *ast.Ident, value ast.Expr) {
+		if fromLiteral(value) || isNewOf(value, typeName) {
+			instances[name.Name] = true
+		} else {
+			locals[name.Name] = true
+		}
+	}
+
+	for _, stmt := range funcDecl.Body.List {
+		switch s := stmt.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range s.Lhs {
+				var value ast.Expr
+				if len(s.Lhs) == len(s.Rhs) {
+					value = s.Rhs[i]
+				}
+				switch target := lhs.(type) {
+				case *ast.Ident:
+					define(target, value)
+				case *ast.SelectorExpr:
+					if x, ok := target.X.(*ast.Ident); ok && instances[x.Name] && s.Tok == token.ASSIGN && value != nil {
+						record(target.Sel.Name, value)
+					}
+				}
+			}
+		case *ast.DeclStmt:
+			genDecl, ok := s.Decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				for i, name := range valueSpec.Names {
+					switch {
+					case i < len(valueSpec.Values) && len(valueSpec.Names) == len(valueSpec.Values):
+						define(name, valueSpec.Values[i])
+					case len(valueSpec.Values) == 0 && valueSpec.Type != nil && identName(valueSpec.Type) == typeName:
+						instances[name.Name] = true // var x X
+					default:
+						locals[name.Name] = true
+					}
+				}
+			}
+		case *ast.ReturnStmt:
+			if len(s.Results) > 0 {
+				fromLiteral(s.Results[0])
+			}
+		}
+	}
+	return defaults
+}
+
+// typeLiteral liefert das Composite-Literal X{...} in expr oder &X{...},
+// wenn es den Typ typeName erzeugt
+func typeLiteral(expr ast.Expr, typeName string) *ast.CompositeLit {
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = unary.X
+	}
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok || lit.Type == nil || receiverTypeName(lit.Type) != typeName {
+		return nil
+	}
+	return lit
+}
+
+// isNewOf prüft auf new(X) für den Typ typeName
+func isNewOf(expr ast.Expr, typeName string) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || identName(call.Fun) != "new" || len(call.Args) != 1 {
+		return false
+	}
+	return receiverTypeName(call.Args[0]) == typeName
+}
+
+// identName liefert den Namen eines Bezeichners, sonst ""
+func identName(expr ast.Expr) string {
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// defaultValue gibt einen Wert so wieder, wie er im Quelltext steht. nil,
+// Funktionsliterale und Werte, die Bezeichner aus locals verwenden,
+// entfallen; lange Werte werden gekürzt.
+func defaultValue(expr ast.Expr, locals map[string]bool) (string, bool) {
+	if expr == nil || identName(expr) == "nil" {
+		return "", false
+	}
+	usable := true
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			usable = false
+		case *ast.Ident:
+			if locals[n.Name] {
+				usable = false
+			}
+		case *ast.SelectorExpr:
+			// Nur der Empfänger zählt, z.B. cfg in cfg.Timeout, nicht Timeout
+			ast.Inspect(n.X, func(inner ast.Node) bool {
+				if ident, ok := inner.(*ast.Ident); ok && locals[ident.Name] {
+					usable = false
+				}
+				return usable
+			})
+			return false
+		}
+		return usable
+	})
+	if !usable {
+		return "", false
+	}
+	text := getTypeString(expr)
+	if runes := []rune(text); len(runes) > maxDefaultLength {
+		text = string(runes[:maxDefaultLength-1]) + "…"
+	}
+	return text, true
+}
+
+// attachFieldDefaults überträgt die in Konstruktoren gefundenen
+// Standardwerte an die Felder ihrer Structs (--field-defaults)
+func (g *UMLGenerator) attachFieldDefaults() {
+	for typeName, defaults := range g.fieldDefaults {
+		structInfo, ok := g.structs[typeName]
+		if !ok {
+			continue
+		}
+		for i, field := range structInfo.Fields {
+			if value, ok := defaults[field.Name]; ok {
+				structInfo.Fields[i].Default = value
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package umlgen
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// appName ist der Verzeichnisname unter den Benutzerverzeichnissen
+const appName = "go-uml-generator"
+
+// Umgebungsvariablen, die die Standardverzeichnisse überschreiben
+const (
+	envCacheDir  = "GOUML_CACHE_DIR"
+	envConfigDir = "GOUML_CONFIG_DIR"
+	envJar       = "GOUML_PLANTUML_JAR"
+)
+
+// cacheDir liefert das Cache-Verzeichnis (plantuml.jar, Render-Caches):
+// $GOUML_CACHE_DIR oder <os.UserCacheDir>/go-uml-generator
+func cacheDir() (string, error) {
+	if dir := os.Getenv(envCacheDir); dir != "" {
+		return dir, nil
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("Fehler beim Bestimmen des Cache-Verzeichnisses: %v", err)
+	}
+	return filepath.Join(base, appName), nil
+}
+
+// configDir liefert das Konfigurationsverzeichnis:
+// $GOUML_CONFIG_DIR oder <os.UserConfigDir>/go-uml-generator
+func configDir() (string, error) {
+	if dir := os.Getenv(envConfigDir); dir != "" {
+		return dir, nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("Fehler beim Bestimmen des Konfigurationsverzeichnisses: %v", err)
+	}
+	return filepath.Join(base, appName), nil
+}
+
+// plantUMLJarPath sucht plantuml.jar in dieser Reihenfolge: $GOUML_PLANTUML_JAR,
+// Cache-Verzeichnis, aktuelles Verzeichnis (frühere Versionen erwarteten die
+// Datei dort). Wird keine gefunden, ist das Ergebnis leer und der
+// Standardpfad im Cache-Verzeichnis wird als zweiter Wert geliefert.
+func plantUMLJarPath() (found string, cachePath string) {
+	if jar := os.Getenv(envJar); jar != "" {
+		return jar, jar
+	}
+
+	if dir, err := cacheDir(); err == nil {
+		cachePath = filepath.Join(dir, "plantuml.jar")
+		if _, err := os.Stat(cachePath); err == nil {
+			return cachePath, cachePath
+		}
+	}
+
+	if _, err := os.Stat("plantuml.jar"); err == nil {
+		return "plantuml.jar", cachePath
+	}
+	return "", cachePath
+}
+
+// applyConfigFile setzt Flag-Standardwerte aus der Konfigurationsdatei
+// <configDir>/config. Jede Zeile hat die Form "flag = wert"; Leerzeilen und
+// Zeilen mit # werden ignoriert. Kommandozeilenargumente haben Vorrang,
+// weil sie danach geparst werden. Unterbefehle übernehmen nur die Flags, die
+// sie kennen.
+func applyConfigFile(fs *flag.FlagSet) error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "config")
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Fehler beim Lesen der Konfiguration: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("Fehler in %s:%d: \"flag = wert\" erwartet", path, lineNo)
+		}
+		name = strings.TrimPrefix(strings.TrimSpace(name), "-")
+		if fs != flag.CommandLine && fs.Lookup(name) == nil {
+			continue
+		}
+		if err := fs.Set(name, strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("Fehler in %s:%d: %v", path, lineNo, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("Fehler beim Lesen der Konfiguration: %v", err)
+	}
+	return nil
+}
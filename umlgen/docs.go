@@ -0,0 +1,147 @@
+package umlgen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Markierungen des generierten Abschnitts in README.md bzw. doc.go
+const (
+	docMarkerStart = "<!-- go-uml-generator:start -->"
+	docMarkerEnd   = "<!-- go-uml-generator:end -->"
+	docGoMarker    = "// Diagramm erzeugt von go-uml-generator, Änderungen werden überschrieben."
+)
+
+// packageFileName liefert den Dateinamen des Diagramms eines Pakets. Gibt es
+// den Paketnamen in mehreren Verzeichnissen (z.B. mehrere main-Pakete), wird
+// er wie bei kollidierenden Typnamen über den Importpfad unterschieden.
+func packageFileName(scope string, scopes []string) string {
+	dir, pkg := splitScope(scope)
+	for _, other := range scopes {
+		if _, otherPkg := splitScope(other); other != scope && otherPkg == pkg {
+			root, modulePath := findModule(dir)
+			return "uml_package_" + strings.NewReplacer("/", "_", ":", "").Replace(packageImportPath(root, modulePath, dir))
+		}
+	}
+	return "uml_package_" + pkg
+}
+
+// splitScope zerlegt einen Paketbezeichner "Verzeichnis:Paket"
+func splitScope(scope string) (dir, pkg string) {
+	i := strings.LastIndex(scope, ":")
+	return scope[:i], scope[i+1:]
+}
+
+// GeneratePackageDocs schreibt ein Diagramm pro Paket (uml_package_<paket>)
+// und verweist darauf aus dem Paketverzeichnis: mode "readme" pflegt einen
+// markierten Abschnitt in README.md, mode "docgo" eine doc.go. Eine doc.go,
+// die nicht von diesem Werkzeug stammt, wird nicht verändert. Externe
+// Testpakete (pkg_test) erhalten ein Diagramm, aber keinen Verweis, da sie
+// sich das Verzeichnis mit dem eigentlichen Paket teilen.
+func (g *UMLGenerator) GeneratePackageDocs(outputDir, mode string) error {
+	byScope := make(map[string]map[string]bool) // Verzeichnis:Paket -> Typen
+	for _, typeInfo := range g.Types() {
+		scope := filepath.Dir(typeInfo.Pos.File) + ":" + typeInfo.Package
+		if byScope[scope] == nil {
+			byScope[scope] = make(map[string]bool)
+		}
+		byScope[scope][typeInfo.Name] = true
+	}
+
+	absOutput, err := filepath.Abs(outputDir)
+	if err != nil {
+		return err
+	}
+
+	scopes := sortedMapKeys(byScope)
+	for _, scope := range scopes {
+		dir, pkg := splitScope(scope)
+		name := packageFileName(scope, scopes)
+		sub := g.subset(byScope[scope])
+		err := sub.writeDiagram(outputDir, name, sub.GeneratePlantUML())
+		g.artifacts = append(g.artifacts, sub.artifacts...)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(pkg, "_test") {
+			continue
+		}
+
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		image := filepath.Join(absOutput, name+".png")
+		link, err := filepath.Rel(absDir, image)
+		if err != nil {
+			link = image
+		}
+		link = filepath.ToSlash(link)
+
+		switch mode {
+		case "readme":
+			err = updateReadme(filepath.Join(dir, "README.md"), pkg, link, sub.AltText())
+		case "docgo":
+			err = updateDocGo(filepath.Join(dir, "doc.go"), pkg, link)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateReadme ersetzt den markierten Abschnitt in README.md oder hängt ihn an
+func updateReadme(path, pkg, link, alt string) error {
+	section := fmt.Sprintf("%s\n## Klassendiagramm\n\n![%s](%s)\n%s", docMarkerStart, alt, link, docMarkerEnd)
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Fehler beim Lesen von %s: %v", path, err)
+	}
+	content := string(data)
+
+	start := strings.Index(content, docMarkerStart)
+	end := strings.Index(content, docMarkerEnd)
+	switch {
+	case start >= 0 && end > start:
+		content = content[:start] + section + content[end+len(docMarkerEnd):]
+	case content == "":
+		content = fmt.Sprintf("# %s\n\n%s\n", pkg, section)
+	default:
+		content = strings.TrimRight(content, "\n") + "\n\n" + section + "\n"
+	}
+
+	return writeIfChanged(path, string(data), content)
+}
+
+// updateDocGo schreibt eine doc.go mit Verweis auf das Diagramm, sofern es
+// keine gibt oder die vorhandene von diesem Werkzeug stammt
+func updateDocGo(path, pkg, link string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Fehler beim Lesen von %s: %v", path, err)
+	}
+	if err == nil && !strings.Contains(string(data), docGoMarker) {
+		fmt.Printf("Hinweis: %s ist nicht generiert und wird nicht verändert\n", path)
+		return nil
+	}
+
+	content := fmt.Sprintf("%s\n\n// Package %s: siehe [Klassendiagramm].\n//\n// [Klassendiagramm]: %s\npackage %s\n", docGoMarker, pkg, link, pkg)
+	return writeIfChanged(path, string(data), content)
+}
+
+// writeIfChanged schreibt eine Quelldatei nur bei geändertem Inhalt, damit
+// der Watch-Modus nicht durch eigene Schreibvorgänge erneut auslöst
+func writeIfChanged(path, old, content string) error {
+	if old == content {
+		return nil
+	}
+	if err := writeFileFrom(path, strings.NewReader(content), 0644); err != nil {
+		return fmt.Errorf("Fehler beim Schreiben von %s: %v", path, err)
+	}
+	fmt.Printf("Diagrammverweis aktualisiert: %s\n", path)
+	return nil
+}
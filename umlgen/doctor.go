@@ -0,0 +1,157 @@
+package umlgen
+
+import (
+	"archive/zip"
+	"context"
+	"flag"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultPlantUMLServer ist der öffentliche PlantUML-Server
+const defaultPlantUMLServer = "https://www.plantuml.com/plantuml"
+
+// plantUMLMainClass muss in einer intakten plantuml.jar enthalten sein
+const plantUMLMainClass = "net/sourceforge/plantuml/Run.class"
+
+// doctorCheck ist das Ergebnis einer einzelnen Prüfung
+type doctorCheck struct {
+	Name   string
+	OK     bool
+	Detail string
+}
+
+// runDoctor prüft, welche Renderer nutzbar sind (Unterbefehl "doctor"),
+// und liefert den Exit-Code: 0, wenn mindestens ein Renderer ein PNG
+// erzeugen kann. Die Server-Renderer werden unter denselben Adressen geprüft,
+// die --local-server, --kroki-url und --server beim Rendern verwenden.
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	localServer := fs.String("local-server", defaultLocalServer, "Adresse des lokalen PlantUML-Servers (Renderer local)")
+	krokiURL := fs.String("kroki-url", defaultKrokiURL, "Adresse des Kroki-Dienstes (Renderer kroki)")
+	server := fs.String("server", defaultPlantUMLServer, "Adresse des öffentlichen PlantUML-Servers (Renderer public)")
+	timeout := fs.Duration("timeout", 5*time.Second, "Zeitlimit für Netzwerk- und Prozessprüfungen")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Verwendung: uml-watcher doctor [Optionen]")
+		fs.PrintDefaults()
+	}
+	if err := applySubcommandConfig(fs); err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	fs.Parse(args)
+
+	java := checkCommand("Java", *timeout, "java", "-version")
+	jar := checkJar()
+	graphviz := checkCommand("Graphviz", *timeout, "dot", "-V")
+	servers := map[string]doctorCheck{
+		"local":  checkServer("Local", serverRenderer{name: "local", baseURL: *localServer, timeout: *timeout}),
+		"kroki":  checkServer("Kroki", serverRenderer{name: "kroki", baseURL: *krokiURL, kroki: true, timeout: *timeout}),
+		"public": checkServer("Public", serverRenderer{name: "public", baseURL: *server, timeout: *timeout}),
+	}
+
+	fmt.Println("Prüfungen:")
+	for _, check := range []doctorCheck{java, jar, graphviz, servers["local"], servers["kroki"], servers["public"]} {
+		status := "OK    "
+		if !check.OK {
+			status = "FEHLT "
+		}
+		fmt.Printf("  %s %-10s %s\n", status, check.Name, check.Detail)
+	}
+
+	fmt.Println("\nRenderer:")
+	usable := 0
+	for _, name := range sortedMapKeys(validRenderers) {
+		var reasons []string
+		switch name {
+		case "jar":
+			if !java.OK {
+				reasons = append(reasons, "Java fehlt")
+			}
+			if !jar.OK {
+				reasons = append(reasons, "plantuml.jar fehlt oder ist beschädigt")
+			}
+			if len(reasons) == 0 && !graphviz.OK {
+				fmt.Println("  Hinweis: ohne Graphviz nutzt PlantUML das eingebaute Layout (Smetana)")
+			}
+		case "puml":
+			// Schreibt nur den Quelltext und zählt daher nicht als Renderer
+			fmt.Printf("  %-8s immer nutzbar (nur .puml-Dateien)\n", name)
+			continue
+		default:
+			if !servers[name].OK {
+				reasons = append(reasons, "Server nicht erreichbar")
+			}
+		}
+
+		if len(reasons) > 0 {
+			fmt.Printf("  %-8s nicht nutzbar: %s\n", name, strings.Join(reasons, "; "))
+			continue
+		}
+		usable++
+		fmt.Printf("  %-8s nutzbar\n", name)
+	}
+
+	if usable == 0 {
+		fmt.Println("\nKein Renderer nutzbar, es werden nur .puml-Dateien erzeugt.")
+		return 1
+	}
+	return 0
+}
+
+// checkCommand prüft, ob ein Programm vorhanden ist und fehlerfrei läuft
+func checkCommand(name string, timeout time.Duration, command string, args ...string) doctorCheck {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return doctorCheck{Name: name, Detail: command + " nicht im PATH"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, path, args...).CombinedOutput()
+	if ctx.Err() != nil {
+		return doctorCheck{Name: name, Detail: fmt.Sprintf("%s: keine Antwort nach %s", path, timeout)}
+	}
+	if err != nil {
+		return doctorCheck{Name: name, Detail: fmt.Sprintf("%s: %v", path, err)}
+	}
+
+	// Erste Zeile der Versionsausgabe genügt
+	version := strings.TrimSpace(string(output))
+	if i := strings.IndexByte(version, '\n'); i >= 0 {
+		version = version[:i]
+	}
+	return doctorCheck{Name: name, OK: true, Detail: fmt.Sprintf("%s (%s)", path, version)}
+}
+
+// checkJar prüft, ob plantuml.jar gefunden wird und ein lesbares Archiv mit
+// der PlantUML-Hauptklasse ist
+func checkJar() doctorCheck {
+	jarPath, cachePath := plantUMLJarPath()
+	if jarPath == "" {
+		return doctorCheck{Name: "Jar", Detail: "nicht gefunden (erwartet unter " + cachePath + ")"}
+	}
+
+	archive, err := zip.OpenReader(jarPath)
+	if err != nil {
+		return doctorCheck{Name: "Jar", Detail: fmt.Sprintf("%s: kein gültiges Archiv: %v", jarPath, err)}
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if file.Name == plantUMLMainClass {
+			return doctorCheck{Name: "Jar", OK: true, Detail: jarPath}
+		}
+	}
+	return doctorCheck{Name: "Jar", Detail: jarPath + ": PlantUML-Hauptklasse fehlt"}
+}
+
+// checkServer prüft, ob ein Server-Renderer ein kleines Testdiagramm rendert
+func checkServer(name string, renderer serverRenderer) doctorCheck {
+	if _, err := renderer.Render([]byte("@startuml\nBob -> Alice\n@enduml\n")); err != nil {
+		return doctorCheck{Name: name, Detail: err.Error()}
+	}
+	return doctorCheck{Name: name, OK: true, Detail: renderer.baseURL}
+}
@@ -0,0 +1,255 @@
+package umlgen
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// plantUMLVersion ist die Version, die standardmäßig heruntergeladen wird
+const plantUMLVersion = "1.2024.7"
+
+// defaultJarMirrors sind die Download-Quellen für plantuml.jar in Reihenfolge
+var defaultJarMirrors = []string{
+	"https://github.com/plantuml/plantuml/releases/download/v" + plantUMLVersion + "/plantuml-" + plantUMLVersion + ".jar",
+	"https://repo1.maven.org/maven2/net/sourceforge/plantuml/plantuml/" + plantUMLVersion + "/plantuml-" + plantUMLVersion + ".jar",
+}
+
+// publishedJarChecksum ist die von Maven Central zur Standardversion
+// veröffentlichte SHA-1. Ohne -jar-sha256 muss jeder Download, auch von
+// anderen Mirrors, dazu passen.
+var publishedJarChecksum = "https://repo1.maven.org/maven2/net/sourceforge/plantuml/plantuml/" + plantUMLVersion + "/plantuml-" + plantUMLVersion + ".jar.sha1"
+
+// downloadTimeout begrenzt einen einzelnen Download-Versuch
+const downloadTimeout = 5 * time.Minute
+
+// ensurePlantUMLJar stellt sicher, dass plantuml.jar vorhanden ist, und lädt
+// sie andernfalls ins Cache-Verzeichnis. Die Mirrors werden der Reihe nach
+// versucht; ein abgebrochener Download (.part) wird fortgesetzt. Ist keine
+// Prüfsumme angegeben, wird der Download gegen die veröffentlichte
+// Prüfsumme geprüft und seine SHA-256 für spätere Downloads neben der Jar
+// abgelegt.
+func ensurePlantUMLJar(options Options) (string, error) {
+	if jarPath, _ := plantUMLJarPath(); jarPath != "" {
+		return jarPath, nil
+	}
+
+	_, cachePath := plantUMLJarPath()
+	if cachePath == "" {
+		return "", fmt.Errorf("Fehler beim Bestimmen des Cache-Verzeichnisses für plantuml.jar")
+	}
+	if options.NoDownload {
+		return "", fmt.Errorf("plantuml.jar nicht gefunden und Download deaktiviert (-no-download); legen Sie die Datei unter %s ab", cachePath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return "", fmt.Errorf("Fehler beim Erstellen des Cache-Verzeichnisses: %v", err)
+	}
+
+	checksumPath := cachePath + ".sha256"
+	expected := strings.ToLower(strings.TrimSpace(options.JarSHA256))
+	if expected == "" {
+		if data, err := os.ReadFile(checksumPath); err == nil {
+			expected = strings.TrimSpace(string(data))
+		}
+	}
+	published := ""
+	if expected == "" {
+		sum, err := fetchChecksum(publishedJarChecksum)
+		if err != nil {
+			return "", fmt.Errorf("Fehler beim Abrufen der Prüfsumme von plantuml.jar (%s): %v; mit -jar-sha256 angeben", publishedJarChecksum, err)
+		}
+		published = sum
+	}
+
+	mirrors := defaultJarMirrors
+	if options.JarMirrors != "" {
+		mirrors = nil
+		for _, mirror := range strings.Split(options.JarMirrors, ",") {
+			if mirror = strings.TrimSpace(mirror); mirror != "" {
+				mirrors = append(mirrors, mirror)
+			}
+		}
+	}
+
+	var failures []string
+	for _, mirror := range mirrors {
+		fmt.Printf("Lade plantuml.jar von %s\n", mirror)
+		sum, err := downloadFile(mirror, cachePath+".part")
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", mirror, err))
+			continue
+		}
+		if expected != "" && sum != expected {
+			os.Remove(cachePath + ".part")
+			failures = append(failures, fmt.Sprintf("%s: Prüfsumme %s statt %s", mirror, sum, expected))
+			continue
+		}
+		if published != "" {
+			if sha1sum, err := fileSHA1(cachePath + ".part"); err != nil || sha1sum != published {
+				os.Remove(cachePath + ".part")
+				failures = append(failures, fmt.Sprintf("%s: SHA-1 %s statt veröffentlichter %s", mirror, sha1sum, published))
+				continue
+			}
+		}
+		if err := os.Rename(cachePath+".part", cachePath); err != nil {
+			return "", fmt.Errorf("Fehler beim Speichern von plantuml.jar: %v", err)
+		}
+		if expected == "" {
+			if err := os.WriteFile(checksumPath, []byte(sum+"\n"), 0644); err != nil {
+				return "", fmt.Errorf("Fehler beim Speichern der Prüfsumme: %v", err)
+			}
+		}
+		fmt.Printf("plantuml.jar gespeichert: %s\n", cachePath)
+		return cachePath, nil
+	}
+
+	return "", fmt.Errorf("Fehler beim Herunterladen von plantuml.jar:\n  %s", strings.Join(failures, "\n  "))
+}
+
+// downloadFile lädt url nach path, setzt einen vorhandenen Teil-Download per
+// Range-Anfrage fort und liefert die SHA-256 der vollständigen Datei
+func downloadFile(url, path string) (string, error) {
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	client := &http.Client{Timeout: downloadTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		fmt.Printf("Setze Download bei %d Bytes fort\n", offset)
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		// Server ignoriert Range: von vorn beginnen
+		offset = 0
+		flags |= os.O_TRUNC
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// Teil-Download ist bereits vollständig
+	default:
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	if resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		file, err := os.OpenFile(path, flags, 0644)
+		if err != nil {
+			return "", err
+		}
+		total := offset + resp.ContentLength
+		progress := &progressWriter{written: offset, total: total}
+		_, err = io.Copy(file, io.TeeReader(resp.Body, progress))
+		progress.finish()
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			// Teil-Download bleibt für den nächsten Versuch liegen
+			return "", err
+		}
+	}
+
+	return fileSHA256(path)
+}
+
+// fileSHA256 berechnet die SHA-256 einer Datei
+func fileSHA256(path string) (string, error) {
+	return fileHash(path, sha256.New())
+}
+
+// fileSHA1 berechnet die SHA-1 einer Datei (Prüfsummen von Maven Central)
+func fileSHA1(path string) (string, error) {
+	return fileHash(path, sha1.New())
+}
+
+// fileHash berechnet eine Prüfsumme über den Inhalt einer Datei
+func fileHash(path string, h hash.Hash) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fetchChecksum lädt eine veröffentlichte Prüfsumme ("<hex>" oder
+// "<hex>  datei")
+func fetchChecksum(url string) (string, error) {
+	client := &http.Client{Timeout: renderTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("leere Prüfsumme")
+	}
+	if _, err := hex.DecodeString(fields[0]); err != nil {
+		return "", fmt.Errorf("ungültige Prüfsumme %q", fields[0])
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// progressWriter zeigt den Fortschritt eines Downloads in einer Zeile an
+type progressWriter struct {
+	written int64
+	total   int64 // <= 0, wenn die Größe unbekannt ist
+	shown   int64
+}
+
+func (p *progressWriter) Write(data []byte) (int, error) {
+	p.written += int64(len(data))
+	// Höchstens etwa alle 256 KiB aktualisieren
+	if p.written-p.shown >= 256*1024 {
+		p.shown = p.written
+		p.print()
+	}
+	return len(data), nil
+}
+
+func (p *progressWriter) print() {
+	if p.total > 0 {
+		fmt.Printf("\r  %d%% (%d / %d KiB)", p.written*100/p.total, p.written/1024, p.total/1024)
+	} else {
+		fmt.Printf("\r  %d KiB", p.written/1024)
+	}
+}
+
+func (p *progressWriter) finish() {
+	p.print()
+	fmt.Println()
+}
@@ -0,0 +1,65 @@
+package umlgen
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// processConstDecl merkt sich die Konstanten eines const-Blocks nach ihrem
+// Typ. Wie im Go-Compiler übernehmen Spezifikationen ohne Typ und Wert
+// (Red Color = iota; Green; Blue) Typ und Ausdruck der vorherigen. Auch
+// Konvertierungen wie Red = Color(iota) zählen.
+func (g *UMLGenerator) processConstDecl(genDecl *ast.GenDecl, scope string) {
+	typeName := ""
+	for _, spec := range genDecl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		if valueSpec.Type != nil || len(valueSpec.Values) > 0 {
+			typeName = g.typeKey(scope, constType(valueSpec))
+		}
+		if typeName == "" {
+			continue
+		}
+		for _, name := range valueSpec.Names {
+			if name.Name != "_" {
+				g.constants[typeName] = append(g.constants[typeName], name.Name)
+			}
+		}
+	}
+}
+
+// constType liefert den benannten Typ einer Konstantenspezifikation
+func constType(valueSpec *ast.ValueSpec) string {
+	if ident, ok := valueSpec.Type.(*ast.Ident); ok {
+		return ident.Name
+	}
+	if valueSpec.Type == nil && len(valueSpec.Values) > 0 {
+		if call, ok := valueSpec.Values[0].(*ast.CallExpr); ok && len(call.Args) == 1 {
+			if ident, ok := call.Fun.(*ast.Ident); ok {
+				return ident.Name
+			}
+		}
+	}
+	return ""
+}
+
+// attachEnumValues macht benannte Typen mit eingebautem Basistyp, für die
+// Konstanten deklariert sind, zu Aufzählungen (type Color int plus
+// const ( Red Color = iota ... ))
+func (g *UMLGenerator) attachEnumValues() {
+	for _, typeName := range sortedMapKeys(g.constants) {
+		namedInfo, ok := g.namedTypes[typeName]
+		if !ok || namedInfo.Alias || namedInfo.Multiple || !builtinTypes[namedInfo.Underlying] {
+			continue
+		}
+		namedInfo.EnumValues = g.constants[typeName]
+	}
+}
+
+// constDecl liefert die Deklaration, sofern sie ein const-Block ist
+func constDecl(decl ast.Decl) (*ast.GenDecl, bool) {
+	genDecl, ok := decl.(*ast.GenDecl)
+	return genDecl, ok && genDecl.Tok == token.CONST
+}
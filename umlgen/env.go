@@ -0,0 +1,45 @@
+package umlgen
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envPrefix ist das Präfix der Umgebungsvariablen für Flags
+const envPrefix = "GOUML_"
+
+// envOutput setzt das Ausgabeverzeichnis, wenn es nicht als Argument angegeben ist
+const envOutput = envPrefix + "OUTPUT"
+
+// flagEnvName bildet einen Flag-Namen auf seine Umgebungsvariable ab,
+// z.B. max-types -> GOUML_MAX_TYPES
+func flagEnvName(name string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// applySubcommandConfig setzt die Flags eines Unterbefehls wie beim
+// Hauptbefehl aus Konfigurationsdatei und Umgebungsvariablen
+func applySubcommandConfig(fs *flag.FlagSet) error {
+	if err := applyConfigFile(fs); err != nil {
+		return err
+	}
+	return applyEnv(fs)
+}
+
+// applyEnv setzt Flags aus GOUML_-Umgebungsvariablen. Sie überschreiben die
+// Konfigurationsdatei, Kommandozeilenargumente haben weiterhin Vorrang.
+func applyEnv(fs *flag.FlagSet) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		value, ok := os.LookupEnv(flagEnvName(f.Name))
+		if !ok || err != nil {
+			return
+		}
+		if setErr := fs.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("Ungültiger Wert in %s: %v", flagEnvName(f.Name), setErr)
+		}
+	})
+	return err
+}
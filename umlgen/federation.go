@@ -0,0 +1,290 @@
+package umlgen
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// sharedGroup ist die Gruppe für Typen, die in mehreren Services vorkommen
+const sharedGroup = "gemeinsam"
+
+// FederationConfig listet die Repositories einer Service-Landschaft
+//
+//	{"services": [
+//	  {"name": "orders", "path": "../orders"},
+//	  {"name": "billing", "url": "https://example.com/billing.git", "ref": "main"}
+//	]}
+type FederationConfig struct {
+	Services []FederatedService `json:"services"`
+}
+
+// FederatedService ist ein Repository der Landschaft, lokal (Path) oder per
+// Git-URL, die ins Cache-Verzeichnis geklont wird
+type FederatedService struct {
+	Name string `json:"name"`
+	Path string `json:"path,omitempty"`
+	URL  string `json:"url,omitempty"`
+	Ref  string `json:"ref,omitempty"` // Branch oder Tag für URL
+}
+
+// loadFederationConfig liest und prüft eine Federation-Konfiguration.
+// Relative Pfade gelten relativ zur Konfigurationsdatei.
+func loadFederationConfig(path string) (*FederationConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Lesen der Federation-Konfiguration: %v", err)
+	}
+	var config FederationConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("Fehler in der Federation-Konfiguration: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for i, service := range config.Services {
+		if service.Name == "" || (service.Path == "") == (service.URL == "") {
+			return nil, fmt.Errorf("Fehler in der Federation-Konfiguration: Service %d braucht einen Namen und entweder path oder url", i+1)
+		}
+		if seen[service.Name] {
+			return nil, fmt.Errorf("Fehler in der Federation-Konfiguration: Service %s doppelt", service.Name)
+		}
+		seen[service.Name] = true
+		if service.Path != "" && !filepath.IsAbs(service.Path) {
+			config.Services[i].Path = filepath.Join(filepath.Dir(path), service.Path)
+		}
+	}
+	return &config, nil
+}
+
+// checkout liefert das lokale Verzeichnis eines Services; Git-URLs werden
+// flach ins Cache-Verzeichnis geklont bzw. dort aktualisiert
+func (s FederatedService) checkout() (string, error) {
+	if s.Path != "" {
+		return s.Path, nil
+	}
+
+	base, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "federation", serviceDirName(s.Name))
+
+	var cmd *exec.Cmd
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		fmt.Printf("Aktualisiere %s\n", s.URL)
+		cmd = exec.Command("git", "-C", dir, "pull", "--ff-only", "--quiet")
+	} else {
+		fmt.Printf("Klone %s\n", s.URL)
+		args := []string{"clone", "--depth", "1", "--quiet"}
+		if s.Ref != "" {
+			args = append(args, "--branch", s.Ref)
+		}
+		cmd = exec.Command("git", append(args, s.URL, dir)...)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("Fehler beim Abrufen von %s: %v\n%s", s.URL, err, output)
+	}
+	return dir, nil
+}
+
+// serviceDirName macht einen Servicenamen zu einem Verzeichnisnamen im
+// Cache, so dass Namen wie "../x" oder "a/b" das Cache-Verzeichnis nicht
+// verlassen
+func serviceDirName(name string) string {
+	dirName := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, name)
+	if strings.Trim(dirName, ".") == "" {
+		dirName = strings.ReplaceAll(dirName, ".", "_")
+	}
+	return dirName
+}
+
+// serviceQualifier macht einen Servicenamen zum Qualifizierer von
+// Modellschlüsseln (orders.model.User)
+func serviceQualifier(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// LoadFederation parst alle Services in ein gemeinsames Modell. Dateien mit
+// identischem Inhalt (z.B. dieselbe geteilte Bibliothek in mehreren
+// Repositories) werden nur einmal geparst. Liefert je Typ die Services, in
+// denen er vorkommt.
+func (g *UMLGenerator) LoadFederation(config *FederationConfig) (map[string][]string, error) {
+	g.Reset()
+	g.serviceDirs = make(map[string]string)
+
+	fileServices := make(map[string][]string) // Dateipfad -> Services
+	byContent := make(map[[32]byte]string)    // Inhalts-Hash -> erster Dateipfad
+
+	for _, service := range config.Services {
+		dir, err := service.checkout()
+		if err != nil {
+			return nil, err
+		}
+		goFiles, skipped, err := scanGoFiles(dir, g.options)
+		for filePath, reason := range skipped {
+			g.skippedFiles[filePath] = reason
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Fehler beim Durchsuchen von %s: %v", dir, err)
+		}
+		fmt.Printf("Service %s: %d Go-Dateien\n", service.Name, len(goFiles))
+		if abs, err := filepath.Abs(dir); err == nil {
+			g.serviceDirs[abs] = serviceQualifier(service.Name)
+		}
+
+		for _, filePath := range goFiles {
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				return nil, err
+			}
+			sum := sha256.Sum256(data)
+			if first, ok := byContent[sum]; ok {
+				fileServices[first] = appendUnique(fileServices[first], service.Name)
+				continue
+			}
+			byContent[sum] = filePath
+			fileServices[filePath] = []string{service.Name}
+
+			if err := g.parseFile(filePath); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := g.rebuild(); err != nil {
+		return nil, err
+	}
+
+	// Services je Typ aus den Deklarationen der Dateien ableiten
+	typeServices := make(map[string][]string)
+	for _, filePath := range sortedMapKeys(g.files) {
+		scope := g.fileScope(g.files[filePath])
+		for _, decl := range g.files[filePath].Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				name := g.typeKey(scope, spec.(*ast.TypeSpec).Name.Name)
+				for _, service := range fileServices[filePath] {
+					typeServices[name] = appendUnique(typeServices[name], service)
+				}
+			}
+		}
+	}
+	return typeServices, nil
+}
+
+// GenerateFederationPlantUML zeichnet die Typen gruppiert nach Service; Typen,
+// die in mehreren Services vorkommen, stehen einmal in der Gruppe "gemeinsam"
+func (g *UMLGenerator) GenerateFederationPlantUML(typeServices map[string][]string) string {
+	var sb strings.Builder
+	out := &plantUMLWriter{w: &sb}
+	out.WriteString("@startuml\n\n")
+
+	groups := make(map[string][]string)
+	for _, typeInfo := range g.Types() {
+		group := sharedGroup
+		if services := typeServices[typeInfo.Name]; len(services) == 1 {
+			group = services[0]
+		}
+		groups[group] = append(groups[group], typeInfo.Name)
+	}
+
+	for _, group := range sortedMapKeys(groups) {
+		if group == sharedGroup {
+			out.WriteString(fmt.Sprintf("package \"%s (geteilte Module)\" <<Folder>> {\n\n", group))
+		} else {
+			out.WriteString(fmt.Sprintf("package \"%s\" <<Node>> {\n\n", group))
+		}
+		for _, name := range groups[group] {
+			if structInfo, ok := g.structs[name]; ok {
+				writeStructPlantUML(out, structInfo)
+			} else if interfaceInfo, ok := g.interfaces[name]; ok {
+				writeInterfacePlantUML(out, interfaceInfo)
+			} else if namedInfo, ok := g.namedTypes[name]; ok {
+				writeNamedTypePlantUML(out, namedInfo)
+			}
+		}
+		out.WriteString("}\n\n")
+	}
+
+	for _, relation := range g.relations {
+		if _, ok := g.factories[relation.From]; ok {
+			continue
+		}
+		writeRelationPlantUML(out, relation)
+	}
+
+	out.WriteString("\n@enduml")
+	return sb.String()
+}
+
+// runFederate erzeugt das serviceübergreifende Diagramm (Unterbefehl "federate")
+func runFederate(args []string) int {
+	fs := flag.NewFlagSet("federate", flag.ExitOnError)
+	var options Options
+	fs.BoolVar(&options.AnalyzeBodies, "analyze-bodies", false, "Funktionsrümpfe analysieren (uses-, casts- und Factory-Beziehungen)")
+	fs.BoolVar(&options.PumlOnly, "puml-only", false, "nur .puml-Dateien schreiben, keine Bilder erzeugen")
+	fs.StringVar(&options.Renderers, "renderers", defaultRenderers, "Renderer in Reihenfolge der Präferenz: jar, local, kroki, public, puml")
+	fs.StringVar(&options.SkipDirs, "skip-dirs", "vendor,testdata", "kommagetrennte Verzeichnisnamen, die nicht durchsucht werden")
+	fs.StringVar(&options.Ignore, "ignore", "", "zusätzliche kommagetrennte Muster für zu ignorierende Dateien/Verzeichnisse")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Verwendung: uml-watcher federate [Optionen] <services.json> [Ausgabeverzeichnis]")
+		fs.PrintDefaults()
+	}
+	if err := applySubcommandConfig(fs); err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 2
+	}
+	outputDir := "output"
+	if fs.NArg() > 1 {
+		outputDir = fs.Arg(1)
+	}
+
+	config, err := loadFederationConfig(fs.Arg(0))
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+
+	g := NewUMLGenerator(options)
+	typeServices, err := g.LoadFederation(config)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	if err := g.writeDiagram(outputDir, "uml_federation", g.GenerateFederationPlantUML(typeServices)); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	if err := g.WriteManifest(outputDir); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	printWarnings(g.warnings)
+	return 0
+}
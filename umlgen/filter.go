@@ -0,0 +1,47 @@
+package umlgen
+
+// TypeInfo ist eine einheitliche, schreibgeschützte Sicht auf einen Typ des
+// Modells, unabhängig davon, ob es sich um eine Struct, ein Interface oder
+// einen sonstigen benannten Typ handelt
+type TypeInfo struct {
+	Name    string
+	Package string
+	Kind    string // "struct", "interface" oder "type"
+	Pos     Position
+	Fields  []FieldInfo
+	Methods []MethodInfo
+}
+
+// FilterFunc entscheidet, ob ein Typ ins Modell aufgenommen wird. Sie wird
+// von einbettenden Anwendungen über Options.Filter gesetzt.
+type FilterFunc func(TypeInfo) bool
+
+// Types liefert alle Typen des Modells in sortierter Reihenfolge
+func (g *UMLGenerator) Types() []TypeInfo {
+	var types []TypeInfo
+	for _, name := range sortedMapKeys(g.structs) {
+		s := g.structs[name]
+		types = append(types, TypeInfo{Name: s.Name, Package: s.Package, Kind: "struct", Pos: s.Pos, Fields: s.Fields, Methods: s.Methods})
+	}
+	for _, name := range sortedMapKeys(g.interfaces) {
+		i := g.interfaces[name]
+		types = append(types, TypeInfo{Name: i.Name, Package: i.Package, Kind: "interface", Pos: i.Pos, Methods: i.Methods})
+	}
+	for _, name := range sortedMapKeys(g.namedTypes) {
+		n := g.namedTypes[name]
+		types = append(types, TypeInfo{Name: n.Name, Package: n.Package, Kind: "type", Pos: n.Pos, Methods: n.Methods})
+	}
+	return types
+}
+
+// applyFilter entfernt alle Typen, die Options.Filter ablehnt, samt ihrer
+// Beziehungen aus dem Modell
+func (g *UMLGenerator) applyFilter(filter FilterFunc) {
+	keep := make(map[string]bool)
+	for _, typeInfo := range g.Types() {
+		if filter(typeInfo) {
+			keep[typeInfo.Name] = true
+		}
+	}
+	g.retainTypes(keep)
+}
@@ -0,0 +1,180 @@
+package umlgen
+
+import "slices"
+
+// PromotedFields sind die Felder, die eine eingebettete Struct an die
+// einbettende weiterreicht (--flatten-embedding)
+type PromotedFields struct {
+	From   string // Eingebettete Struct, die die Felder deklariert
+	Fields []FieldInfo
+}
+
+// flattenEmbedding ermittelt für jede Struct die Felder, die über
+// eingebettete Structs (auch mehrstufig) erreichbar sind. Wie in Go verdeckt
+// ein Feld geringerer Tiefe gleichnamige tiefere; kommt ein Name auf
+// derselben Tiefe mehrfach vor (auch bei rautenförmiger Einbettung), ist er
+// mehrdeutig und wird nicht gezeigt.
+func (g *UMLGenerator) flattenEmbedding() {
+	for _, name := range sortedMapKeys(g.structs) {
+		structInfo := g.structs[name]
+
+		hidden := make(map[string]bool) // Namen geringerer Tiefe
+		for _, field := range structInfo.Fields {
+			hidden[field.Name] = true
+		}
+
+		visited := map[string]bool{name: true} // Structs geringerer Tiefe
+		level := g.embeddedStructs(structInfo, visited)
+		for len(level) > 0 {
+			for _, embedded := range level {
+				visited[embedded] = true
+			}
+			counts := make(map[string]int)
+			for _, embedded := range level {
+				for _, field := range g.structs[embedded].Fields {
+					counts[field.Name]++
+				}
+			}
+
+			var next []string
+			for i, embedded := range level {
+				next = append(next, g.embeddedStructs(g.structs[embedded], visited)...)
+				if slices.Index(level, embedded) < i {
+					continue // Mehrfach eingebettet, Felder sind mehrdeutig
+				}
+				promoted := PromotedFields{From: embedded}
+				for _, field := range g.structs[embedded].Fields {
+					if !field.Embedded && !hidden[field.Name] && counts[field.Name] == 1 {
+						promoted.Fields = append(promoted.Fields, field)
+					}
+				}
+				if len(promoted.Fields) > 0 {
+					structInfo.Promoted = append(structInfo.Promoted, promoted)
+				}
+			}
+			for fieldName := range counts {
+				hidden[fieldName] = true
+			}
+			level = next
+		}
+	}
+}
+
+// embeddedStructs liefert die Structs, die structInfo einbettet, ohne die
+// bereits auf geringerer Tiefe besuchten
+func (g *UMLGenerator) embeddedStructs(structInfo *StructInfo, visited map[string]bool) []string {
+	var embedded []string
+	for _, field := range structInfo.Fields {
+		if !field.Embedded || visited[field.Target] {
+			continue
+		}
+		if _, ok := g.structs[field.Target]; ok {
+			embedded = append(embedded, field.Target)
+		}
+	}
+	return embedded
+}
+
+// promotedMarker kennzeichnet über Einbettung kopierte Felder und Methoden
+// (--expand-embedded)
+const promotedMarker = "<<promoted>> "
+
+// expandEmbedded kopiert die Felder und Methoden, die eingebettete Structs,
+// Interfaces und benannte Typen an eine Struct weiterreichen, mit dem
+// Stereotyp «promoted» in die einbettende Struct (--expand-embedded). Es
+// gelten dieselben Verdeckungs- und Mehrdeutigkeitsregeln wie bei
+// flattenEmbedding. Die Kopien werden erst nach der Berechnung für alle
+// Structs eingefügt, damit sie nicht selbst weitergereicht werden.
+func (g *UMLGenerator) expandEmbedded() {
+	promotedFields := make(map[string][]FieldInfo)
+	promotedMethods := make(map[string][]MethodInfo)
+	for _, name := range sortedMapKeys(g.structs) {
+		structInfo := g.structs[name]
+
+		hidden := make(map[string]bool) // Namen geringerer Tiefe
+		for _, field := range structInfo.Fields {
+			hidden[field.Name] = true
+		}
+		for _, method := range structInfo.Methods {
+			hidden[method.Name] = true
+		}
+
+		visited := map[string]bool{name: true}
+		level := g.embeddedTypes(structInfo, visited)
+		for len(level) > 0 {
+			for _, embedded := range level {
+				visited[embedded] = true
+			}
+			counts := make(map[string]int)
+			for _, embedded := range level {
+				fields, methods := g.embeddedMembers(embedded)
+				for _, field := range fields {
+					counts[field.Name]++
+				}
+				for _, method := range methods {
+					counts[method.Name]++
+				}
+			}
+
+			var next []string
+			for i, embedded := range level {
+				if embeddedInfo, ok := g.structs[embedded]; ok {
+					next = append(next, g.embeddedTypes(embeddedInfo, visited)...)
+				}
+				if slices.Index(level, embedded) < i {
+					continue // Mehrfach eingebettet, Member sind mehrdeutig
+				}
+				fields, methods := g.embeddedMembers(embedded)
+				for _, field := range fields {
+					if !field.Embedded && !hidden[field.Name] && counts[field.Name] == 1 {
+						field.Marker = promotedMarker
+						promotedFields[name] = append(promotedFields[name], field)
+					}
+				}
+				for _, method := range methods {
+					if !hidden[method.Name] && counts[method.Name] == 1 {
+						method.Marker = promotedMarker + method.Marker
+						promotedMethods[name] = append(promotedMethods[name], method)
+					}
+				}
+			}
+			for memberName := range counts {
+				hidden[memberName] = true
+			}
+			level = next
+		}
+	}
+
+	for name, structInfo := range g.structs {
+		structInfo.Fields = append(structInfo.Fields, promotedFields[name]...)
+		structInfo.Methods = append(structInfo.Methods, promotedMethods[name]...)
+	}
+}
+
+// embeddedTypes liefert die Structs, Interfaces und benannten Typen, die
+// structInfo einbettet, ohne die bereits auf geringerer Tiefe besuchten
+func (g *UMLGenerator) embeddedTypes(structInfo *StructInfo, visited map[string]bool) []string {
+	var embedded []string
+	for _, field := range structInfo.Fields {
+		if !field.Embedded || visited[field.Target] {
+			continue
+		}
+		if g.isKnownType(field.Target) {
+			embedded = append(embedded, field.Target)
+		}
+	}
+	return embedded
+}
+
+// embeddedMembers liefert die Felder und Methoden, die ein eingebetteter Typ
+// selbst deklariert; bei Interfaces den vollständigen Methodensatz
+func (g *UMLGenerator) embeddedMembers(name string) ([]FieldInfo, []MethodInfo) {
+	if structInfo, ok := g.structs[name]; ok {
+		return structInfo.Fields, structInfo.Methods
+	}
+	if namedInfo, ok := g.namedTypes[name]; ok {
+		return nil, namedInfo.Methods
+	}
+	methods, _ := g.interfaceMethodSet(name)
+	return nil, methods
+}
@@ -0,0 +1,53 @@
+package umlgen
+
+import "go/ast"
+
+// modelsFile prüft, ob die Deklarationen einer Datei ins Modell gehören.
+// Testdateien werden für --test-map auch ohne --include-tests geparst, ihre
+// Typen erscheinen aber nur mit --include-tests im Diagramm. Generierte
+// Dateien (// Code generated ... DO NOT EDIT.) bleiben für die Typprüfung
+// geparst und erscheinen nur mit --include-generated.
+func (g *UMLGenerator) modelsFile(filePath string) bool {
+	if isTestFile(filePath) && !g.options.IncludeTests {
+		return false
+	}
+	return g.options.IncludeGenerated || !ast.IsGenerated(g.files[filePath])
+}
+
+// warnSkippedFiles meldet die Dateien, die nicht ins Modell eingehen: die
+// von der Dateisuche ausgelassenen und geparste generierte Dateien
+func (g *UMLGenerator) warnSkippedFiles() {
+	for _, filePath := range sortedMapKeys(g.skippedFiles) {
+		g.warn(WarningSkippedFile, Position{File: filePath}, "Datei übersprungen: %s", g.skippedFiles[filePath])
+	}
+	for _, filePath := range sortedMapKeys(g.files) {
+		// Testdateien für --test-map gehen nur in die Testübersicht ein
+		if !g.modelsFile(filePath) && !isTestFile(filePath) {
+			g.warn(WarningSkippedFile, Position{File: filePath}, "Datei übersprungen: generierte Datei (--include-generated)")
+		}
+	}
+}
+
+// hideGeneratedMethods entfernt Methoden aus generierten Dateien (String von
+// stringer, DeepCopy* von deepcopy-gen, ...) aus den Klassen. Sie laufen
+// vorher durch die Beziehungserkennung, Implementierungen von fmt.Stringer
+// oder runtime.Object bleiben also sichtbar.
+func (g *UMLGenerator) hideGeneratedMethods() {
+	for _, structInfo := range g.structs {
+		structInfo.Methods = withoutGenerated(structInfo.Methods)
+	}
+	for _, namedInfo := range g.namedTypes {
+		namedInfo.Methods = withoutGenerated(namedInfo.Methods)
+	}
+}
+
+// withoutGenerated liefert die handgeschriebenen Methoden
+func withoutGenerated(methods []MethodInfo) []MethodInfo {
+	var kept []MethodInfo
+	for _, method := range methods {
+		if !method.Generated {
+			kept = append(kept, method)
+		}
+	}
+	return kept
+}
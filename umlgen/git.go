@@ -0,0 +1,70 @@
+package umlgen
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// gitCommit liefert den kurzen Commit-Hash des Repositorys, in dem dir liegt,
+// und ob es uncommittete Änderungen gibt. Außerhalb eines Repositorys oder
+// ohne git ist der Hash leer.
+func gitCommit(dir string) (hash string, dirty bool) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return "", false
+	}
+	hash = strings.TrimSpace(string(out))
+
+	status, err := exec.Command("git", "-C", dir, "status", "--porcelain", "--untracked-files=no").Output()
+	return hash, err == nil && len(bytes.TrimSpace(status)) > 0
+}
+
+// provenanceStamp beschreibt den Codestand für den Diagramm-Footer
+// (--stamp-commit), z.B. "Commit 1a2b3c4 (uncommittete Änderungen)"
+func provenanceStamp(dir string) string {
+	hash, dirty := gitCommit(dir)
+	if hash == "" {
+		return ""
+	}
+	if dirty {
+		return "Commit " + hash + " (uncommittete Änderungen)"
+	}
+	return "Commit " + hash
+}
+
+// gitDir liefert das Git-Verzeichnis des Repositorys, in dem dir liegt,
+// oder einen leeren String außerhalb eines Repositorys
+func gitDir(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// headState beschreibt den ausgecheckten Stand ohne git aufzurufen: Inhalt
+// von HEAD und, falls HEAD auf einen Branch zeigt, dessen Commit. So werden
+// Branch-Wechsel ebenso erkannt wie Commits, Resets und Rebases.
+func headState(gitDir string) string {
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	state := strings.TrimSpace(string(head))
+
+	ref, ok := strings.CutPrefix(state, "ref: ")
+	if !ok {
+		return state
+	}
+	if commit, err := os.ReadFile(filepath.Join(gitDir, filepath.FromSlash(ref))); err == nil {
+		return state + " " + strings.TrimSpace(string(commit))
+	}
+	// Ref nur in packed-refs vorhanden
+	if info, err := os.Stat(filepath.Join(gitDir, "packed-refs")); err == nil {
+		return state + " " + info.ModTime().String()
+	}
+	return state
+}
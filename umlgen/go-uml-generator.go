@@ -0,0 +1,3035 @@
+// Package umlgen erzeugt PlantUML-Klassendiagramme aus Go-Quelltext. Es
+// enthält den Generator samt Kommandozeile von go-uml-generator und kann
+// auch direkt eingebunden werden:
+//
+//	g := umlgen.NewUMLGenerator(umlgen.Options{
+//		Filter: func(t umlgen.TypeInfo) bool { return t.Package != "mocks" },
+//	})
+//	if err := g.GenerateUMLFromDirectory("./internal"); err != nil {
+//		...
+//	}
+//	fmt.Println(g.GeneratePlantUML())
+package umlgen
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
+)
+
+// UMLGenerator verwaltet die UML-Diagramm-Generierung
+type UMLGenerator struct {
+	structs          map[string]*StructInfo
+	interfaces       map[string]*InterfaceInfo
+	namedTypes       map[string]*NamedTypeInfo
+	factories        map[string]*FactoryInfo
+	elements         map[string]*ElementInfo // Kontextelemente ohne Code aus --overlay
+	functions        map[string][]MethodInfo // Importpfad -> Funktionen auf Paketebene (--functions)
+	relations        []Relation
+	warnings         []Warning
+	skippedFiles     map[string]string            // Von der Dateisuche ausgelassene Dateien -> Grund
+	artifacts        []Artifact                   // Im aktuellen Lauf geschriebene Ausgabedateien
+	renderers        []Renderer                   // Renderer-Kette, wird bei Bedarf aufgebaut
+	queue            *renderQueue                 // Ziel von --render-queue, wird bei Bedarf aufgebaut
+	stamp            string                       // Herkunftsangabe für den Footer (--stamp-commit)
+	hasCycles        bool                         // Zyklen wurden markiert (--cycles)
+	stereotypeColors map[string]string            // Stereotyp -> Hintergrundfarbe (--conventions)
+	pendingMethods   map[string][]MethodInfo      // Methoden, deren Receiver-Typ noch nicht geparst wurde
+	constructors     map[string][]MethodInfo      // Typname -> Konstruktoren, bis alle Typen bekannt sind
+	constants        map[string][]string          // Typname -> Namen der Konstanten dieses Typs
+	fieldDefaults    map[string]map[string]string // Typname -> Feld -> Standardwert aus dem Konstruktor (--field-defaults)
+	typeKeys         map[string]map[string]string // Paket -> Typname -> Modellschlüssel
+	scopePackages    map[string]string            // Paket (Verzeichnis:Name) -> Paketname
+	scopePaths       map[string]string            // Paket (Verzeichnis:Name) -> Importpfad
+	pathScopes       map[string]string            // Importpfad -> Paket (Verzeichnis:Name)
+	typePaths        map[string]string            // Modellschlüssel -> Importpfad des deklarierenden Pakets
+	serviceDirs      map[string]string            // Verzeichnis eines Services -> Servicename (federate)
+	files            map[string]*ast.File         // Geparste Dateien, aus denen das Modell aufgebaut wird
+	syntaxErrors     map[string]scanner.ErrorList // Datei -> Syntaxfehler, die Datei ist nur teilweise geparst
+	typesInfo        *types.Info                  // Typinformationen im Lademodus "types", sonst nil
+	typesPackages    map[string]bool              // Importpfade der analysierten Pakete
+	typeObjects      map[Position]*types.TypeName // Geprüfte Typdeklarationen nach Fundstelle
+	importer         *packagesImporter            // Importer für nicht analysierte Pakete, über Neuaufbauten hinweg zwischengespeichert
+	deadline         time.Time                    // Ende des Zeitbudgets (--budget), leer = unbegrenzt
+	budgetFiles      int                          // Wegen des Zeitbudgets nicht geparste Dateien
+	budgetSkipped    []string                     // Wegen des Zeitbudgets übersprungene Arbeitsschritte
+	fset             *token.FileSet
+	options          Options
+}
+
+// Options steuert Filterung und Darstellung der Generierung
+type Options struct {
+	Load                 string        // Lademodus: "ast" oder "types" (--load)
+	Select               string        // Auswahlausdruck (--select), leer = alle Typen
+	MaxTypes             int           // Maximale Anzahl Typen (--max-types), 0 = unbegrenzt
+	MaxEdges             int           // Maximale Anzahl Beziehungen (--max-edges), 0 = unbegrenzt
+	LimitAction          string        // Verhalten bei Überschreitung: "abort" oder "packages"
+	Budget               time.Duration // Zeitbudget der Generierung (--budget), danach Teildiagramm, 0 = unbegrenzt
+	Cluster              bool          // Übersicht nach Clustern plus ein Diagramm pro Cluster (--cluster)
+	Orphans              string        // Typen ohne Beziehungen: "show", "hide" oder "group"
+	AnalyzeBodies        bool          // Methodenrümpfe auf verwendete Typen untersuchen (--analyze-bodies)
+	BuildProducts        bool          // Beziehung vom Builder zum Produkt seiner Build()-Methode zeigen
+	ContextKeys          bool          // Bericht und Diagramm der Context-Schlüssel erzeugen (--context-keys)
+	InitOrder            bool          // Diagramm der Paketinitialisierung erzeugen (--init-order)
+	Ports                bool          // Interface-Sicht mit bereitstellenden und nutzenden Paketen erzeugen (--ports)
+	Cycles               bool          // Zyklen zwischen Typen melden und hervorheben (--cycles)
+	Goroutines           bool          // go-Anweisungen als «spawns»-Notizen und -Beziehungen zeigen (--goroutines)
+	PointerReceivers     string        // Methoden mit Pointer-Receiver kennzeichnen: "off", "prefix" oder "stereotype" (--pointer-receivers)
+	ContextAudit         string        // Ungenutzte Context-Parameter: "off", "report" oder "diagram" (--ctx-audit)
+	FlattenEmbedding     bool          // Felder eingebetteter Structs in der einbettenden Klasse zeigen (--flatten-embedding)
+	IncludeGenerated     bool          // Dateien mit "// Code generated ... DO NOT EDIT." ins Modell aufnehmen (--include-generated)
+	ShowGeneratedMethods bool          // Methoden aus generierten Dateien (String, DeepCopy, ...) anzeigen (--show-generated-methods)
+	ExpandEmbedded       bool          // Weitergereichte Felder und Methoden eingebetteter Typen als «promoted» in die Klasse kopieren (--expand-embedded)
+	ShowTags             string        // Kommagetrennte Tag-Schlüssel, die an Feldern angezeigt werden, z.B. "json,db" (--show-tags)
+	DocNotes             bool          // Ersten Satz der Doc-Kommentare als Notiz an Typen zeigen (--doc-notes)
+	GroupMethods         bool          // Methoden von Structs nach implementiertem Interface gruppieren (--group-methods)
+	MemberOrder          string        // Reihenfolge von Feldern und Methoden: "decl", "alpha" oder "visibility" (--member-order)
+	InlineStructs        string        // Anonyme Structs in Feldern: "collapse", "fields" oder "class" (--inline-structs)
+	Conventions          string        // Stereotypen nach Namens- und Paketkonventionen: "default" oder JSON-Datei (--conventions)
+	Functions            bool          // Funktionen auf Paketebene als «functions»-Klasse je Paket zeigen (--functions)
+	ExpandInterfaces     bool          // Geerbte Methoden eingebetteter Interfaces anzeigen (--expand-interfaces)
+	FieldDefaults        bool          // Felder mit den Standardwerten aus ihrem Konstruktor NewX beschriften (--field-defaults)
+	Constraints          bool          // Constraint-Interfaces mit Typmengen als «constraint» zeigen und «constrains»-Kanten ziehen (--constraints)
+	PProf                string        // CPU-Profil, dessen heiße Typen hervorgehoben werden (--pprof)
+	Overlay              string        // JSON-Datei, die das Modell vor dem Rendern verändert (--overlay)
+	IncludeTests         bool          // Typen aus _test.go-Dateien (Mocks, Testhelfer) ins Modell aufnehmen (--include-tests)
+	Workspace            string        // Diagramme bei go.work: "combined" oder zusätzlich eines je Modul mit "modules" (--workspace)
+	Compare              string        // Zwei kommagetrennte Pakete, die nebeneinander verglichen werden, z.B. "v1,v2" (--compare)
+	Cards                bool          // Eine Karte pro exportiertem Typ mit seinen direkten Beziehungen erzeugen (--cards)
+	TestMap              bool          // Diagramm der von Tests verwendeten Typen und Methoden erzeugen (--test-map)
+	Merge                string        // Kommagetrennte Modelle aus --emit json, die mit dem geparsten Modell zusammengeführt werden (--merge)
+	Emit                 string        // Modell zusätzlich als Zwischendarstellung schreiben: "json" oder leer (--emit)
+	Stats                string        // Ausgabe der Statistik: "text", "json" oder "off"
+	StatsFooter          bool          // Statistik als Footer ins Diagramm einbetten
+	StampCommit          bool          // Commit-Hash und Änderungsstatus in den Footer schreiben (--stamp-commit)
+	Strict               bool          // Warnungen als Fehler behandeln (--strict)
+	Once                 bool          // Nur einmal generieren statt zu überwachen (--once)
+	Prune                bool          // Veraltete Ausgabedateien laut Manifest löschen (--prune)
+	RenderQueue          string        // Renderaufträge in ein Verzeichnis oder nach redis://host:port/schlüssel schreiben statt zu rendern (--render-queue)
+	QueueFormats         string        // Kommagetrennte Formate der Renderaufträge, z.B. "png,svg" (--queue-formats)
+	Renderers            string        // Renderer-Kette, z.B. "jar,local,kroki,public,puml" (--renderers)
+	LocalServer          string        // Adresse des lokalen PlantUML-Servers (--local-server)
+	KrokiURL             string        // Adresse des Kroki-Dienstes (--kroki-url)
+	Server               string        // Adresse des öffentlichen PlantUML-Servers (--server)
+	Serve                string        // Adresse, unter der Betrachter das Diagramm im Watch-Modus live sehen, z.B. ":8000" (--serve)
+	Announce             bool          // Betrachter per mDNS im lokalen Netz ankündigen (--announce)
+	StartServer          bool          // PlantUML-Server als Kindprozess starten (--start-plantuml-server)
+	ServerPort           int           // Port des gestarteten PlantUML-Servers (--plantuml-server-port), 0 = freier Port
+	KeepHistory          int           // Anzahl aufbewahrter Snapshots unter history/ (--keep-history, 0 = aus)
+	HistoryImages        bool          // Snapshots auch als PNG aufbewahren (--history-images)
+	Diff                 bool          // Differenzbild zum vorherigen PNG erzeugen (--diff)
+	Badges               bool          // SVG-Badges mit Kennzahlen erzeugen (--badges)
+	Metrics              string        // Paketmetriken (Instabilität, Abstraktheit) ausgeben: "text", "json" oder "off" (--metrics)
+	MetricsPlot          bool          // Hauptreihen-Diagramm als main_sequence.svg erzeugen (--metrics-plot)
+	Upload               string        // Ausgaben in einen Objektspeicher laden: s3://bucket/prefix oder gs://bucket/prefix (--upload)
+	Site                 string        // Dokumentationsseite aktualisieren: "mkdocs" oder "hugo" (--site)
+	SiteDir              string        // Wurzelverzeichnis der Dokumentationsseite (--site-dir)
+	DocLinks             string        // Diagramm pro Paket und Verweis aus "readme" oder "docgo" (--doc-links)
+	AltText              bool          // Textzusammenfassung je Diagramm als <name>.alt.txt (--alt-text)
+	GOOS                 string        // Zielbetriebssystem für Build-Bedingungen (--goos, leer = aktuelles)
+	GOARCH               string        // Zielarchitektur für Build-Bedingungen (--goarch, leer = aktuelle)
+	BuildTags            string        // Kommagetrennte Build-Tags (--tags)
+	SkipDirs             string        // Kommagetrennte Verzeichnisnamen, die nicht durchsucht werden (--skip-dirs)
+	Ignore               string        // Zusätzliche kommagetrennte Ignoriermuster für Dateien und Verzeichnisse (--ignore)
+	PumlOnly             bool          // Nur .puml-Dateien schreiben, keine Bilder erzeugen (--puml-only)
+	NoDownload           bool          // plantuml.jar nie herunterladen (--no-download)
+	JarMirrors           string        // Kommagetrennte Download-Quellen für plantuml.jar (--jar-mirrors)
+	JarSHA256            string        // Erwartete SHA-256 von plantuml.jar (--jar-sha256)
+	FileMode             os.FileMode   // Rechte der Ausgabedateien (--file-mode, 0 = 0644)
+	DirMode              os.FileMode   // Rechte neu angelegter Ausgabeverzeichnisse (--dir-mode, 0 = 0755)
+
+	// Filter entscheidet programmatisch, welche Typen ins Modell gelangen
+	// (nur für einbettende Anwendungen, kein Kommandozeilen-Flag)
+	Filter FilterFunc
+}
+
+// StructInfo enthält Informationen über eine Struct
+type StructInfo struct {
+	Name         string
+	Package      string
+	Pos          Position
+	TypeParams   []TypeParamInfo // Typparameter generischer Structs
+	Doc          string          // Doc-Kommentar der Deklaration
+	Fields       []FieldInfo
+	Methods      []MethodInfo
+	Constructors []MethodInfo     // Konstruktoren NewX, die *X oder X liefern
+	Promoted     []PromotedFields // Felder eingebetteter Structs (--flatten-embedding)
+	MethodGroups []MethodGroup    // Methoden nach implementiertem Interface (--group-methods)
+	OptionFuncs  []MethodInfo     // Funktionale Optionen (WithX), die diese Struct konfigurieren
+	Stereotypes  []string         // Erkannte Muster, z.B. "singleton"
+	Notes        []string         // Hinweise, die als PlantUML-Notiz angezeigt werden
+	CPUShare     float64          // Anteil an der CPU-Zeit laut --pprof
+}
+
+// InterfaceInfo enthält Informationen über ein Interface
+type InterfaceInfo struct {
+	Name        string
+	Package     string
+	Pos         Position
+	TypeParams  []TypeParamInfo // Typparameter generischer Interfaces
+	Doc         string          // Doc-Kommentar der Deklaration
+	Methods     []MethodInfo
+	Embeds      []string     // Eingebettete Interfaces, z.B. Reader und Writer in ReadWriter
+	TypeSet     []string     // Typmengen eines Constraints, z.B. "~int | ~float64"
+	Foreign     []string     // Eingebettete Interfaces außerhalb des Modells, z.B. io.Reader
+	Inherited   []MethodInfo // Geerbte Methoden eingebetteter Interfaces (--expand-interfaces)
+	Stereotypes []string     // z.B. "planned" für Typen aus --overlay
+}
+
+// NamedTypeInfo enthält Informationen über einen benannten Nicht-Struct-Typ,
+// z.B. type Celsius float64 oder type Users []User, oder über einen Alias
+// wie type Handler = http.HandlerFunc
+type NamedTypeInfo struct {
+	Name         string
+	Package      string
+	Pos          Position
+	Underlying   string          // Zugrundeliegender Typ wie im Quelltext
+	TypeParams   []TypeParamInfo // Typparameter generischer Typen
+	Doc          string          // Doc-Kommentar der Deklaration
+	Alias        bool            // Typalias (type A = B) statt eigenem Typ
+	EnumValues   []string        // Konstanten des Typs, wenn er als Aufzählung dient
+	Stereotypes  []string        // Zusätzliche Stereotypen, z.B. "zyklus"
+	Target       string          // Benannter Elementtyp des zugrundeliegenden Typs
+	Multiple     bool            // Zugrundeliegender Typ ist ein Container
+	Methods      []MethodInfo
+	Constructors []MethodInfo  // Konstruktoren NewX, die *X oder X liefern
+	MethodGroups []MethodGroup // Methoden nach implementiertem Interface (--group-methods)
+	CPUShare     float64       // Anteil an der CPU-Zeit laut --pprof
+}
+
+// FactoryInfo beschreibt eine Paketfunktion, die ein Interface zurückgibt,
+// aber eine konkrete Struct erzeugt (Factory-Idiom)
+type FactoryInfo struct {
+	Name    string
+	Package string
+	Pos     Position
+	Exposes string   // Zurückgegebenes Interface
+	Creates []string // Erzeugte konkrete Typen
+}
+
+// FieldInfo repräsentiert ein Feld in einer Struct
+type FieldInfo struct {
+	Name     string
+	Type     string
+	Pos      Position
+	Target   string            // Benannter Elementtyp nach Entpacken von Pointern und Containern
+	Multiple bool              // Feld enthält mehrere Elemente (Slice, Array, Map, Channel)
+	ChanDir  string            // Bei Channel-Feldern: "both", "send" oder "recv"
+	IsMap    bool              // Feld ist eine Map; Target ist dann der Werttyp
+	MapKey   string            // Benannter Schlüsseltyp einer Map
+	Tag      reflect.StructTag // Struct-Tag ohne Backquotes, z.B. json:"name" db:"name"
+	Shown    string            // Anzuzeigende Tags (--show-tags), z.B. json:"name"
+	Doc      string            // Kommentar über oder hinter dem Feld
+	Inline   []FieldInfo       // Felder einer anonymen Struct (--inline-structs fields)
+	Marker   string            // Kennzeichnung vor dem Namen, z.B. "<<promoted>> " (--expand-embedded)
+	Default  string            // Standardwert aus dem Konstruktor, z.B. "30 * time.Second" (--field-defaults)
+	Embedded bool              // Eingebettetes Feld; Name und Type sind dann der Typ, z.B. *Base
+}
+
+// MethodInfo repräsentiert eine Methode
+type MethodInfo struct {
+	Name       string
+	Pos        Position
+	TypeParams []string // Namen der Typparameter des Receivers, z.B. T bei (s *Stack[T])
+	Parameters []ParameterInfo
+	Results    []ParameterInfo // Rückgabewerte, benannte samt Namen
+	ReturnType string          // Rückgabe wie dargestellt, z.B. "error" oder "(n: int, err: error)"
+	Pointer    bool            // Pointer-Receiver (func (s *S)) statt Wert-Receiver
+	Marker     string          // Kennzeichnung vor dem Namen, z.B. "*" für Pointer-Receiver (--pointer-receivers)
+	Doc        string          // Doc-Kommentar der Funktion oder Interface-Methode
+	Generated  bool            // Stammt aus einer generierten Datei (stringer, deepcopy-gen, ...)
+}
+
+// TypeParamInfo beschreibt einen Typparameter samt Constraint
+type TypeParamInfo struct {
+	Name       string
+	Constraint string   // z.B. "any", "comparable" oder "~int | ~float64"
+	Targets    []string // Benannte Typen im Constraint, z.B. Number bei T Number | ~string
+}
+
+// Position gibt an, wo ein Element im Quelltext definiert ist
+type Position struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// String liefert die Position im Format datei:zeile
+func (p Position) String() string {
+	if p.File == "" {
+		return ""
+	}
+	if p.Line == 0 {
+		return p.File
+	}
+	return fmt.Sprintf("%s:%d", p.File, p.Line)
+}
+
+// ParameterInfo repräsentiert einen Parameter einer Methode
+type ParameterInfo struct {
+	Name string
+	Type string
+}
+
+// Relation repräsentiert eine Beziehung zwischen Typen
+type Relation struct {
+	From        string
+	To          string
+	Type        string // "extends", "implements", "aggregation", "composition", "association", "uses", "casts", "creates", "exposes", "builds", "constrains"
+	Cardinality string
+	Label       string   // Optionale Beschriftung der Kante
+	Pos         Position // Feld oder Typ, aus dem die Beziehung abgeleitet wurde
+	Weight      int      // Anzahl der Felder und Aufrufe, die zur Beziehung beitragen (0 = 1)
+	Cycle       bool     // Beziehung liegt in einem Zyklus zwischen Typen (--cycles)
+}
+
+// weight liefert das Gewicht der Beziehung, mindestens 1
+func (r Relation) weight() int {
+	if r.Weight < 1 {
+		return 1
+	}
+	return r.Weight
+}
+
+// mergeRelations fasst gleiche Beziehungen zusammen, z.B. zwei Felder vom
+// selben Typ, und summiert ihre Gewichte. Die erste Fundstelle bleibt erhalten.
+func (g *UMLGenerator) mergeRelations() {
+	type relationKey struct{ from, to, kind, cardinality, label string }
+	index := make(map[relationKey]int)
+	var merged []Relation
+	for _, relation := range g.relations {
+		key := relationKey{relation.From, relation.To, relation.Type, relation.Cardinality, relation.Label}
+		if i, ok := index[key]; ok {
+			merged[i].Weight = merged[i].weight() + relation.weight()
+			continue
+		}
+		index[key] = len(merged)
+		relation.Weight = relation.weight()
+		merged = append(merged, relation)
+	}
+	g.relations = merged
+}
+
+// FileWatcher überwacht Dateiänderungen in einem Verzeichnis
+type FileWatcher struct {
+	dirPath      string               // Pfad zum zu überwachenden Verzeichnis
+	lastModified map[string]time.Time // Speichert letzte Änderungszeit pro Datei
+	outputDir    string
+	options      Options
+	generator    *UMLGenerator // Wird zwischen Änderungen inkrementell aktualisiert
+	gitDir       string        // Git-Verzeichnis, dessen HEAD überwacht wird
+	headState    string        // Zuletzt gesehener Stand von HEAD
+}
+
+func NewUMLGenerator(options Options) *UMLGenerator {
+	return &UMLGenerator{
+		structs:          make(map[string]*StructInfo),
+		interfaces:       make(map[string]*InterfaceInfo),
+		namedTypes:       make(map[string]*NamedTypeInfo),
+		factories:        make(map[string]*FactoryInfo),
+		elements:         make(map[string]*ElementInfo),
+		functions:        make(map[string][]MethodInfo),
+		relations:        []Relation{},
+		pendingMethods:   make(map[string][]MethodInfo),
+		constructors:     make(map[string][]MethodInfo),
+		stereotypeColors: make(map[string]string),
+		constants:        make(map[string][]string),
+		files:            make(map[string]*ast.File),
+		syntaxErrors:     make(map[string]scanner.ErrorList),
+		fset:             token.NewFileSet(),
+		options:          options,
+	}
+}
+
+// Reset verwirft alle geparsten Dateien und leert das Modell
+func (g *UMLGenerator) Reset() {
+	g.files = make(map[string]*ast.File)
+	g.syntaxErrors = make(map[string]scanner.ErrorList)
+	g.skippedFiles = make(map[string]string)
+	g.fset = token.NewFileSet()
+	g.importer = nil // Abhängigkeiten können sich seitdem geändert haben
+	g.clearModel()
+}
+
+// clearModel leert das Modell, behält aber die geparsten Dateien
+func (g *UMLGenerator) clearModel() {
+	g.structs = make(map[string]*StructInfo)
+	g.interfaces = make(map[string]*InterfaceInfo)
+	g.namedTypes = make(map[string]*NamedTypeInfo)
+	g.factories = make(map[string]*FactoryInfo)
+	g.elements = make(map[string]*ElementInfo)
+	g.functions = make(map[string][]MethodInfo)
+	g.relations = []Relation{}
+	g.hasCycles = false
+	g.stereotypeColors = make(map[string]string)
+	g.warnings = nil
+	g.pendingMethods = make(map[string][]MethodInfo)
+	g.constructors = make(map[string][]MethodInfo)
+	g.constants = make(map[string][]string)
+	g.fieldDefaults = make(map[string]map[string]string)
+	g.budgetSkipped = nil
+}
+
+// ParseGoFile parst eine Datei und führt sie mit den bereits geparsten
+// Dateien zusammen. Wird dieselbe Datei erneut geparst, ersetzt sie ihren
+// früheren Stand, sodass keine Duplikate entstehen.
+func (g *UMLGenerator) ParseGoFile(filePath string) error {
+	if err := g.parseFile(filePath); err != nil {
+		return err
+	}
+	return g.rebuild()
+}
+
+// RemoveFile entfernt eine Datei und alle aus ihr stammenden Typen und
+// Methoden aus dem Modell (z.B. wenn sie im Watch-Modus gelöscht wurde)
+func (g *UMLGenerator) RemoveFile(filePath string) error {
+	if _, ok := g.files[filePath]; !ok {
+		return nil
+	}
+	g.forgetFile(filePath)
+	return g.rebuild()
+}
+
+// UpdateFiles parst geänderte Dateien neu, entfernt gelöschte Dateien und
+// baut das Modell anschließend einmalig neu auf. Geänderte Dateien, die es
+// nicht mehr gibt, gelten als gelöscht. Kann eine Datei nicht gelesen
+// werden, wird das Modell trotzdem neu aufgebaut und der erste Fehler
+// zurückgegeben.
+func (g *UMLGenerator) UpdateFiles(changed, removed []string) error {
+	for _, filePath := range removed {
+		g.forgetFile(filePath)
+	}
+	g.startBudget()
+	var firstErr error
+	for i, filePath := range changed {
+		if g.overBudget() {
+			g.budgetFiles = len(changed) - i
+			break
+		}
+		fmt.Printf("Verarbeite: %s\n", filePath)
+		err := g.parseFile(filePath)
+		if errors.Is(err, fs.ErrNotExist) {
+			g.forgetFile(filePath)
+		} else if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if err := g.rebuild(); err != nil {
+		return err
+	}
+	return firstErr
+}
+
+// forgetFile entfernt eine Datei aus den geparsten Dateien und dem FileSet
+func (g *UMLGenerator) forgetFile(filePath string) {
+	delete(g.files, filePath)
+	delete(g.syntaxErrors, filePath)
+	g.releaseFile(filePath, nil)
+}
+
+// releaseFile entfernt frühere Stände einer Datei aus dem FileSet, außer dem
+// von keep. Ohne das wüchse das FileSet im Watch-Modus mit jedem Neuparsen.
+func (g *UMLGenerator) releaseFile(filePath string, keep *ast.File) {
+	var kept *token.File
+	if keep != nil {
+		kept = g.fset.File(keep.Package)
+	}
+	var stale []*token.File
+	g.fset.Iterate(func(file *token.File) bool {
+		if file.Name() == filePath && file != kept {
+			stale = append(stale, file)
+		}
+		return true
+	})
+	for _, file := range stale {
+		g.fset.RemoveFile(file)
+	}
+}
+
+// parseFile parst eine Datei und merkt sie vor, ohne das Modell neu aufzubauen.
+// Syntaxfehler brechen nicht ab: fehlerhafte Deklarationen entfallen, der
+// Rest der Datei wird verwendet (siehe parseTolerant). Ist schon die
+// package-Klausel kaputt, bleibt der letzte lesbare Stand der Datei erhalten.
+func (g *UMLGenerator) parseFile(filePath string) error {
+	node, syntaxErrors, err := parseTolerant(g.fset, filePath)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Parsen der Datei %s: %w", filePath, err)
+	}
+	delete(g.syntaxErrors, filePath)
+	if len(syntaxErrors) > 0 {
+		g.syntaxErrors[filePath] = syntaxErrors
+	}
+	if node.Name.Name != "" {
+		g.files[filePath] = node
+	}
+	g.releaseFile(filePath, g.files[filePath])
+	return nil
+}
+
+// rebuild baut das Modell aus allen geparsten Dateien neu auf. Die Dateien
+// werden in sortierter Reihenfolge verarbeitet, damit das Ergebnis nicht von
+// der Reihenfolge der ParseGoFile-Aufrufe abhängt.
+func (g *UMLGenerator) rebuild() error {
+	g.clearModel()
+	for _, filePath := range sortedMapKeys(g.syntaxErrors) {
+		first := g.syntaxErrors[filePath][0]
+		pos := Position{File: first.Pos.Filename, Line: first.Pos.Line}
+		if _, parsed := g.files[filePath]; !parsed {
+			g.warn(WarningSkippedFile, pos, "Parsen abgebrochen, Datei übersprungen: %s", first.Msg)
+			continue
+		}
+		g.warn(WarningSyntaxError, pos,
+			"Syntaxfehler, Datei nur teilweise verarbeitet: %s (%d Fehler)", first.Msg, len(g.syntaxErrors[filePath]))
+	}
+	g.warnSkippedFiles()
+	if g.budgetFiles > 0 {
+		g.budgetSkipped = append(g.budgetSkipped, fmt.Sprintf("%d Dateien", g.budgetFiles))
+		g.warn(WarningBudget, Position{}, "Zeitbudget von %s überschritten, %d Dateien nicht geparst", g.options.Budget, g.budgetFiles)
+	}
+	g.collectTypeKeys()
+	if g.options.Load == LoadTypes {
+		if g.withinBudget("Typprüfung") {
+			g.typeCheck()
+		} else {
+			g.typesInfo = nil
+		}
+	}
+
+	for _, filePath := range sortedMapKeys(g.files) {
+		if g.modelsFile(filePath) {
+			g.processFile(g.files[filePath])
+		}
+	}
+	g.attachAliasMethods()
+	g.attachConstructors()
+	if g.options.FieldDefaults {
+		g.attachFieldDefaults()
+	}
+	g.attachEnumValues()
+	g.detectSingletons()
+	g.detectFunctionalOptions()
+	if g.options.Merge != "" {
+		if err := g.mergeModels(g.options.Merge); err != nil {
+			return err
+		}
+	}
+	if g.options.FlattenEmbedding {
+		g.flattenEmbedding()
+	}
+
+	// Methoden, deren Receiver-Typ nie gefunden wurde
+	for _, typeName := range sortedMapKeys(g.pendingMethods) {
+		for _, method := range g.pendingMethods[typeName] {
+			g.warn(WarningUnresolvedReceiver, method.Pos, "Methode %s: Receiver-Typ %s ist unbekannt", method.Name, typeName)
+		}
+	}
+
+	// Beziehungen identifizieren
+	g.identifyRelations()
+	if g.options.ExpandInterfaces {
+		g.expandInterfaces()
+	}
+	if g.options.Constraints {
+		g.constraintRelations()
+	}
+	if !g.options.ShowGeneratedMethods {
+		g.hideGeneratedMethods()
+	}
+	g.detectBuilders()
+	if g.options.GroupMethods {
+		g.groupMethodsByInterface()
+	}
+	if g.options.AnalyzeBodies && g.withinBudget("Rumpfanalyse") {
+		g.analyzeBodies()
+		g.detectFactories()
+	}
+	if g.options.Goroutines && g.withinBudget("Goroutinen") {
+		g.detectSpawns()
+	}
+	if g.options.ContextAudit != "off" && g.withinBudget("Context-Prüfung") {
+		if g.options.ContextAudit == "diagram" {
+			g.markContextAudit()
+		}
+	}
+	g.mergeRelations()
+	if g.options.PProf != "" {
+		if err := g.ApplyProfile(g.options.PProf); err != nil {
+			return err
+		}
+	}
+
+	if g.options.Overlay != "" {
+		if err := g.ApplyOverlay(g.options.Overlay); err != nil {
+			return err
+		}
+	}
+
+	if g.options.ExpandEmbedded {
+		g.expandEmbedded()
+	}
+	g.orderMembers()
+	if g.options.Conventions != "" {
+		if err := g.ApplyConventions(g.options.Conventions); err != nil {
+			return err
+		}
+	}
+
+	// Auswahlausdruck und programmatischen Filter anwenden
+	if g.options.Select != "" {
+		if err := g.ApplySelection(g.options.Select); err != nil {
+			return err
+		}
+	}
+	if g.options.Filter != nil {
+		g.applyFilter(g.options.Filter)
+	}
+	if g.options.Cycles && g.withinBudget("Zyklensuche") {
+		g.markCycles()
+	}
+
+	return nil
+}
+
+// position wandelt eine AST-Position in Datei und Zeile um
+func (g *UMLGenerator) position(pos token.Pos) Position {
+	p := g.fset.Position(pos)
+	return Position{File: p.Filename, Line: p.Line}
+}
+
+// processFile überträgt die Deklarationen einer Datei ins Modell
+func (g *UMLGenerator) processFile(node *ast.File) {
+	scope := g.fileScope(node)
+	generated := ast.IsGenerated(node)
+	// Durchlaufe alle Deklarationen im AST
+	for _, decl := range node.Decls {
+		// Typ-Deklarationen verarbeiten
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.TYPE {
+			for _, spec := range genDecl.Specs {
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+					// Bei type X struct{} steht der Kommentar an der GenDecl,
+					// in type ( ... )-Blöcken an der einzelnen Spezifikation
+					doc := typeSpec.Doc
+					if doc == nil && len(genDecl.Specs) == 1 {
+						doc = genDecl.Doc
+					}
+					g.processTypeSpec(typeSpec, node, doc.Text())
+				}
+			}
+		}
+
+		// Konstanten für Aufzählungen merken
+		if genDecl, ok := constDecl(decl); ok {
+			g.processConstDecl(genDecl, scope)
+		}
+
+		// Methoden verarbeiten
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Recv != nil {
+			g.processMethod(funcDecl, scope, generated)
+		}
+
+		// Konstruktoren und Funktionen auf Paketebene sammeln; init und Tests
+		// gehören nicht zur API, Testhelfer nur mit --include-tests
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Recv == nil && !isTestFunc(funcDecl) {
+			functionInfo := g.buildMethodInfo(funcDecl.Name.Name, funcDecl.Type, funcDecl.Pos())
+			functionInfo.Doc = funcDecl.Doc.Text()
+			if typeName := constructedType(funcDecl); typeName != "" {
+				key := g.typeKey(scope, typeName)
+				g.constructors[key] = append(g.constructors[key], functionInfo)
+				// Standardwerte liefert der erste Konstruktor des Typs
+				if _, seen := g.fieldDefaults[key]; g.options.FieldDefaults && !seen {
+					g.fieldDefaults[key] = constructorDefaults(funcDecl, typeName)
+				}
+			}
+			if g.options.Functions && funcDecl.Name.Name != "init" {
+				importPath := g.scopePaths[scope]
+				g.functions[importPath] = append(g.functions[importPath], functionInfo)
+			}
+		}
+	}
+}
+
+// shownTags wählt die Tags mit den kommagetrennten Schlüsseln keys aus,
+// in deren Reihenfolge, z.B. json:"name" validate:"required"
+func shownTags(tag reflect.StructTag, keys string) string {
+	if keys == "" {
+		return ""
+	}
+	var parts []string
+	for _, key := range strings.Split(keys, ",") {
+		key = strings.TrimSpace(key)
+		if value, ok := tag.Lookup(key); ok {
+			parts = append(parts, fmt.Sprintf("%s:%q", key, value))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// writeInlineFields schreibt die Felder einer anonymen Struct mit dem Pfad
+// des umgebenden Felds, z.B. +Config.Host: string
+func writeInlineFields(out *plantUMLWriter, path string, fields []FieldInfo) {
+	for _, field := range fields {
+		field.Name = path + "." + field.Name
+		out.WriteString(formatFieldPlantUML(field))
+		writeInlineFields(out, field.Name, field.Inline)
+	}
+}
+
+// formatFieldPlantUML formatiert ein Feld als Zeile eines Klassenkörpers,
+// ein Standardwert folgt nach =, ausgewählte Tags in «», z.B.
+// +Port: int = 8080 «json:"port"»
+func formatFieldPlantUML(field FieldInfo) string {
+	fieldType := field.Type
+	if field.Default != "" {
+		fieldType += " = " + field.Default
+	}
+	if field.Shown != "" {
+		return fmt.Sprintf("    +%s%s: %s «%s»\n", field.Marker, field.Name, fieldType, field.Shown)
+	}
+	return fmt.Sprintf("    +%s%s: %s\n", field.Marker, field.Name, fieldType)
+}
+
+// structFields extrahiert die Felder einer Struct. Anonyme Structs in
+// Feldern (Config struct { Host string }) werden je nach --inline-structs
+// als Unterfelder oder als eigene Klasse owner_Feld übernommen.
+func (g *UMLGenerator) structFields(structType *ast.StructType, file *ast.File, owner string) []FieldInfo {
+	fields := []FieldInfo{}
+	if structType.Fields == nil {
+		return fields
+	}
+	for _, field := range structType.Fields.List {
+		fieldType := getTypeString(field.Type)
+		target, multiple := containerTarget(field.Type)
+		target = g.resolvedTarget(field.Type, g.modelTarget(file, target))
+		chanDir := channelDirection(field.Type)
+		mapKey, isMap := mapKeyTarget(field.Type)
+		if mapKey != "" {
+			mapKey = g.modelTarget(file, mapKey)
+		}
+
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			if unquoted, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(unquoted)
+			}
+		}
+		shown := shownTags(tag, g.options.ShowTags)
+		fieldDoc := field.Doc.Text()
+		if fieldDoc == "" {
+			fieldDoc = field.Comment.Text()
+		}
+
+		if len(field.Names) == 0 {
+			// Anonymes Feld (Embedding)
+			fields = append(fields, FieldInfo{
+				Name:     fieldType,
+				Type:     fieldType,
+				Pos:      g.position(field.Pos()),
+				Target:   target,
+				Tag:      tag,
+				Doc:      fieldDoc,
+				Embedded: true,
+			})
+			continue
+		}
+
+		anonymous := inlineStruct(field.Type)
+		for _, name := range field.Names {
+			fieldInfo := FieldInfo{
+				Name:     name.Name,
+				Type:     fieldType,
+				Pos:      g.position(name.Pos()),
+				Target:   target,
+				Multiple: multiple,
+				ChanDir:  chanDir,
+				IsMap:    isMap,
+				MapKey:   mapKey,
+				Tag:      tag,
+				Shown:    shown,
+				Doc:      fieldDoc,
+			}
+			if anonymous != nil {
+				key := owner + "_" + name.Name
+				switch g.options.InlineStructs {
+				case InlineStructsFields:
+					fieldInfo.Inline = g.structFields(anonymous, file, key)
+				case InlineStructsClass:
+					g.structs[key] = &StructInfo{
+						Name:        key,
+						Package:     file.Name.Name,
+						Pos:         fieldInfo.Pos,
+						Fields:      g.structFields(anonymous, file, key),
+						Methods:     []MethodInfo{},
+						Stereotypes: []string{"anonymous"},
+					}
+					g.typePaths[key] = g.typePaths[owner]
+					fieldInfo.Type = strings.Replace(fieldType, "struct", key, 1)
+					fieldInfo.Target = key
+				}
+			}
+			fields = append(fields, fieldInfo)
+		}
+	}
+	return fields
+}
+
+// Werte für --inline-structs
+const (
+	InlineStructsCollapse = "collapse" // Nur "struct" als Feldtyp
+	InlineStructsFields   = "fields"   // Unterfelder als Config.Host unter dem Feld
+	InlineStructsClass    = "class"    // Eigene Klasse «anonymous» mit Komposition
+)
+
+// inlineStruct liefert die anonyme Struct eines Feldtyps, auch hinter
+// Pointern, Slices und Maps, z.B. bei []struct{ ... }
+func inlineStruct(expr ast.Expr) *ast.StructType {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		return t
+	case *ast.StarExpr:
+		return inlineStruct(t.X)
+	case *ast.ParenExpr:
+		return inlineStruct(t.X)
+	case *ast.ArrayType:
+		return inlineStruct(t.Elt)
+	case *ast.MapType:
+		return inlineStruct(t.Value)
+	default:
+		return nil
+	}
+}
+
+// processTypeSpec überträgt eine Typdeklaration samt Doc-Kommentar ins
+// Modell. Typnamen und Elementtypen werden im Paket und über die Importe
+// der Datei auf ihre Modellschlüssel abgebildet.
+func (g *UMLGenerator) processTypeSpec(typeSpec *ast.TypeSpec, file *ast.File, doc string) {
+	pkgName := file.Name.Name
+	typeName := g.typeKey(g.fileScope(file), typeSpec.Name.Name)
+
+	// Struct verarbeiten
+	if structType, ok := typeSpec.Type.(*ast.StructType); ok {
+		structInfo := &StructInfo{Name: typeName, Package: pkgName, Pos: g.position(typeSpec.Pos()), Doc: doc, Methods: []MethodInfo{}}
+
+		structInfo.TypeParams = g.typeParamList(typeSpec.TypeParams, file)
+
+		// Felder extrahieren
+		structInfo.Fields = g.structFields(structType, file, typeName)
+
+		// Bereits vorher gefundene Methoden (frühere Datei oder weiter oben
+		// in derselben Datei) jetzt anhängen
+		structInfo.Methods = append(structInfo.Methods, g.pendingMethods[typeName]...)
+		delete(g.pendingMethods, typeName)
+
+		g.structs[typeName] = structInfo
+		return
+	}
+
+	// Interface verarbeiten
+	if interfaceType, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+		interfaceInfo := &InterfaceInfo{Name: typeName, Package: pkgName, Pos: g.position(typeSpec.Pos()), Doc: doc, Methods: []MethodInfo{}}
+		interfaceInfo.TypeParams = g.typeParamList(typeSpec.TypeParams, file)
+
+		// Interface-Methoden extrahieren
+		if interfaceType.Methods != nil {
+			for _, method := range interfaceType.Methods.List {
+				// Eingebettete Interfaces und Typmengen wie ~int | ~float64
+				if len(method.Names) == 0 {
+					if term := typeSetTerm(method.Type); term != "" {
+						interfaceInfo.TypeSet = append(interfaceInfo.TypeSet, term)
+						continue
+					}
+					switch method.Type.(type) {
+					case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.IndexListExpr:
+						target, _ := containerTarget(method.Type)
+						target = g.resolvedTarget(method.Type, g.modelTarget(file, target))
+						interfaceInfo.Embeds = append(interfaceInfo.Embeds, target)
+					}
+					continue
+				}
+				if len(method.Names) > 0 {
+					methodName := method.Names[0].Name
+
+					// Methoden-Parameter und Rückgabewerte
+					if funcType, ok := method.Type.(*ast.FuncType); ok {
+						methodInfo := g.buildMethodInfo(methodName, funcType, method.Pos())
+						methodInfo.Doc = method.Doc.Text()
+						interfaceInfo.Methods = append(interfaceInfo.Methods, methodInfo)
+					}
+				}
+			}
+		}
+
+		g.interfaces[typeName] = interfaceInfo
+		return
+	}
+
+	// Sonstige benannte Typen wie type Celsius float64 und Aliase wie
+	// type Handler = http.HandlerFunc
+	target, multiple := containerTarget(typeSpec.Type)
+	target = g.resolvedTarget(typeSpec.Type, g.modelTarget(file, target))
+	namedInfo := &NamedTypeInfo{
+		Name:       typeName,
+		Package:    pkgName,
+		Pos:        g.position(typeSpec.Pos()),
+		Underlying: getTypeString(typeSpec.Type),
+		TypeParams: g.typeParamList(typeSpec.TypeParams, file),
+		Doc:        doc,
+		Alias:      typeSpec.Assign.IsValid(),
+		Target:     target,
+		Multiple:   multiple,
+		Methods:    g.pendingMethods[typeName],
+	}
+	delete(g.pendingMethods, typeName)
+	g.namedTypes[typeName] = namedInfo
+}
+
+func (g *UMLGenerator) processMethod(funcDecl *ast.FuncDecl, scope string, generated bool) {
+	// Receiver-Typ ermitteln
+	if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
+		return // Keine Receiver, also keine Methode
+	}
+
+	receiver := funcDecl.Recv.List[0]
+	typeName := receiverTypeName(receiver.Type)
+	if typeName == "" {
+		return
+	}
+	typeName = g.typeKey(scope, typeName)
+
+	// Methoden-Info erstellen
+	methodInfo := g.buildMethodInfo(funcDecl.Name.Name, funcDecl.Type, funcDecl.Pos())
+	methodInfo.TypeParams = receiverTypeParams(receiver.Type)
+	methodInfo.Pointer = isPointerReceiver(receiver.Type)
+	methodInfo.Doc = funcDecl.Doc.Text()
+	methodInfo.Generated = generated
+	if methodInfo.Pointer {
+		methodInfo.Marker = pointerReceiverMarkers[g.options.PointerReceivers]
+	}
+
+	// Methode zur entsprechenden Struct hinzufügen oder vormerken, bis die
+	// Struct geparst wird
+	if structInfo, ok := g.structs[typeName]; ok {
+		structInfo.Methods = append(structInfo.Methods, methodInfo)
+	} else if namedInfo, ok := g.namedTypes[typeName]; ok {
+		namedInfo.Methods = append(namedInfo.Methods, methodInfo)
+	} else {
+		g.pendingMethods[typeName] = append(g.pendingMethods[typeName], methodInfo)
+	}
+}
+
+// pointerReceiverMarkers bildet die Werte von --pointer-receivers auf die
+// Kennzeichnung von Methoden mit Pointer-Receiver ab
+var pointerReceiverMarkers = map[string]string{
+	"off":        "",
+	"prefix":     "*",
+	"stereotype": "<<pointer>> ",
+}
+
+// isPointerReceiver prüft, ob ein Receiver ein Pointer ist, auch bei
+// geklammerten oder generischen Receivern wie (*Cache[K, V])
+func isPointerReceiver(expr ast.Expr) bool {
+	for {
+		paren, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		expr = paren.X
+	}
+	_, ok := expr.(*ast.StarExpr)
+	return ok
+}
+
+// buildMethodInfo erstellt die Methoden-Info aus einer Funktionssignatur
+func (g *UMLGenerator) buildMethodInfo(methodName string, funcType *ast.FuncType, pos token.Pos) MethodInfo {
+	methodInfo := MethodInfo{Name: methodName, Pos: g.position(pos), Parameters: []ParameterInfo{}}
+
+	// Parameter
+	if funcType.Params != nil {
+		for _, param := range funcType.Params.List {
+			paramType := getTypeString(param.Type)
+
+			if len(param.Names) > 0 {
+				for _, name := range param.Names {
+					methodInfo.Parameters = append(methodInfo.Parameters, ParameterInfo{
+						Name: name.Name,
+						Type: paramType,
+					})
+				}
+			} else {
+				methodInfo.Parameters = append(methodInfo.Parameters, ParameterInfo{
+					Name: "",
+					Type: paramType,
+				})
+			}
+		}
+	}
+
+	// Rückgabewerte; (a, b int) ergibt zwei Einträge
+	if funcType.Results != nil {
+		for _, result := range funcType.Results.List {
+			resultType := getTypeString(result.Type)
+			if len(result.Names) == 0 {
+				methodInfo.Results = append(methodInfo.Results, ParameterInfo{Type: resultType})
+			}
+			for _, name := range result.Names {
+				methodInfo.Results = append(methodInfo.Results, ParameterInfo{Name: name.Name, Type: resultType})
+			}
+		}
+		methodInfo.ReturnType = formatResults(methodInfo.Results)
+	}
+
+	return methodInfo
+}
+
+// formatResults formatiert Rückgabewerte: einen unbenannten als Typ, sonst
+// als Tupel wie (int, error) oder mit Namen (n: int, err: error)
+func formatResults(results []ParameterInfo) string {
+	if len(results) == 1 && results[0].Name == "" {
+		return results[0].Type
+	}
+	var parts []string
+	for _, result := range results {
+		if result.Name != "" {
+			parts = append(parts, result.Name+": "+result.Type)
+		} else {
+			parts = append(parts, result.Type)
+		}
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// resultTypes liefert die Typen der Rückgabewerte. Methoden aus --overlay
+// kennen nur die Rückgabe als Text, z.B. "(*User, error)".
+func (m MethodInfo) resultTypes() []string {
+	if m.Results != nil {
+		var typeNames []string
+		for _, result := range m.Results {
+			typeNames = append(typeNames, result.Type)
+		}
+		return typeNames
+	}
+	if m.ReturnType == "" {
+		return nil
+	}
+	var typeNames []string
+	for _, result := range splitTopLevel(strings.TrimSuffix(strings.TrimPrefix(m.ReturnType, "("), ")")) {
+		if colon := strings.Index(result, ":"); colon >= 0 {
+			result = result[colon+1:]
+		}
+		typeNames = append(typeNames, strings.TrimSpace(result))
+	}
+	return typeNames
+}
+
+// receiverTypeName ermittelt den Typnamen eines Receivers. Unterstützt werden
+// Pointer- und Wert-Receiver sowie generische Receiver wie *Cache[K, V].
+func receiverTypeName(expr ast.Expr) string {
+	switch typeExpr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(typeExpr.X)
+	case *ast.ParenExpr:
+		return receiverTypeName(typeExpr.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(typeExpr.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(typeExpr.X)
+	case *ast.Ident:
+		return typeExpr.Name
+	default:
+		return ""
+	}
+}
+
+// constructedType liefert den Typ X, wenn die Funktion ein Konstruktor der
+// Form NewX oder New ist, deren erstes Ergebnis *X oder X ist
+func constructedType(funcDecl *ast.FuncDecl) string {
+	suffix, ok := strings.CutPrefix(funcDecl.Name.Name, "New")
+	if !ok || funcDecl.Type.Results == nil || len(funcDecl.Type.Results.List) == 0 {
+		return ""
+	}
+	typeName := receiverTypeName(funcDecl.Type.Results.List[0].Type)
+	if typeName == "" || (suffix != "" && suffix != typeName) {
+		return ""
+	}
+	return typeName
+}
+
+// attachConstructors hängt Konstruktoren an ihre Structs und benannten
+// Typen. Sie erscheinen dort statt in der «functions»-Klasse des Pakets.
+func (g *UMLGenerator) attachConstructors() {
+	for _, typeName := range sortedMapKeys(g.constructors) {
+		constructors := g.constructors[typeName]
+		pkg := ""
+		if structInfo, ok := g.structs[typeName]; ok {
+			structInfo.Constructors = constructors
+			pkg = structInfo.Package
+		} else if namedInfo, ok := g.namedTypes[typeName]; ok && !namedInfo.Alias {
+			namedInfo.Constructors = constructors
+			pkg = namedInfo.Package
+		} else {
+			continue
+		}
+
+		pkg = g.packageID(typeName, pkg)
+		var remaining []MethodInfo
+		for _, function := range g.functions[pkg] {
+			if !hasMethod(constructors, function.Name) {
+				remaining = append(remaining, function)
+			}
+		}
+		if len(remaining) > 0 {
+			g.functions[pkg] = remaining
+		} else {
+			delete(g.functions, pkg)
+		}
+	}
+}
+
+// attachAliasMethods hängt Methoden, deren Receiver ein Alias ist, an den
+// Zieltyp. In Go gehören sie zum Methodensatz des Zieltyps; je nach
+// Reihenfolge der Deklarationen wären sie sonst am Alias gelandet.
+func (g *UMLGenerator) attachAliasMethods() {
+	for _, name := range sortedMapKeys(g.namedTypes) {
+		alias := g.namedTypes[name]
+		if !alias.Alias || alias.Multiple || len(alias.Methods) == 0 {
+			continue
+		}
+		if structInfo, ok := g.structs[alias.Target]; ok {
+			structInfo.Methods = append(structInfo.Methods, alias.Methods...)
+		} else if namedInfo, ok := g.namedTypes[alias.Target]; ok && !namedInfo.Alias {
+			namedInfo.Methods = append(namedInfo.Methods, alias.Methods...)
+		} else {
+			continue
+		}
+		alias.Methods = nil
+	}
+}
+
+// receiverTypeParams liefert die Namen der Typparameter eines generischen
+// Receivers, z.B. K und V bei (m *Map[K, V])
+func receiverTypeParams(expr ast.Expr) []string {
+	var indices []ast.Expr
+	switch typeExpr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeParams(typeExpr.X)
+	case *ast.ParenExpr:
+		return receiverTypeParams(typeExpr.X)
+	case *ast.IndexExpr:
+		indices = []ast.Expr{typeExpr.Index}
+	case *ast.IndexListExpr:
+		indices = typeExpr.Indices
+	}
+	var names []string
+	for _, index := range indices {
+		if ident, ok := index.(*ast.Ident); ok {
+			names = append(names, ident.Name)
+		}
+	}
+	return names
+}
+
+// typeParamList liefert die Typparameter einer Typdeklaration samt den
+// Modellschlüsseln der benannten Typen in ihren Constraints
+func (g *UMLGenerator) typeParamList(fields *ast.FieldList, file *ast.File) []TypeParamInfo {
+	if fields == nil {
+		return nil
+	}
+	var params []TypeParamInfo
+	for _, field := range fields.List {
+		constraint := getTypeString(field.Type)
+		var targets []string
+		for _, target := range constraintNames(field.Type) {
+			targets = appendUnique(targets, g.modelTarget(file, target))
+		}
+		for _, name := range field.Names {
+			params = append(params, TypeParamInfo{Name: name.Name, Constraint: constraint, Targets: targets})
+		}
+	}
+	return params
+}
+
+// renameTypeParams ersetzt in Parametern und Rückgabetyp einer Methode die
+// Typparameter des Receivers durch die Namen aus der Typdeklaration, z.B. E
+// durch T bei func (s *Stack[E]) für type Stack[T any]
+func renameTypeParams(method MethodInfo, params []TypeParamInfo) MethodInfo {
+	if len(method.TypeParams) != len(params) {
+		return method
+	}
+	var replacements []string
+	for i, name := range method.TypeParams {
+		if name != params[i].Name {
+			replacements = append(replacements, name, params[i].Name)
+		}
+	}
+	if len(replacements) == 0 {
+		return method
+	}
+	rename := func(typeString string) string {
+		return identPattern.ReplaceAllStringFunc(typeString, func(ident string) string {
+			for i := 0; i < len(replacements); i += 2 {
+				if ident == replacements[i] {
+					return replacements[i+1]
+				}
+			}
+			return ident
+		})
+	}
+	renamed := method
+	renamed.Parameters = make([]ParameterInfo, len(method.Parameters))
+	for i, param := range method.Parameters {
+		renamed.Parameters[i] = ParameterInfo{Name: param.Name, Type: rename(param.Type)}
+	}
+	if method.Results != nil {
+		renamed.Results = make([]ParameterInfo, len(method.Results))
+		for i, result := range method.Results {
+			renamed.Results[i] = ParameterInfo{Name: result.Name, Type: rename(result.Type)}
+		}
+	}
+	renamed.ReturnType = rename(method.ReturnType)
+	return renamed
+}
+
+// identPattern findet Bezeichner in einer Typangabe
+var identPattern = regexp.MustCompile(`[\pL_][\pL\pN_]*`)
+
+// typeParamNames liefert nur die Namen einer Typparameterliste
+func typeParamNames(params []TypeParamInfo) []string {
+	var names []string
+	for _, param := range params {
+		names = append(names, param.Name)
+	}
+	return names
+}
+
+// formatTypeParams formatiert Typparameter für einen Klassenkopf, z.B.
+// "<K comparable, V>"; der Constraint any wird weggelassen
+func formatTypeParams(params []TypeParamInfo) string {
+	if len(params) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, param := range params {
+		if param.Constraint == "any" || param.Constraint == "interface{}" {
+			parts = append(parts, param.Name)
+		} else {
+			parts = append(parts, param.Name+" "+param.Constraint)
+		}
+	}
+	return "<" + strings.Join(parts, ", ") + ">"
+}
+
+func (g *UMLGenerator) identifyRelations() {
+	// Embedding und Komposition identifizieren
+	for _, structName := range sortedMapKeys(g.structs) {
+		g.identifyFieldRelations(structName, g.structs[structName])
+	}
+
+	// Benannte Typen auf Basis von Containern oder anderen Typen
+	for _, typeName := range sortedMapKeys(g.namedTypes) {
+		namedInfo := g.namedTypes[typeName]
+		if namedInfo.Target == typeName || !g.isClassType(namedInfo.Target) {
+			continue
+		}
+		if namedInfo.Alias && !namedInfo.Multiple {
+			g.relations = append(g.relations, Relation{
+				From: typeName,
+				To:   namedInfo.Target,
+				Type: "alias",
+				Pos:  namedInfo.Pos,
+			})
+			continue
+		}
+		cardinality := "1"
+		if namedInfo.Multiple {
+			cardinality = "*"
+		}
+		g.relations = append(g.relations, Relation{
+			From:        typeName,
+			To:          namedInfo.Target,
+			Type:        "aggregation",
+			Cardinality: cardinality,
+			Pos:         namedInfo.Pos,
+		})
+	}
+
+	// Eingebettete Interfaces als Generalisierung
+	for _, interfaceName := range sortedMapKeys(g.interfaces) {
+		interfaceInfo := g.interfaces[interfaceName]
+		for _, embedded := range interfaceInfo.Embeds {
+			if _, ok := g.interfaces[embedded]; !ok {
+				interfaceInfo.Foreign = append(interfaceInfo.Foreign, embedded)
+				continue
+			}
+			g.relations = append(g.relations, Relation{
+				From: interfaceName,
+				To:   embedded,
+				Type: "extends",
+				Pos:  interfaceInfo.Pos,
+			})
+		}
+	}
+
+	// Interfaces und Implementierungen prüfen
+	methodSets := make(map[string][]MethodInfo)
+	for structName, structInfo := range g.structs {
+		methodSets[structName] = structInfo.Methods
+	}
+	for typeName, namedInfo := range g.namedTypes {
+		methodSets[typeName] = namedInfo.Methods
+	}
+
+	for _, typeName := range sortedMapKeys(methodSets) {
+		methods := methodSets[typeName]
+		for _, interfaceName := range sortedMapKeys(g.interfaces) {
+			// Prüfe, ob der Typ alle Methoden des Interfaces einschließlich
+			// der eingebetteten mit gleichem Namen besitzt
+			required, complete := g.interfaceMethodSet(interfaceName)
+			namesMatch := true
+			var mismatched []string
+			for _, interfaceMethod := range required {
+				found := false
+				for _, method := range methods {
+					if method.Name == interfaceMethod.Name {
+						found = true
+						if !g.sameSignature(method, interfaceMethod) {
+							mismatched = append(mismatched, method.Name)
+						}
+						break
+					}
+				}
+				if !found {
+					namesMatch = false
+					break
+				}
+			}
+
+			// Implementiert nur bei übereinstimmenden Signaturen und bekanntem
+			// Methodensatz; mit Typinformationen entscheidet go/types, das
+			// auch eingebettete Methoden fremder Pakete berücksichtigt
+			implementsInterface := namesMatch && complete && len(mismatched) == 0 && len(required) > 0
+			if result, checked := g.typesImplements(typeName, interfaceName); checked {
+				implementsInterface = result
+			} else if !complete {
+				continue
+			}
+
+			if namesMatch && len(required) > 0 && !implementsInterface {
+				if len(mismatched) > 0 {
+					g.warn(WarningNameOnlyMatch, g.typePosition(typeName),
+						"%s implementiert %s nur dem Namen nach, abweichende Signatur: %s",
+						typeName, interfaceName, strings.Join(mismatched, ", "))
+				} else {
+					g.warn(WarningNameOnlyMatch, g.typePosition(typeName),
+						"%s implementiert %s nur dem Namen nach, laut Typprüfung passen die Signaturen nicht",
+						typeName, interfaceName)
+				}
+			}
+			if implementsInterface {
+				g.relations = append(g.relations, Relation{
+					From:        typeName,
+					To:          interfaceName,
+					Type:        "implements",
+					Cardinality: "",
+				})
+			}
+		}
+	}
+}
+
+// interfaceMethodSet liefert die Methoden eines Interfaces samt der aus
+// eingebetteten Interfaces geerbten. complete ist false, wenn ein
+// eingebettetes Interface nicht im Modell liegt (z.B. io.Reader).
+func (g *UMLGenerator) interfaceMethodSet(name string) (methods []MethodInfo, complete bool) {
+	return g.collectInterfaceMethods(name, make(map[string]bool))
+}
+
+// collectInterfaceMethods sammelt den Methodensatz rekursiv; seen verhindert
+// doppelte Methoden bei rautenförmiger Einbettung
+func (g *UMLGenerator) collectInterfaceMethods(name string, seen map[string]bool) ([]MethodInfo, bool) {
+	interfaceInfo, ok := g.interfaces[name]
+	if !ok {
+		return nil, false
+	}
+	if seen[name] {
+		return nil, true
+	}
+	seen[name] = true
+
+	methods := append([]MethodInfo{}, interfaceInfo.Methods...)
+	complete := true
+	for _, embedded := range interfaceInfo.Embeds {
+		inherited, ok := g.collectInterfaceMethods(embedded, seen)
+		complete = complete && ok
+		for _, method := range inherited {
+			if !hasMethod(methods, method.Name) {
+				methods = append(methods, method)
+			}
+		}
+	}
+	return methods, complete
+}
+
+// hasMethod prüft, ob eine Methode des Namens enthalten ist
+func hasMethod(methods []MethodInfo, name string) bool {
+	for _, method := range methods {
+		if method.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// expandInterfaces hängt jedem Interface die geerbten Methoden seiner
+// eingebetteten Interfaces an (--expand-interfaces)
+func (g *UMLGenerator) expandInterfaces() {
+	for _, name := range sortedMapKeys(g.interfaces) {
+		interfaceInfo := g.interfaces[name]
+		methods, _ := g.interfaceMethodSet(name)
+		interfaceInfo.Inherited = methods[len(interfaceInfo.Methods):]
+	}
+}
+
+// identifyFieldRelations leitet die Beziehungen einer Struct aus ihren
+// Feldern ab
+func (g *UMLGenerator) identifyFieldRelations(structName string, structInfo *StructInfo) {
+	for _, field := range structInfo.Fields {
+		if g.isUnresolvedType(field.Target, typeParamNames(structInfo.TypeParams)) {
+			g.warn(WarningUnresolvedType, field.Pos, "Typ %s des Feldes %s.%s ist unbekannt", field.Target, structName, field.Name)
+		}
+
+		// Channel-Felder werden zu Assoziationen mit Richtung
+		if field.ChanDir != "" && g.isKnownType(field.Target) {
+			g.relations = append(g.relations, Relation{
+				From:  structName,
+				To:    field.Target,
+				Type:  "association",
+				Label: channelLabel(field.ChanDir),
+				Pos:   field.Pos,
+			})
+			continue
+		}
+
+		// Map-Schlüssel mit bekanntem Typ als eigene Beziehung
+		if field.IsMap && g.isKnownType(field.MapKey) {
+			g.relations = append(g.relations, Relation{
+				From:        structName,
+				To:          field.MapKey,
+				Type:        "association",
+				Cardinality: "*",
+				Label:       "key",
+				Pos:         field.Pos,
+			})
+		}
+
+		// Prüfe, ob der (entpackte) Feldtyp eine bekannte Struct oder ein
+		// benannter Typ ist
+		if g.isClassType(field.Target) {
+			relationType := "aggregation"
+			cardinality := "1"
+			if field.Embedded {
+				relationType = "extends"
+			} else if field.Multiple {
+				// Container von Elementen: Aggregation mit Multiplizität
+				cardinality = "*"
+			} else if strings.HasPrefix(field.Type, "*") {
+				// Pointer könnte Komposition sein
+				relationType = "composition"
+			}
+
+			label := ""
+			if field.IsMap {
+				label = "value"
+			}
+
+			g.relations = append(g.relations, Relation{
+				From:        structName,
+				To:          field.Target,
+				Type:        relationType,
+				Cardinality: cardinality,
+				Label:       label,
+				Pos:         field.Pos,
+			})
+		}
+
+		// Prüfe, ob der Feldtyp ein Interface ist
+		if _, ok := g.interfaces[field.Target]; ok {
+			g.relations = append(g.relations, Relation{
+				From:        structName,
+				To:          field.Target,
+				Type:        "implements",
+				Cardinality: "",
+				Pos:         field.Pos,
+			})
+		}
+	}
+}
+
+// isClassType prüft, ob ein Name eine bekannte Struct oder ein benannter Typ ist
+func (g *UMLGenerator) isClassType(name string) bool {
+	if _, ok := g.structs[name]; ok {
+		return true
+	}
+	_, ok := g.namedTypes[name]
+	return ok
+}
+
+// getTypeString konvertiert einen AST-Typ in eine String-Repräsentation
+func getTypeString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + getTypeString(t.X)
+	case *ast.SelectorExpr:
+		return getTypeString(t.X) + "." + t.Sel.Name
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + getTypeString(t.Elt)
+		}
+		return "[" + getArrayLenString(t.Len) + "]" + getTypeString(t.Elt)
+	case *ast.MapType:
+		return "map[" + getTypeString(t.Key) + "]" + getTypeString(t.Value)
+	case *ast.InterfaceType:
+		return "interface{}"
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			return "chan<- " + getTypeString(t.Value)
+		case ast.RECV:
+			return "<-chan " + getTypeString(t.Value)
+		default:
+			return "chan " + getTypeString(t.Value)
+		}
+	case *ast.FuncType:
+		return funcTypeString(t)
+	case *ast.StructType:
+		return "struct"
+	case *ast.Ellipsis:
+		return "..." + getTypeString(t.Elt)
+	case *ast.ParenExpr:
+		return "(" + getTypeString(t.X) + ")"
+	case *ast.IndexExpr:
+		// Generischer Typ mit einem Typargument, z.B. List[T]
+		return getTypeString(t.X) + "[" + getTypeString(t.Index) + "]"
+	case *ast.IndexListExpr:
+		// Generischer Typ mit mehreren Typargumenten, z.B. Map[K, V]
+		var args []string
+		for _, index := range t.Indices {
+			args = append(args, getTypeString(index))
+		}
+		return getTypeString(t.X) + "[" + strings.Join(args, ", ") + "]"
+	default:
+		// Alle übrigen Ausdrücke so wiedergeben, wie sie im Quelltext stehen
+		return types.ExprString(expr)
+	}
+}
+
+// funcTypeString gibt einen Funktionstyp samt Signatur wieder, z.B.
+// func(ctx context.Context, n int) (string, error)
+func funcTypeString(t *ast.FuncType) string {
+	signature := "func(" + fieldListString(t.Params) + ")"
+	if t.Results == nil || len(t.Results.List) == 0 {
+		return signature
+	}
+	results := fieldListString(t.Results)
+	if len(t.Results.List) == 1 && len(t.Results.List[0].Names) == 0 {
+		return signature + " " + results
+	}
+	return signature + " (" + results + ")"
+}
+
+// fieldListString gibt Parameter oder Ergebnisse wie im Quelltext wieder,
+// benannte als "a, b int"
+func fieldListString(fields *ast.FieldList) string {
+	if fields == nil {
+		return ""
+	}
+	var parts []string
+	for _, field := range fields.List {
+		typeString := getTypeString(field.Type)
+		if len(field.Names) == 0 {
+			parts = append(parts, typeString)
+			continue
+		}
+		var names []string
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		parts = append(parts, strings.Join(names, ", ")+" "+typeString)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// getArrayLenString liefert die Länge eines Array-Typs wie im Quelltext
+func getArrayLenString(expr ast.Expr) string {
+	switch l := expr.(type) {
+	case *ast.BasicLit:
+		return l.Value
+	case *ast.Ident:
+		return l.Name
+	case *ast.SelectorExpr:
+		return getTypeString(l)
+	case *ast.Ellipsis:
+		return "..."
+	default:
+		return types.ExprString(expr)
+	}
+}
+
+// channelDirection liefert die Richtung eines Channel-Typs ("both", "send",
+// "recv") oder einen leeren String, wenn der Typ kein Channel ist
+func channelDirection(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.ParenExpr:
+		return channelDirection(t.X)
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			return "send"
+		case ast.RECV:
+			return "recv"
+		default:
+			return "both"
+		}
+	default:
+		return ""
+	}
+}
+
+// mapKeyTarget liefert den benannten Schlüsseltyp, wenn der Typ (ggf. hinter
+// einem Pointer) eine Map ist
+func mapKeyTarget(expr ast.Expr) (key string, isMap bool) {
+	switch t := expr.(type) {
+	case *ast.ParenExpr:
+		return mapKeyTarget(t.X)
+	case *ast.StarExpr:
+		return mapKeyTarget(t.X)
+	case *ast.MapType:
+		key, _ := containerTarget(t.Key)
+		return key, true
+	default:
+		return "", false
+	}
+}
+
+// channelLabel beschriftet eine Channel-Assoziation mit ihrer Richtung
+func channelLabel(dir string) string {
+	switch dir {
+	case "send":
+		return "<<chan>> send"
+	case "recv":
+		return "<<chan>> receive"
+	default:
+		return "<<chan>>"
+	}
+}
+
+// containerTarget entpackt Pointer, Slices, Arrays, Maps (Wert) und Channels
+// rekursiv bis zum benannten Elementtyp. multiple ist true, sobald dabei ein
+// Container durchlaufen wurde.
+func containerTarget(expr ast.Expr) (target string, multiple bool) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name, false
+	case *ast.SelectorExpr:
+		return getTypeString(t), false
+	case *ast.StarExpr:
+		return containerTarget(t.X)
+	case *ast.ParenExpr:
+		return containerTarget(t.X)
+	case *ast.IndexExpr:
+		return containerTarget(t.X)
+	case *ast.IndexListExpr:
+		return containerTarget(t.X)
+	case *ast.ArrayType:
+		target, _ := containerTarget(t.Elt)
+		return target, true
+	case *ast.MapType:
+		target, _ := containerTarget(t.Value)
+		return target, true
+	case *ast.ChanType:
+		target, _ := containerTarget(t.Value)
+		return target, true
+	default:
+		return "", false
+	}
+}
+
+// Findet rekursiv alle Go-Dateien in einem Verzeichnis
+// Wie das go-Tool werden Verzeichnisse, die mit . oder _ beginnen,
+// übersprungen. Liegt im Startverzeichnis eine go.mod, gelten
+// Unterverzeichnisse mit eigener go.mod als fremde Module und werden
+// ebenfalls übersprungen. Dateien, die laut Build-Bedingungen nicht zur
+// gewählten Konfiguration (--goos, --goarch, --tags) gehören, fehlen,
+// ebenso Testdateien, sofern weder --include-tests noch --test-map sie braucht.
+// Liegt im Startverzeichnis eine go.work, werden stattdessen alle Module aus
+// ihren use-Direktiven durchsucht.
+func findGoFiles(dirPath string, options Options) ([]string, error) {
+	files, _, err := scanGoFiles(dirPath, options)
+	return files, err
+}
+
+// scanGoFiles arbeitet wie findGoFiles und liefert zusätzlich die Dateien,
+// die wegen Build-Bedingungen oder als Testdateien ausgelassen wurden, samt
+// Grund
+func scanGoFiles(dirPath string, options Options) (files []string, skipped map[string]string, err error) {
+	skipped = make(map[string]string)
+	modules, err := workspaceModules(dirPath)
+	if err != nil {
+		return nil, skipped, err
+	}
+	if len(modules) == 0 {
+		files, err = walkGoFiles(dirPath, options, skipped)
+		return files, skipped, err
+	}
+	for _, module := range modules {
+		moduleFiles, err := walkGoFiles(module, options, skipped)
+		if err != nil {
+			return nil, skipped, err
+		}
+		files = append(files, moduleFiles...)
+	}
+	return files, skipped, nil
+}
+
+// walkGoFiles durchsucht ein Verzeichnis für scanGoFiles
+func walkGoFiles(dirPath string, options Options, skipped map[string]string) ([]string, error) {
+	var files []string
+	ignore := ignoreGlobs(options)
+	ctx := buildContext(options)
+
+	_, err := os.Stat(filepath.Join(dirPath, "go.mod"))
+	isModule := err == nil
+
+	err = filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && path != dirPath {
+			name := info.Name()
+			if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if isModule {
+				if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+					return filepath.SkipDir
+				}
+			}
+		}
+
+		// Editor-Temporärdateien und ignorierte Verzeichnisse überspringen
+		if path != dirPath && ignored(info.Name(), ignore) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".go") {
+			return nil
+		}
+		if isTestFile(path) && !options.IncludeTests && !options.TestMap {
+			skipped[path] = "Testdatei (--include-tests)"
+			return nil
+		}
+		if !matchesBuild(ctx, path) {
+			skipped[path] = "Build-Bedingungen treffen nicht zu (--goos, --goarch, --tags)"
+			return nil
+		}
+		files = append(files, path)
+
+		return nil
+	})
+
+	return files, err
+}
+
+// GenerateUMLFromDirectory parst alle Go-Dateien in einem Verzeichnis
+func (g *UMLGenerator) GenerateUMLFromDirectory(dirPath string) error {
+	g.Reset()
+
+	// Alle Go-Dateien im Verzeichnis finden
+	goFiles, skipped, err := scanGoFiles(dirPath, g.options)
+	g.skippedFiles = skipped
+	if err != nil {
+		return fmt.Errorf("Fehler beim Durchsuchen des Verzeichnisses: %v", err)
+	}
+
+	fmt.Printf("Gefundene Go-Dateien: %d\n", len(goFiles))
+
+	// Jede Go-Datei parsen, solange das Zeitbudget reicht
+	g.startBudget()
+	for i, filePath := range goFiles {
+		if g.overBudget() {
+			g.budgetFiles = len(goFiles) - i
+			break
+		}
+		fmt.Printf("Verarbeite: %s\n", filePath)
+		if err := g.parseFile(filePath); err != nil {
+			return err
+		}
+	}
+
+	// Modell einmalig aus allen Dateien aufbauen
+	return g.rebuild()
+}
+
+// UML-Diagramm als PlantUML generieren
+func (g *UMLGenerator) GeneratePlantUML() string {
+	var sb strings.Builder
+	g.WriteTo(&sb)
+	return sb.String()
+}
+
+// WriteTo schreibt das UML-Diagramm als PlantUML direkt in w, ohne den
+// gesamten Text im Speicher aufzubauen (implementiert io.WriterTo)
+func (g *UMLGenerator) WriteTo(w io.Writer) (int64, error) {
+	out := &plantUMLWriter{w: w}
+
+	out.WriteString("@startuml\n\n")
+	if g.hasCycles {
+		out.WriteString(fmt.Sprintf("skinparam classBackgroundColor<<%s>> #FFDDDD\n", cycleStereotype))
+		out.WriteString(fmt.Sprintf("skinparam classBorderColor<<%s>> red\n\n", cycleStereotype))
+	}
+	for _, stereotype := range sortedMapKeys(g.stereotypeColors) {
+		out.WriteString(fmt.Sprintf("skinparam classBackgroundColor<<%s>> %s\n", stereotype, g.stereotypeColors[stereotype]))
+	}
+	if len(g.stereotypeColors) > 0 {
+		out.WriteString("\n")
+	}
+	if len(g.elements) > 0 {
+		// Kontextelemente wie database oder queue neben Klassen erlauben
+		out.WriteString("allowmixing\n\n")
+	}
+
+	// Typen ohne Beziehungen je nach --orphans ausblenden oder gruppieren
+	orphans := make(map[string]bool)
+	if g.options.Orphans == "hide" || g.options.Orphans == "group" {
+		orphans = g.orphanTypes()
+	}
+	var groupedStructs []*StructInfo
+	var groupedInterfaces []*InterfaceInfo
+	var groupedNamedTypes []*NamedTypeInfo
+
+	// Bei mehreren Paketen erhält jedes einen eigenen package-Block, auch
+	// gleichnamige Pakete verschiedener Verzeichnisse. Qualifizierte Namen
+	// wie auth.User oder Importpfade sollen dabei nicht als weitere
+	// Namensräume gelesen werden.
+	packages, labels := g.modelPackages()
+	namespaced := len(packages) > 1
+	qualifiedLabels := false
+	for _, label := range labels {
+		qualifiedLabels = qualifiedLabels || strings.ContainsAny(label, "./")
+	}
+	if g.hasQualifiedKeys() || (namespaced && qualifiedLabels) {
+		out.WriteString("set namespaceSeparator none\n\n")
+	}
+
+	for _, pkg := range packages {
+		label := labels[pkg]
+		switch {
+		case !namespaced || label == "":
+		case strings.ContainsAny(label, "./"):
+			out.WriteString(fmt.Sprintf("package %q {\n\n", label))
+		default:
+			out.WriteString(fmt.Sprintf("package %s {\n\n", label))
+		}
+
+		// Structs darstellen
+		for _, name := range sortedMapKeys(g.structs) {
+			structInfo := g.structs[name]
+			if g.packageID(name, structInfo.Package) != pkg {
+				continue
+			}
+			if orphans[structInfo.Name] {
+				groupedStructs = append(groupedStructs, structInfo)
+				continue
+			}
+			writeStructPlantUML(out, structInfo)
+			g.writeDocNote(out, structInfo.Name, structInfo.Doc)
+		}
+
+		// Interfaces darstellen
+		for _, name := range sortedMapKeys(g.interfaces) {
+			interfaceInfo := g.interfaces[name]
+			if g.packageID(name, interfaceInfo.Package) != pkg {
+				continue
+			}
+			if orphans[interfaceInfo.Name] {
+				groupedInterfaces = append(groupedInterfaces, interfaceInfo)
+				continue
+			}
+			writeInterfacePlantUML(out, interfaceInfo)
+			g.writeDocNote(out, interfaceInfo.Name, interfaceInfo.Doc)
+		}
+
+		// Benannte Nicht-Struct-Typen darstellen
+		for _, name := range sortedMapKeys(g.namedTypes) {
+			namedInfo := g.namedTypes[name]
+			if g.packageID(name, namedInfo.Package) != pkg {
+				continue
+			}
+			if orphans[namedInfo.Name] {
+				groupedNamedTypes = append(groupedNamedTypes, namedInfo)
+				continue
+			}
+			writeNamedTypePlantUML(out, namedInfo)
+			g.writeDocNote(out, namedInfo.Name, namedInfo.Doc)
+		}
+
+		// Factory-Funktionen darstellen
+		for _, name := range sortedMapKeys(g.factories) {
+			if factory := g.factories[name]; g.packageID(name, factory.Package) == pkg {
+				out.WriteString(fmt.Sprintf("class \"%s()\" as %s <<factory>>\n\n", factory.Name, name))
+			}
+		}
+
+		// Übrige Funktionen auf Paketebene als Hilfsklasse
+		if functions := g.functions[pkg]; len(functions) > 0 {
+			g.writeFunctionsPlantUML(out, pkg, label, functions)
+		}
+
+		if namespaced && label != "" {
+			out.WriteString("}\n\n")
+		}
+	}
+
+	// Kontextelemente aus dem Overlay
+	for _, name := range sortedMapKeys(g.elements) {
+		writeElementPlantUML(out, g.elements[name])
+	}
+
+	// Unverbundene Typen in einem eigenen Paket sammeln
+	if g.options.Orphans == "group" && len(orphans) > 0 {
+		out.WriteString("package \"unverbunden\" {\n\n")
+		for _, structInfo := range groupedStructs {
+			writeStructPlantUML(out, structInfo)
+			g.writeDocNote(out, structInfo.Name, structInfo.Doc)
+		}
+		for _, interfaceInfo := range groupedInterfaces {
+			writeInterfacePlantUML(out, interfaceInfo)
+			g.writeDocNote(out, interfaceInfo.Name, interfaceInfo.Doc)
+		}
+		for _, namedInfo := range groupedNamedTypes {
+			writeNamedTypePlantUML(out, namedInfo)
+			g.writeDocNote(out, namedInfo.Name, namedInfo.Doc)
+		}
+		out.WriteString("}\n\n")
+	}
+
+	// Beziehungen darstellen
+	for _, relation := range g.relations {
+		writeRelationPlantUML(out, relation)
+	}
+
+	var footer []string
+	if g.options.StatsFooter {
+		footer = append(footer, g.Statistics().String())
+	}
+	if g.stamp != "" {
+		footer = append(footer, g.stamp)
+	}
+	if note := g.budgetNote(); note != "" {
+		footer = append(footer, note)
+	}
+	if len(footer) > 0 {
+		out.WriteString(fmt.Sprintf("\nfooter %s\n", strings.Join(footer, "\\n")))
+	}
+
+	out.WriteString("\n@enduml")
+	return out.n, out.err
+}
+
+// writeRelationPlantUML schreibt eine Beziehung als PlantUML-Kante. Kanten
+// mit mehreren beitragenden Feldern oder Aufrufen werden dicker gezeichnet und
+// mit ihrem Gewicht beschriftet, Kanten in Zyklen rot.
+func writeRelationPlantUML(out *plantUMLWriter, relation Relation) {
+	weight := relation.weight()
+	switch relation.Type {
+	case "extends":
+		out.WriteString(fmt.Sprintf("%s %s %s\n", relation.To, relationArrow(relation, "<|--"), relation.From))
+	case "implements":
+		out.WriteString(fmt.Sprintf("%s %s %s\n", relation.To, relationArrow(relation, "<|.."), relation.From))
+	case "aggregation":
+		out.WriteString(fmt.Sprintf("%s %s%s %s%s\n", relation.From, relationArrow(relation, "o--"), formatCardinality(relation), relation.To, formatLabel(relation)))
+	case "composition":
+		out.WriteString(fmt.Sprintf("%s %s%s %s%s\n", relation.From, relationArrow(relation, "*--"), formatCardinality(relation), relation.To, formatLabel(relation)))
+	case "association":
+		out.WriteString(fmt.Sprintf("%s %s%s %s%s\n", relation.From, relationArrow(relation, "-->"), formatCardinality(relation), relation.To, formatLabel(relation)))
+	case "uses":
+		label := relation.Label
+		if label == "" {
+			label = "uses"
+		}
+		out.WriteString(fmt.Sprintf("%s %s %s : %s%s\n", relation.From, relationArrow(relation, "..>"), relation.To, label, formatWeight(weight)))
+	case "spawns":
+		out.WriteString(fmt.Sprintf("%s %s %s : <<spawns>> %s%s\n", relation.From, relationArrow(relation, "..>"), relation.To, relation.Label, formatWeight(weight)))
+	case "constrains":
+		out.WriteString(fmt.Sprintf("%s %s %s : <<constrains>> %s\n", relation.From, relationArrow(relation, "..>"), relation.To, relation.Label))
+	case "casts", "creates", "exposes", "builds", "alias":
+		out.WriteString(fmt.Sprintf("%s %s %s : <<%s>>%s\n", relation.From, relationArrow(relation, "..>"), relation.To, relation.Type, formatWeight(weight)))
+	}
+}
+
+// relationArrow gestaltet eine Kante: ab Gewicht 2 dicker, in Zyklen rot,
+// z.B. o-[#red,thickness=3]-
+func relationArrow(relation Relation, arrow string) string {
+	var styles []string
+	if relation.Cycle {
+		styles = append(styles, "#red")
+	}
+	if weight := relation.weight(); weight >= 2 {
+		styles = append(styles, fmt.Sprintf("thickness=%d", min(weight, maxEdgeThickness)))
+	}
+	if len(styles) == 0 {
+		return arrow
+	}
+	i := strings.IndexAny(arrow, "-.")
+	return arrow[:i+1] + "[" + strings.Join(styles, ",") + "]" + arrow[i+1:]
+}
+
+// maxEdgeThickness begrenzt die Linienstärke gewichteter Kanten
+const maxEdgeThickness = 5
+
+// formatWeight liefert die Gewichtsangabe für eine Kantenbeschriftung
+func formatWeight(weight int) string {
+	if weight < 2 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d×)", weight)
+}
+
+// plantUMLWriter schreibt in einen io.Writer, zählt die geschriebenen Bytes
+// und merkt sich den ersten Fehler, nach dem alle weiteren Schreibvorgänge
+// übersprungen werden
+type plantUMLWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+// WriteString schreibt s, sofern noch kein Fehler aufgetreten ist
+func (p *plantUMLWriter) WriteString(s string) {
+	if p.err != nil {
+		return
+	}
+	n, err := io.WriteString(p.w, s)
+	p.n += int64(n)
+	p.err = err
+}
+
+// formatCardinality liefert die Multiplizität am Zielende einer Beziehung,
+// sofern sie von der Standardmultiplizität 1 abweicht
+func formatCardinality(relation Relation) string {
+	if relation.Cardinality == "" || relation.Cardinality == "1" {
+		return ""
+	}
+	return fmt.Sprintf(" \"%s\"", relation.Cardinality)
+}
+
+// formatLabel liefert die Beschriftung einer Kante, sofern vorhanden
+func formatLabel(relation Relation) string {
+	if relation.Label == "" {
+		if relation.weight() > 1 {
+			return fmt.Sprintf(" : %d×", relation.weight())
+		}
+		return ""
+	}
+	return " : " + relation.Label + formatWeight(relation.weight())
+}
+
+// writeStructPlantUML schreibt eine Struct als PlantUML-Klasse
+func writeStructPlantUML(out *plantUMLWriter, structInfo *StructInfo) {
+	out.WriteString(fmt.Sprintf("class %s%s%s%s {\n", structInfo.Name, formatTypeParams(structInfo.TypeParams), formatStereotypes(structInfo.Stereotypes), formatHotness(structInfo.CPUShare)))
+
+	// Felder
+	for _, field := range structInfo.Fields {
+		// Anonyme Felder (Embedding) nicht anzeigen
+		if !field.Embedded {
+			out.WriteString(formatFieldPlantUML(field))
+			writeInlineFields(out, field.Name, field.Inline)
+		}
+	}
+
+	// Über Einbettung erreichbare Felder, je eingebetteter Struct ein Abschnitt
+	for _, promoted := range structInfo.Promoted {
+		out.WriteString(fmt.Sprintf("    .. %s ..\n", promoted.From))
+		for _, field := range promoted.Fields {
+			out.WriteString(formatFieldPlantUML(field))
+		}
+	}
+	if len(structInfo.Promoted) > 0 && len(structInfo.Constructors)+len(structInfo.Methods) > 0 {
+		out.WriteString("    --\n")
+	}
+
+	// Konstruktoren vor den Methoden
+	for _, constructor := range structInfo.Constructors {
+		out.WriteString(formatStaticPlantUML(constructor, "create"))
+	}
+
+	// Methoden, mit --group-methods je implementiertem Interface ein Abschnitt
+	writeMethodsPlantUML(out, structInfo.Methods, structInfo.MethodGroups, structInfo.TypeParams)
+
+	// Funktionale Optionen in eigenem Abschnitt
+	if len(structInfo.OptionFuncs) > 0 {
+		out.WriteString("    .. <<options>> ..\n")
+		for _, option := range structInfo.OptionFuncs {
+			out.WriteString(formatMethodPlantUML(option))
+		}
+	}
+
+	out.WriteString("}\n\n")
+
+	// Notizen
+	if len(structInfo.Notes) > 0 {
+		out.WriteString(fmt.Sprintf("note right of %s\n", structInfo.Name))
+		for _, note := range structInfo.Notes {
+			out.WriteString(note + "\n")
+		}
+		out.WriteString("end note\n\n")
+	}
+}
+
+// writeFunctionsPlantUML schreibt die Funktionen eines Pakets als
+// Hilfsklasse mit statischen Mitgliedern. Als Factory dargestellte
+// Funktionen erscheinen nicht doppelt.
+func (g *UMLGenerator) writeFunctionsPlantUML(out *plantUMLWriter, pkg, label string, functions []MethodInfo) {
+	factories := make(map[string]bool)
+	for key, factory := range g.factories {
+		if g.packageID(key, factory.Package) == pkg {
+			factories[factory.Name] = true
+		}
+	}
+
+	alias := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, label)
+	out.WriteString(fmt.Sprintf("class \"%s\" as %s_functions <<functions>> {\n", label, alias))
+	for _, function := range functions {
+		if factories[function.Name] {
+			continue
+		}
+		out.WriteString(formatStaticPlantUML(function, ""))
+	}
+	out.WriteString("}\n\n")
+}
+
+// writeDocNote schreibt den ersten Satz eines Doc-Kommentars als Notiz über
+// den Typ (--doc-notes)
+func (g *UMLGenerator) writeDocNote(out *plantUMLWriter, name, doc string) {
+	if !g.options.DocNotes || doc == "" {
+		return
+	}
+	out.WriteString(fmt.Sprintf("note top of %s\n%s\nend note\n\n", name, docSummary(doc)))
+}
+
+// docSummary liefert den ersten Satz eines Doc-Kommentars in einer Zeile
+func docSummary(doc string) string {
+	doc = strings.Join(strings.Fields(doc), " ")
+	if end := strings.Index(doc, ". "); end >= 0 {
+		doc = doc[:end+1]
+	}
+	return doc
+}
+
+// formatStereotypes formatiert Stereotypen für einen Klassenkopf
+func formatStereotypes(stereotypes []string) string {
+	var sb strings.Builder
+	for _, stereotype := range stereotypes {
+		sb.WriteString(fmt.Sprintf(" <<%s>>", stereotype))
+	}
+	return sb.String()
+}
+
+// writeInterfacePlantUML schreibt ein Interface als PlantUML-Interface
+func writeInterfacePlantUML(out *plantUMLWriter, interfaceInfo *InterfaceInfo) {
+	out.WriteString(fmt.Sprintf("interface %s%s%s {\n", interfaceInfo.Name, formatTypeParams(interfaceInfo.TypeParams), formatStereotypes(interfaceInfo.Stereotypes)))
+
+	// Interface-Methoden
+	for _, method := range interfaceInfo.Methods {
+		out.WriteString(formatMethodPlantUML(method))
+	}
+
+	// Typmengen, eingebettete Interfaces außerhalb des Modells und geerbte Methoden
+	for _, term := range interfaceInfo.TypeSet {
+		out.WriteString(fmt.Sprintf("    <<typeset>> %s\n", term))
+	}
+	for _, embedded := range interfaceInfo.Foreign {
+		out.WriteString(fmt.Sprintf("    <<embed>> %s\n", embedded))
+	}
+	if len(interfaceInfo.Inherited) > 0 {
+		out.WriteString("    .. geerbt ..\n")
+		for _, method := range interfaceInfo.Inherited {
+			out.WriteString(formatMethodPlantUML(method))
+		}
+	}
+
+	out.WriteString("}\n\n")
+}
+
+// writeNamedTypePlantUML schreibt einen benannten Nicht-Struct-Typ als
+// Klasse mit Stereotyp «type» und dem zugrundeliegenden Typ, einen Alias mit
+// Stereotyp «alias» und dem Zieltyp
+func writeNamedTypePlantUML(out *plantUMLWriter, namedInfo *NamedTypeInfo) {
+	if len(namedInfo.EnumValues) > 0 {
+		writeEnumPlantUML(out, namedInfo)
+		return
+	}
+	stereotype, underlying := "type", namedInfo.Underlying
+	if namedInfo.Alias {
+		stereotype, underlying = "alias", "= "+namedInfo.Underlying
+	}
+	out.WriteString(fmt.Sprintf("class %s%s <<%s>>%s%s {\n", namedInfo.Name, formatTypeParams(namedInfo.TypeParams), stereotype, formatStereotypes(namedInfo.Stereotypes), formatHotness(namedInfo.CPUShare)))
+	out.WriteString(fmt.Sprintf("    %s\n", underlying))
+
+	for _, constructor := range namedInfo.Constructors {
+		out.WriteString(formatStaticPlantUML(constructor, "create"))
+	}
+	writeMethodsPlantUML(out, namedInfo.Methods, namedInfo.MethodGroups, namedInfo.TypeParams)
+
+	out.WriteString("}\n\n")
+}
+
+// writeEnumPlantUML schreibt einen Typ mit Konstanten als PlantUML-Enum,
+// eigene Methoden folgen nach einer Trennlinie
+func writeEnumPlantUML(out *plantUMLWriter, namedInfo *NamedTypeInfo) {
+	out.WriteString(fmt.Sprintf("enum %s%s%s {\n", namedInfo.Name, formatStereotypes(namedInfo.Stereotypes), formatHotness(namedInfo.CPUShare)))
+	for _, value := range namedInfo.EnumValues {
+		out.WriteString(fmt.Sprintf("    %s\n", value))
+	}
+	if len(namedInfo.Methods) > 0 || len(namedInfo.Constructors) > 0 {
+		out.WriteString("    --\n")
+		for _, constructor := range namedInfo.Constructors {
+			out.WriteString(formatStaticPlantUML(constructor, "create"))
+		}
+		writeMethodsPlantUML(out, namedInfo.Methods, namedInfo.MethodGroups, nil)
+	}
+	out.WriteString("}\n\n")
+}
+
+// formatStaticPlantUML formatiert eine Funktion als statisches Mitglied,
+// optional mit Stereotyp, z.B. {static} +<<create>> NewRepo(db: *DB): *Repo
+func formatStaticPlantUML(method MethodInfo, stereotype string) string {
+	prefix := "{static} +"
+	if stereotype != "" {
+		prefix += "<<" + stereotype + ">> "
+	}
+	return strings.Replace(formatMethodPlantUML(method), "+", prefix, 1)
+}
+
+// formatMethodPlantUML formatiert eine Methode als Zeile eines Klassenkörpers
+func formatMethodPlantUML(method MethodInfo) string {
+	var params []string
+	for _, param := range method.Parameters {
+		if param.Name != "" {
+			params = append(params, fmt.Sprintf("%s: %s", param.Name, param.Type))
+		} else {
+			params = append(params, param.Type)
+		}
+	}
+
+	if method.ReturnType != "" {
+		return fmt.Sprintf("    +%s%s(%s): %s\n", method.Marker, method.Name, strings.Join(params, ", "), method.ReturnType)
+	}
+	return fmt.Sprintf("    +%s%s(%s)\n", method.Marker, method.Name, strings.Join(params, ", "))
+}
+
+// orphanTypes liefert alle Typen, die an keiner Beziehung beteiligt sind
+func (g *UMLGenerator) orphanTypes() map[string]bool {
+	connected := make(map[string]bool)
+	for _, relation := range g.relations {
+		connected[relation.From] = true
+		connected[relation.To] = true
+	}
+
+	orphans := make(map[string]bool)
+	for name := range g.structs {
+		if !connected[name] {
+			orphans[name] = true
+		}
+	}
+	for name := range g.interfaces {
+		if !connected[name] {
+			orphans[name] = true
+		}
+	}
+	for name := range g.namedTypes {
+		if !connected[name] {
+			orphans[name] = true
+		}
+	}
+	return orphans
+}
+
+// Generiere UML-Diagramm als PNG
+// Generiere UML-Diagramm als PNG mit HTTP POST-Anfrage
+// Generiere UML-Diagramm mit lokaler PlantUML.jar
+func (g *UMLGenerator) GenerateUMLDiagram(outputDir, fileName string) error {
+	source, err := g.plantUMLSource()
+	if err != nil {
+		return err
+	}
+
+	if err := g.writeDiagramFrom(outputDir, fileName, source); err != nil {
+		return err
+	}
+	if g.options.AltText {
+		return g.writeAltText(outputDir, fileName)
+	}
+	return nil
+}
+
+// writeDiagram speichert PlantUML-Text als .puml-Datei und erzeugt daraus ein PNG
+func (g *UMLGenerator) writeDiagram(outputDir, fileName, plantUML string) error {
+	return g.writeDiagramFrom(outputDir, fileName, strings.NewReader(plantUML))
+}
+
+// writeDiagramFrom streamt PlantUML aus source in eine .puml-Datei und
+// erzeugt daraus ein PNG. Beide Dateien werden fürs Manifest erfasst.
+func (g *UMLGenerator) writeDiagramFrom(outputDir, fileName string, source io.WriterTo) error {
+	// Stellen Sie sicher, dass das Ausgabeverzeichnis existiert
+	if err := os.MkdirAll(outputDir, g.dirMode()); err != nil {
+		return fmt.Errorf("Fehler beim Erstellen des Ausgabeverzeichnisses: %v", err)
+	}
+
+	// PlantUML-Datei speichern
+	plantUMLFilePath := filepath.Join(outputDir, fileName+".puml")
+	if err := writeFileFrom(plantUMLFilePath, source, g.fileMode()); err != nil {
+		return fmt.Errorf("Fehler beim Speichern der PlantUML-Datei: %v", err)
+	}
+
+	fmt.Printf("PlantUML-Datei erstellt: %s\n", plantUMLFilePath)
+	if err := g.recordArtifact(outputDir, plantUMLFilePath, "puml", ""); err != nil {
+		return err
+	}
+
+	// PNG über die Renderer-Kette erzeugen
+	return g.renderPNG(outputDir, fileName, plantUMLFilePath)
+}
+
+// writeFileFrom schreibt den Inhalt von source gepuffert in eine Datei mit den
+// Rechten mode. Der Inhalt landet zunächst in einer temporären Datei im selben
+// Verzeichnis und wird dann umbenannt, damit Leser nie eine halb geschriebene
+// Datei sehen.
+func writeFileFrom(path string, source io.WriterTo, mode os.FileMode) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := file.Name()
+
+	buf := bufio.NewWriter(file)
+	if _, err := source.WriteTo(buf); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	// CreateTemp legt Dateien mit 0600 an
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return replaceFile(tmpPath, path)
+}
+
+// fileMode liefert die Rechte für Ausgabedateien
+func (g *UMLGenerator) fileMode() os.FileMode {
+	if g.options.FileMode == 0 {
+		return 0644
+	}
+	return g.options.FileMode
+}
+
+// dirMode liefert die Rechte für neu angelegte Ausgabeverzeichnisse
+func (g *UMLGenerator) dirMode() os.FileMode {
+	if g.options.DirMode == 0 {
+		return 0755
+	}
+	return g.options.DirMode
+}
+
+// parseFileMode parst Dateirechte in Oktalschreibweise, z.B. "0600"
+func parseFileMode(value string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode == 0 || mode > 0777 {
+		return 0, fmt.Errorf("ungültige Rechte %q (erwartet oktal, z.B. 0644)", value)
+	}
+	return os.FileMode(mode), nil
+}
+
+// replaceFile ersetzt path atomar durch tmpPath
+func replaceFile(tmpPath, path string) error {
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// Neue FileWatcher-Implementierung für Verzeichnisse
+func NewFileWatcher(dirPath string, outputDir string, options Options) *FileWatcher {
+	return &FileWatcher{
+		dirPath:      dirPath,
+		lastModified: make(map[string]time.Time),
+		outputDir:    outputDir,
+		options:      options,
+	}
+}
+
+// regenerate parst das Verzeichnis neu und erstellt das UML-Diagramm
+func (w *FileWatcher) regenerate() error {
+	previous := w.generator
+	w.generator = NewUMLGenerator(w.options)
+	if previous != nil {
+		// Renderer samt laufender JVM weiterverwenden
+		w.generator.renderers = previous.renderers
+	}
+	if err := w.generator.GenerateUMLFromDirectory(w.dirPath); err != nil {
+		return fmt.Errorf("Fehler beim Generieren des UML-Diagramms: %v", err)
+	}
+
+	return w.render()
+}
+
+// update parst nur die geänderten Dateien neu, entfernt gelöschte Dateien
+// aus dem Modell und erstellt das UML-Diagramm
+func (w *FileWatcher) update(changed, removed []string) error {
+	if w.generator == nil {
+		return w.regenerate()
+	}
+
+	// Nicht lesbare Dateien verhindern den Neuaufbau nicht; das Diagramm
+	// zeigt dann den Stand ohne ihre Änderungen
+	err := w.generator.UpdateFiles(changed, removed)
+	var pathErr *fs.PathError
+	if err != nil && !errors.As(err, &pathErr) {
+		return fmt.Errorf("Fehler beim Generieren des UML-Diagramms: %v", err)
+	}
+	if renderErr := w.render(); renderErr != nil {
+		return renderErr
+	}
+	return err
+}
+
+// render erstellt die Diagramme aus dem aktuellen Modell
+func (w *FileWatcher) render() error {
+	g := w.generator
+	g.artifacts = nil
+	defer g.closeRenderQueue()
+	if w.options.StampCommit {
+		g.stamp = provenanceStamp(w.dirPath)
+	}
+
+	// Cluster-Diagramme zuerst, damit sie auch bei zu großem Gesamtdiagramm entstehen
+	if w.options.Cluster {
+		if err := g.GenerateClusterDiagrams(w.outputDir); err != nil {
+			return fmt.Errorf("Fehler beim Erstellen der Cluster-Diagramme: %v", err)
+		}
+	}
+
+	if w.options.ContextKeys {
+		if err := g.GenerateContextKeyOutputs(w.outputDir); err != nil {
+			return fmt.Errorf("Fehler beim Erstellen des Context-Berichts: %v", err)
+		}
+	}
+
+	if w.options.DocLinks != "" {
+		if err := g.GeneratePackageDocs(w.outputDir, w.options.DocLinks); err != nil {
+			return fmt.Errorf("Fehler beim Erstellen der Paketdiagramme: %v", err)
+		}
+	}
+
+	if w.options.InitOrder {
+		if err := g.writeDiagram(w.outputDir, "uml_init_order", g.GenerateInitOrderPlantUML(g.PackageInits())); err != nil {
+			return fmt.Errorf("Fehler beim Erstellen des Initialisierungsdiagramms: %v", err)
+		}
+	}
+
+	if w.options.Ports {
+		if err := g.writeDiagram(w.outputDir, "uml_ports", g.GeneratePortsPlantUML(g.InterfacePorts())); err != nil {
+			return fmt.Errorf("Fehler beim Erstellen der Interface-Sicht: %v", err)
+		}
+	}
+
+	if w.options.TestMap {
+		if err := g.GenerateTestMap(w.outputDir); err != nil {
+			return fmt.Errorf("Fehler beim Erstellen des Testdiagramms: %v", err)
+		}
+	}
+
+	if w.options.Workspace == "modules" {
+		if err := g.GenerateModuleDiagrams(w.outputDir); err != nil {
+			return fmt.Errorf("Fehler beim Erstellen der Moduldiagramme: %v", err)
+		}
+	}
+
+	if w.options.Compare != "" {
+		left, right, _ := strings.Cut(w.options.Compare, ",")
+		comparison := g.ComparePackages(left, right)
+		if len(comparison.Interfaces) == 0 {
+			fmt.Printf("Hinweis: %s und %s implementieren keine gemeinsamen Interfaces\n", left, right)
+		}
+		if err := g.writeDiagram(w.outputDir, "uml_compare", g.GenerateComparePlantUML(comparison)); err != nil {
+			return fmt.Errorf("Fehler beim Erstellen des Paketvergleichs: %v", err)
+		}
+	}
+
+	if w.options.Cards {
+		if err := g.GenerateCards(w.outputDir); err != nil {
+			return fmt.Errorf("Fehler beim Erstellen der Karten: %v", err)
+		}
+	}
+
+	if err := g.GenerateUMLDiagram(w.outputDir, "uml_diagram"); err != nil {
+		return fmt.Errorf("Fehler beim Erstellen des UML-Diagramms: %v", err)
+	}
+
+	if w.options.Emit == "json" {
+		if err := g.WriteModel(w.outputDir); err != nil {
+			return err
+		}
+	}
+
+	if w.options.Badges {
+		if err := g.GenerateBadges(w.outputDir); err != nil {
+			return err
+		}
+	}
+
+	if w.options.MetricsPlot {
+		if err := g.GenerateMainSequencePlot(w.outputDir); err != nil {
+			return err
+		}
+	}
+
+	if err := g.WriteManifest(w.outputDir); err != nil {
+		return err
+	}
+
+	if w.options.Upload != "" {
+		if err := g.UploadArtifacts(w.outputDir, w.options.Upload); err != nil {
+			return err
+		}
+	}
+
+	if w.options.Site != "" {
+		if err := g.GenerateSite(w.outputDir, w.options.Site, w.options.SiteDir); err != nil {
+			return fmt.Errorf("Fehler beim Erstellen der Dokumentationsseite: %v", err)
+		}
+	}
+
+	if w.options.KeepHistory > 0 {
+		if err := g.saveHistory(w.outputDir, w.dirPath, w.options.KeepHistory, w.options.HistoryImages); err != nil {
+			return err
+		}
+	}
+
+	printStatistics(g.Statistics(), w.options.Stats)
+	if w.options.Cycles && !g.skippedForBudget("Zyklensuche") {
+		printCycles(g.TypeCycles())
+	}
+	printPackageMetrics(g.PackageMetrics(), w.options.Metrics)
+	if w.options.ContextAudit != "off" && !g.skippedForBudget("Context-Prüfung") {
+		printContextAudit(g.ContextAudit())
+	}
+
+	printWarnings(g.warnings)
+	if w.options.Strict && len(g.warnings) > 0 {
+		return fmt.Errorf("%d Warnungen (--strict)", len(g.warnings))
+	}
+	return nil
+}
+
+// packagePatternDir wandelt ein Paketmuster wie ./... oder ./pkg/... in das
+// zu durchsuchende Verzeichnis um
+func packagePatternDir(pattern string) string {
+	if pattern == "..." {
+		return "."
+	}
+	if dir, ok := strings.CutSuffix(pattern, "/..."); ok {
+		if dir == "" {
+			return "/"
+		}
+		return dir
+	}
+	return pattern
+}
+
+// recordModTimes merkt sich die aktuellen Änderungszeiten der Dateien
+func (w *FileWatcher) recordModTimes(goFiles []string) {
+	for _, filePath := range goFiles {
+		fileInfo, err := os.Stat(filePath)
+		if err == nil {
+			w.lastModified[filePath] = fileInfo.ModTime()
+		}
+	}
+}
+
+// Watch überwacht das Verzeichnis und erzeugt das Diagramm bei Änderungen
+// neu, bis ctx beendet wird
+func (w *FileWatcher) Watch(ctx context.Context) {
+	// Initialisierung der letzten Änderungszeiten
+	goFiles, err := findGoFiles(w.dirPath, w.options)
+	if err != nil {
+		fmt.Printf("Fehler beim Durchsuchen des Verzeichnisses: %v\n", err)
+		return
+	}
+
+	w.recordModTimes(goFiles)
+
+	// Branch-Wechsel und Rebases über .git/HEAD erkennen
+	w.gitDir = gitDir(w.dirPath)
+	if w.gitDir != "" {
+		w.headState = headState(w.gitDir)
+	}
+
+	// UML-Diagramm initial erstellen
+	if err := w.regenerate(); err != nil {
+		fmt.Println(err)
+	}
+
+	// Dateiänderungen überwachen
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(2 * time.Second):
+		}
+
+		goFiles, err := findGoFiles(w.dirPath, w.options)
+		if err != nil {
+			fmt.Printf("Fehler beim Durchsuchen des Verzeichnisses: %v\n", err)
+			continue
+		}
+
+		// Nach einem Checkout ändern sich viele Dateien auf einmal: komplett
+		// neu parsen statt inkrementell zu aktualisieren
+		if w.gitDir != "" {
+			if state := headState(w.gitDir); state != w.headState {
+				w.headState = state
+				fmt.Println("Git-HEAD geändert, UML-Diagramm wird vollständig neu erstellt...")
+				w.lastModified = make(map[string]time.Time)
+				w.recordModTimes(goFiles)
+				if err := w.regenerate(); err != nil {
+					fmt.Println(err)
+				}
+				continue
+			}
+		}
+
+		var changed, removed []string
+
+		// Prüfen, ob sich Dateien geändert haben oder neue hinzugekommen sind
+		for _, filePath := range goFiles {
+			fileInfo, err := os.Stat(filePath)
+			if err != nil {
+				continue
+			}
+
+			lastMod, exists := w.lastModified[filePath]
+			if !exists || fileInfo.ModTime().After(lastMod) {
+				w.lastModified[filePath] = fileInfo.ModTime()
+				changed = append(changed, filePath)
+			}
+		}
+
+		// Prüfen, ob Dateien gelöscht wurden
+		for filePath := range w.lastModified {
+			exists := false
+			for _, goFile := range goFiles {
+				if goFile == filePath {
+					exists = true
+					break
+				}
+			}
+
+			if !exists {
+				delete(w.lastModified, filePath)
+				removed = append(removed, filePath)
+			}
+		}
+
+		// Bei Änderungen UML-Diagramm neu generieren
+		if len(changed) > 0 || len(removed) > 0 {
+			fmt.Println("Änderungen erkannt, UML-Diagramm wird aktualisiert...")
+
+			if err := w.update(changed, removed); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+}
+
+// Main führt die Kommandozeile von go-uml-generator mit os.Args aus
+func Main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "doctor":
+			os.Exit(runDoctor(os.Args[2:]))
+		case "history-gif":
+			os.Exit(runHistoryGIF(os.Args[2:]))
+		case "federate":
+			os.Exit(runFederate(os.Args[2:]))
+		case "sequence":
+			os.Exit(runSequence(os.Args[2:]))
+		case "stub":
+			os.Exit(runStub(os.Args[2:]))
+		case "skeleton":
+			os.Exit(runSkeleton(os.Args[2:]))
+		}
+	}
+
+	var options Options
+	flag.StringVar(&options.Load, "load", LoadAST, "Lademodus: ast (nur Syntaxbaum) oder types (Typprüfung mit go/types, löst Importe und Aliase auf)")
+	flag.StringVar(&options.Select, "select", "", "Auswahlausdruck, z.B. 'type.name =~ \"Repo$\" && relations.to contains \"DB\"'")
+	flag.IntVar(&options.MaxTypes, "max-types", 0, "maximale Anzahl Typen im Diagramm (0 = unbegrenzt)")
+	flag.IntVar(&options.MaxEdges, "max-edges", 0, "maximale Anzahl Beziehungen im Diagramm (0 = unbegrenzt)")
+	flag.StringVar(&options.LimitAction, "limit-action", "abort", "Verhalten bei Überschreitung der Grenzen: abort oder packages")
+	flag.DurationVar(&options.Budget, "budget", 0, "Zeitbudget der Generierung, z.B. 30s: danach keine weiteren Dateien parsen, teure Analysen (Typprüfung, Rümpfe, Goroutinen, Zyklen) überspringen und ein Teildiagramm mit Hinweis erzeugen (0 = unbegrenzt)")
+	flag.BoolVar(&options.Cluster, "cluster", false, "Typen per Community-Erkennung clustern und Übersicht plus Detaildiagramme erzeugen")
+	flag.StringVar(&options.Orphans, "orphans", "show", "Typen ohne Beziehungen: show, hide oder group")
+	flag.BoolVar(&options.AnalyzeBodies, "analyze-bodies", false, "Funktionsrümpfe analysieren (uses-, casts- und Factory-Beziehungen)")
+	flag.BoolVar(&options.BuildProducts, "build-products", false, "bei Buildern die Beziehung zum Produkt der Build()-Methode zeigen")
+	flag.BoolVar(&options.ContextKeys, "context-keys", false, "Bericht und Diagramm erzeugen, welche Pakete Context-Schlüssel setzen und lesen")
+	flag.BoolVar(&options.InitOrder, "init-order", false, "Diagramm der init()-Funktionen und Paketvariablen mit paketübergreifenden Abhängigkeiten erzeugen")
+	flag.BoolVar(&options.Cycles, "cycles", false, "Zyklen zwischen Typen (gegenseitige Referenzen) melden und im Diagramm rot hervorheben")
+	flag.BoolVar(&options.Goroutines, "goroutines", false, "go-Anweisungen in Methoden als «spawns»-Notiz und Beziehung zum nebenläufig laufenden Typ zeigen")
+	flag.StringVar(&options.PointerReceivers, "pointer-receivers", "off", "Methoden mit Pointer-Receiver kennzeichnen: off, prefix (+*Name) oder stereotype (<<pointer>>)")
+	flag.StringVar(&options.ContextAudit, "ctx-audit", "off", "Methoden melden, die context.Context annehmen, aber weder prüfen noch weitergeben: off, report oder diagram (zusätzlich als Notiz)")
+	flag.BoolVar(&options.FlattenEmbedding, "flatten-embedding", false, "Felder eingebetteter Structs (auch mehrstufig) je eingebetteter Struct in eigenem Abschnitt der einbettenden Klasse zeigen")
+	flag.BoolVar(&options.IncludeGenerated, "include-generated", false, "generierte Dateien (// Code generated ... DO NOT EDIT., z.B. protobuf, mockgen, stringer) ins Diagramm aufnehmen")
+	flag.BoolVar(&options.ShowGeneratedMethods, "show-generated-methods", false, "Methoden aus generierten Dateien (stringer, deepcopy-gen, ...) in den Klassen anzeigen (mit -include-generated)")
+	flag.BoolVar(&options.ExpandEmbedded, "expand-embedded", false, "Felder und Methoden eingebetteter Structs und Interfaces als <<promoted>> in die einbettende Klasse kopieren (effektive API)")
+	flag.StringVar(&options.ShowTags, "show-tags", "", "kommagetrennte Struct-Tag-Schlüssel, die an Feldzeilen angehängt werden, z.B. json,db,validate")
+	flag.BoolVar(&options.DocNotes, "doc-notes", false, "ersten Satz des Doc-Kommentars als Notiz an Structs, Interfaces und Typen zeigen")
+	flag.BoolVar(&options.GroupMethods, "group-methods", false, "Methoden von Structs in Abschnitte je implementiertem Interface plus eigene Methoden gruppieren")
+	flag.StringVar(&options.MemberOrder, "member-order", MemberOrderDecl, "Reihenfolge von Feldern und Methoden in Klassen: decl (wie im Quelltext), alpha oder visibility (exportierte zuerst)")
+	flag.StringVar(&options.InlineStructs, "inline-structs", InlineStructsCollapse, "anonyme Structs in Feldern: collapse (nur struct), fields (Unterfelder als Feld.Name) oder class (eigene Klasse «anonymous»)")
+	flag.StringVar(&options.Conventions, "conventions", "", "Typen nach Namens-/Paketkonventionen stereotypisieren und einfärben: default (handler, service, repository, dto) oder JSON-Datei mit Regeln")
+	flag.BoolVar(&options.Functions, "functions", false, "Funktionen auf Paketebene je Paket als Klasse <<functions>> mit statischen Mitgliedern zeigen")
+	flag.BoolVar(&options.FieldDefaults, "field-defaults", false, "Felder mit den Werten beschriften, die der Konstruktor NewX fest setzt, z.B. +Port: int = 8080")
+	flag.BoolVar(&options.Constraints, "constraints", false, "Constraint-Interfaces (Typmengen wie ~int | ~float64) als <<constraint>> zeigen und <<constrains>>-Kanten von generischen Typen zu ihren Constraints ziehen")
+	flag.BoolVar(&options.ExpandInterfaces, "expand-interfaces", false, "bei Interfaces, die andere einbetten, die geerbten Methoden mit anzeigen")
+	flag.StringVar(&options.PProf, "pprof", "", "pprof-CPU-Profil (z.B. aus go test -cpuprofile); Typen mit hohem CPU-Anteil werden hervorgehoben")
+	flag.StringVar(&options.Overlay, "overlay", "", "JSON-Overlay, das Typen vor dem Rendern entfernt, umbenennt oder als «planned» hinzufügt")
+	flag.BoolVar(&options.IncludeTests, "include-tests", false, "Typen und Methoden aus _test.go-Dateien (Mocks, Testhelfer) ins Diagramm aufnehmen")
+	flag.StringVar(&options.Workspace, "workspace", "combined", "bei go.work-Arbeitsbereichen: combined (ein Diagramm) oder modules (zusätzlich uml_module_<modulpfad> je Modul mit modulübergreifenden Beziehungen)")
+	flag.StringVar(&options.Compare, "compare", "", "zwei Pakete mit gemeinsamen Interfaces nebeneinander vergleichen (uml_compare), z.B. v1,v2")
+	flag.BoolVar(&options.Cards, "cards", false, "eine kleine Karte pro exportiertem Typ (Klasse plus direkte Beziehungen) unter cards/ erzeugen")
+	flag.BoolVar(&options.TestMap, "test-map", false, "Diagramm erzeugen, welche Typen und Methoden von Tests in _test.go-Dateien verwendet werden (uml_tests)")
+	flag.BoolVar(&options.Ports, "ports", false, "Interface-Sicht (uml_ports) erzeugen: nur Interfaces und die Pakete, die sie bereitstellen oder nutzen")
+	flag.StringVar(&options.Merge, "merge", "", "kommagetrennte Modelle (uml_model.json aus -emit json), z.B. anderer Module, ins Diagramm übernehmen; Verweise zwischen den Modulen werden aufgelöst")
+	flag.StringVar(&options.Emit, "emit", "", "Modell (Typen, Felder, Methoden, Beziehungen, Fundstellen, Warnungen) zusätzlich als versioniertes JSON nach uml_model.json schreiben: json")
+	flag.StringVar(&options.Stats, "stats", "text", "Statistik nach der Generierung ausgeben: text, json oder off")
+	flag.BoolVar(&options.StatsFooter, "stats-footer", false, "Statistik als Footer ins Diagramm einbetten")
+	flag.BoolVar(&options.StampCommit, "stamp-commit", false, "kurzen Commit-Hash und Änderungsstatus des Git-Repositorys als Footer ins Diagramm schreiben")
+	flag.BoolVar(&options.Strict, "strict", false, "Warnungen als Fehler behandeln (mit -once: Exit-Code 1)")
+	flag.BoolVar(&options.Once, "once", false, "Diagramm einmal erzeugen und beenden, statt das Verzeichnis zu überwachen")
+	flag.BoolVar(&options.Prune, "prune", false, "Ausgabedateien früherer Läufe löschen, die nicht mehr erzeugt werden (laut manifest.json)")
+	flag.StringVar(&options.Renderers, "renderers", defaultRenderers, "Renderer in Reihenfolge der Präferenz: jar, local, kroki, public, puml (puml = nur .puml-Datei)")
+	flag.StringVar(&options.RenderQueue, "render-queue", "", "statt selbst zu rendern Aufträge (PlantUML-Text und Formate) als JSON in ein Verzeichnis oder per RPUSH nach redis://host:port/schlüssel schreiben")
+	flag.StringVar(&options.QueueFormats, "queue-formats", "png", "kommagetrennte Formate, die Worker für -render-queue erzeugen sollen, z.B. png,svg")
+	flag.StringVar(&options.LocalServer, "local-server", defaultLocalServer, "Adresse des lokalen PlantUML-Servers (Renderer local)")
+	flag.StringVar(&options.KrokiURL, "kroki-url", defaultKrokiURL, "Adresse des Kroki-Dienstes (Renderer kroki)")
+	flag.StringVar(&options.Server, "server", defaultPlantUMLServer, "Adresse des öffentlichen PlantUML-Servers (Renderer public)")
+	flag.StringVar(&options.Serve, "serve", "", "Ausgabeverzeichnis im Watch-Modus schreibgeschützt per HTTP bereitstellen, z.B. :8000; die Seite lädt das Diagramm bei Änderungen neu")
+	flag.BoolVar(&options.Announce, "announce", false, "den Betrachter von -serve per mDNS (_http._tcp) im lokalen Netz ankündigen")
+	flag.BoolVar(&options.StartServer, "start-plantuml-server", false, "plantuml.jar -picoweb als Kindprozess starten und dagegen rendern (spart den JVM-Start pro Diagramm)")
+	flag.IntVar(&options.ServerPort, "plantuml-server-port", 0, "Port für -start-plantuml-server (0 = freier Port)")
+	flag.IntVar(&options.KeepHistory, "keep-history", 0, "die letzten N Diagramme mit Zeitstempel und Commit unter history/ aufbewahren")
+	flag.BoolVar(&options.HistoryImages, "history-images", false, "mit -keep-history auch die PNGs aufbewahren (für history-gif)")
+	flag.BoolVar(&options.Diff, "diff", false, "geänderte Bereiche gegenüber dem vorherigen PNG als <name>.diff.png markieren")
+	flag.BoolVar(&options.Badges, "badges", false, "SVG-Badges (Typen, Pakete, Beziehungen, Architektur-Status) als badge_*.svg erzeugen")
+	flag.StringVar(&options.Metrics, "metrics", "off", "Instabilität, Abstraktheit und Abstand zur Hauptreihe je Paket ausgeben: text, json oder off")
+	flag.BoolVar(&options.MetricsPlot, "metrics-plot", false, "Pakete als Streudiagramm Instabilität/Abstraktheit mit Hauptreihe als main_sequence.svg erzeugen")
+	flag.StringVar(&options.Upload, "upload", "", "Ausgaben nach s3://bucket/prefix oder gs://bucket/prefix hochladen (nur geänderte Dateien)")
+	flag.StringVar(&options.Site, "site", "", "Diagramme als Markdown-Seiten für mkdocs oder hugo ablegen (Abschnitt architecture)")
+	flag.StringVar(&options.SiteDir, "site-dir", ".", "Wurzelverzeichnis der Dokumentationsseite für -site")
+	flag.StringVar(&options.DocLinks, "doc-links", "", "Diagramm pro Paket erzeugen und aus README.md (readme) oder doc.go (docgo) im Paketverzeichnis verlinken")
+	flag.BoolVar(&options.AltText, "alt-text", false, "kurze Textzusammenfassung (Typen, wichtige Beziehungen) je Diagramm als <name>.alt.txt schreiben")
+	flag.StringVar(&options.GOOS, "goos", "", "Zielbetriebssystem für //go:build-Bedingungen und Dateisuffixe wie _windows.go (leer = aktuelles System)")
+	flag.StringVar(&options.GOARCH, "goarch", "", "Zielarchitektur für //go:build-Bedingungen und Dateisuffixe wie _arm64.go (leer = aktuelle Architektur)")
+	flag.StringVar(&options.BuildTags, "tags", "", "kommagetrennte Build-Tags wie bei go build -tags, z.B. integration,debug")
+	flag.StringVar(&options.SkipDirs, "skip-dirs", "vendor,testdata", "kommagetrennte Verzeichnisnamen, die nicht durchsucht werden")
+	flag.StringVar(&options.Ignore, "ignore", "", "zusätzliche kommagetrennte Muster für zu ignorierende Dateien/Verzeichnisse, z.B. '*_gen.go,testdata' (Editor-Temporärdateien werden immer ignoriert)")
+	flag.BoolVar(&options.PumlOnly, "puml-only", false, "nur .puml-Dateien schreiben, keine Bilder erzeugen")
+	flag.BoolVar(&options.NoDownload, "no-download", false, "plantuml.jar nicht herunterladen (für Umgebungen ohne Internetzugang)")
+	flag.StringVar(&options.JarMirrors, "jar-mirrors", "", "kommagetrennte Download-Quellen für plantuml.jar (leer = GitHub, Maven Central)")
+	flag.StringVar(&options.JarSHA256, "jar-sha256", "", "erwartete SHA-256 von plantuml.jar")
+	fileMode := flag.String("file-mode", "0644", "Rechte der Ausgabedateien (oktal), z.B. 0600 für Diagramme proprietären Codes")
+	dirMode := flag.String("dir-mode", "0755", "Rechte neu angelegter Ausgabeverzeichnisse (oktal)")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Verwendung: uml-watcher [Optionen] <Verzeichnispfad> [Ausgabeverzeichnis]")
+		fmt.Fprintln(flag.CommandLine.Output(), "       uml-watcher doctor [Optionen]")
+		fmt.Fprintln(flag.CommandLine.Output(), "       uml-watcher history-gif [Optionen] <Ausgabeverzeichnis> [Zieldatei.gif]")
+		fmt.Fprintln(flag.CommandLine.Output(), "       uml-watcher federate [Optionen] <services.json> [Ausgabeverzeichnis]")
+		fmt.Fprintln(flag.CommandLine.Output(), "       uml-watcher sequence [Optionen] <trace.jsonl> [Ausgabeverzeichnis]")
+		fmt.Fprintln(flag.CommandLine.Output(), "       uml-watcher stub -interface <Name> [Optionen] [Verzeichnis]")
+		fmt.Fprintln(flag.CommandLine.Output(), "       uml-watcher skeleton [Optionen] <diagramm.puml> [Zielverzeichnis]")
+		flag.PrintDefaults()
+		fmt.Fprintf(flag.CommandLine.Output(), "\nJedes Flag kann auch per Umgebungsvariable gesetzt werden, z.B. -max-types als %s.\n", flagEnvName("max-types"))
+		fmt.Fprintf(flag.CommandLine.Output(), "%s setzt das Ausgabeverzeichnis.\n", envOutput)
+	}
+	if err := applyConfigFile(flag.CommandLine); err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	if err := applyEnv(flag.CommandLine); err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		return
+	}
+
+	// Paketmuster wie ./... akzeptieren; Verzeichnisse werden ohnehin rekursiv durchsucht
+	dirPath := packagePatternDir(flag.Arg(0))
+	outputDir := "output"
+	if dir := os.Getenv(envOutput); dir != "" {
+		outputDir = dir
+	}
+
+	if flag.NArg() > 1 {
+		outputDir = flag.Arg(1)
+	}
+
+	if options.LimitAction != "abort" && options.LimitAction != "packages" {
+		fmt.Printf("Ungültiger Wert für -limit-action: %s (erlaubt: abort, packages)\n", options.LimitAction)
+		os.Exit(2)
+	}
+
+	switch options.Load {
+	case LoadAST, LoadTypes:
+	default:
+		fmt.Printf("Ungültiger Wert für -load: %s (erlaubt: ast, types)\n", options.Load)
+		os.Exit(2)
+	}
+
+	switch options.Orphans {
+	case "show", "hide", "group":
+	default:
+		fmt.Printf("Ungültiger Wert für -orphans: %s (erlaubt: show, hide, group)\n", options.Orphans)
+		os.Exit(2)
+	}
+
+	if options.Upload != "" {
+		if _, err := parseUploadTarget(options.Upload); err != nil {
+			fmt.Printf("Ungültiger Wert für -upload: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	switch options.Site {
+	case "", "mkdocs", "hugo":
+	default:
+		fmt.Printf("Ungültiger Wert für -site: %s (erlaubt: mkdocs, hugo)\n", options.Site)
+		os.Exit(2)
+	}
+
+	switch options.Workspace {
+	case "combined", "modules":
+	default:
+		fmt.Printf("Ungültiger Wert für -workspace: %s (erlaubt: combined, modules)\n", options.Workspace)
+		os.Exit(2)
+	}
+
+	if options.Compare != "" {
+		if left, right, ok := strings.Cut(options.Compare, ","); !ok || left == "" || right == "" || strings.Contains(right, ",") {
+			fmt.Printf("Ungültiger Wert für -compare: %s (erwartet: paket1,paket2)\n", options.Compare)
+			os.Exit(2)
+		}
+	}
+
+	switch options.DocLinks {
+	case "", "readme", "docgo":
+	default:
+		fmt.Printf("Ungültiger Wert für -doc-links: %s (erlaubt: readme, docgo)\n", options.DocLinks)
+		os.Exit(2)
+	}
+
+	if options.Emit != "" && options.Emit != "json" {
+		fmt.Printf("Ungültiger Wert für -emit: %s (erlaubt: json)\n", options.Emit)
+		os.Exit(2)
+	}
+
+	switch options.Stats {
+	case "text", "json", "off":
+	default:
+		fmt.Printf("Ungültiger Wert für -stats: %s (erlaubt: text, json, off)\n", options.Stats)
+		os.Exit(2)
+	}
+
+	if _, ok := pointerReceiverMarkers[options.PointerReceivers]; !ok {
+		fmt.Printf("Ungültiger Wert für -pointer-receivers: %s (erlaubt: off, prefix, stereotype)\n", options.PointerReceivers)
+		os.Exit(2)
+	}
+
+	switch options.InlineStructs {
+	case InlineStructsCollapse, InlineStructsFields, InlineStructsClass:
+	default:
+		fmt.Printf("Ungültiger Wert für -inline-structs: %s (erlaubt: collapse, fields, class)\n", options.InlineStructs)
+		os.Exit(2)
+	}
+
+	switch options.MemberOrder {
+	case MemberOrderDecl, MemberOrderAlpha, MemberOrderVisibility:
+	default:
+		fmt.Printf("Ungültiger Wert für -member-order: %s (erlaubt: decl, alpha, visibility)\n", options.MemberOrder)
+		os.Exit(2)
+	}
+
+	switch options.ContextAudit {
+	case "off", "report", "diagram":
+	default:
+		fmt.Printf("Ungültiger Wert für -ctx-audit: %s (erlaubt: off, report, diagram)\n", options.ContextAudit)
+		os.Exit(2)
+	}
+
+	switch options.Metrics {
+	case "text", "json", "off":
+	default:
+		fmt.Printf("Ungültiger Wert für -metrics: %s (erlaubt: text, json, off)\n", options.Metrics)
+		os.Exit(2)
+	}
+
+	var err error
+	if options.FileMode, err = parseFileMode(*fileMode); err != nil {
+		fmt.Printf("Ungültiger Wert für -file-mode: %v\n", err)
+		os.Exit(2)
+	}
+	if options.DirMode, err = parseFileMode(*dirMode); err != nil {
+		fmt.Printf("Ungültiger Wert für -dir-mode: %v\n", err)
+		os.Exit(2)
+	}
+
+	if _, err := parseRenderers(options.Renderers); err != nil {
+		fmt.Printf("Ungültiger Wert für -renderers: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Auswahlausdruck vorab prüfen, damit Syntaxfehler sofort gemeldet werden
+	if options.Select != "" {
+		if _, err := ParseSelection(options.Select); err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+	}
+
+	if options.ExpandEmbedded && options.FlattenEmbedding {
+		fmt.Println("-expand-embedded und -flatten-embedding schließen sich aus")
+		os.Exit(2)
+	}
+
+	if options.Serve != "" && options.Once {
+		fmt.Println("-serve und -once schließen sich aus")
+		os.Exit(2)
+	}
+	if options.Announce && options.Serve == "" {
+		fmt.Println("-announce benötigt -serve")
+		os.Exit(2)
+	}
+
+	if options.RenderQueue != "" {
+		if _, err := parseRenderQueue(options.RenderQueue); err != nil {
+			fmt.Printf("Ungültiger Wert für -render-queue: %v\n", err)
+			os.Exit(2)
+		}
+		if options.PumlOnly || options.StartServer {
+			fmt.Println("-render-queue schließt -puml-only und -start-plantuml-server aus")
+			os.Exit(2)
+		}
+	}
+
+	if options.PumlOnly && options.StartServer {
+		fmt.Println("-puml-only und -start-plantuml-server schließen sich aus")
+		os.Exit(2)
+	}
+
+	// SIGINT/SIGTERM beenden Watch bzw. -once; danach werden gestarteter
+	// Server und Viewer ordentlich beendet
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Gestarteter Server wird als erster Renderer genutzt
+	var server *plantUMLServer
+	if options.StartServer {
+		server, err = startPlantUMLServer(options)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		options.LocalServer = server.url
+		options.Renderers = "local," + options.Renderers
+	}
+
+	watcher := NewFileWatcher(dirPath, outputDir, options)
+	if options.Once {
+		done := make(chan error, 1)
+		go func() { done <- watcher.regenerate() }()
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+		}
+		if server != nil {
+			server.Stop()
+		}
+		if ctx.Err() != nil {
+			os.Exit(130)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	var live *viewer
+	if options.Serve != "" {
+		live, err = startViewer(outputDir, options.Serve, options.Announce)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	watcher.Watch(ctx)
+	if live != nil {
+		live.Stop()
+	}
+	if server != nil {
+		server.Stop()
+	}
+	if ctx.Err() != nil {
+		os.Exit(130)
+	}
+}
@@ -0,0 +1,243 @@
+package umlgen
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeTree legt Dateien (Pfad relativ zu dir -> Inhalt) an
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// typeNames liefert die Modellschlüssel aller Typen
+func typeNames(g *UMLGenerator) []string {
+	var names []string
+	for name := range g.structs {
+		names = append(names, name)
+	}
+	for name := range g.interfaces {
+		names = append(names, name)
+	}
+	for name := range g.namedTypes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// fileSetSize zählt die Dateien im FileSet des Generators
+func fileSetSize(fset *token.FileSet) int {
+	count := 0
+	fset.Iterate(func(*token.File) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+// methodNames liefert die Methoden einer Struct in Modellreihenfolge
+func methodNames(g *UMLGenerator, name string) []string {
+	var names []string
+	if structInfo, ok := g.structs[name]; ok {
+		for _, method := range structInfo.Methods {
+			names = append(names, method.Name)
+		}
+	}
+	return names
+}
+
+func TestUpdateFiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string // Ausgangsstand
+		changed   map[string]string // Neuer Inhalt, "" = Datei gelöscht, "/" = Verzeichnis
+		removed   []string
+		wantTypes []string
+		wantErr   bool
+	}{
+		{
+			name:      "geänderte Datei ersetzt ihren Stand",
+			files:     map[string]string{"a.go": "package p\n\ntype A struct{}\n"},
+			changed:   map[string]string{"a.go": "package p\n\ntype B struct{}\n"},
+			wantTypes: []string{"B"},
+		},
+		{
+			name:      "gelöschte Datei",
+			files:     map[string]string{"a.go": "package p\n\ntype A struct{}\n", "b.go": "package p\n\ntype B struct{}\n"},
+			removed:   []string{"b.go"},
+			wantTypes: []string{"A"},
+		},
+		{
+			name:      "geänderte, nicht mehr vorhandene Datei gilt als gelöscht",
+			files:     map[string]string{"a.go": "package p\n\ntype A struct{}\n", "b.go": "package p\n\ntype B struct{}\n"},
+			changed:   map[string]string{"b.go": ""},
+			wantTypes: []string{"A"},
+		},
+		{
+			name:  "nicht lesbare Datei lässt das Modell aktuell",
+			files: map[string]string{"a.go": "package p\n\ntype A struct{}\n", "b.go": "package p\n\ntype B struct{}\n"},
+			changed: map[string]string{
+				"a.go": "package p\n\ntype C struct{}\n",
+				"c.go": "/",
+			},
+			removed:   []string{"b.go"},
+			wantTypes: []string{"C"},
+			wantErr:   true,
+		},
+		{
+			name:      "Syntaxfehler behalten den lesbaren Teil",
+			files:     map[string]string{"a.go": "package p\n\ntype A struct{}\n"},
+			changed:   map[string]string{"a.go": "package p\n\ntype A struct{}\n\nfunc broken( {\n\ntype D struct{}\n"},
+			wantTypes: []string{"A", "D"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTree(t, dir, tt.files)
+			g := NewUMLGenerator(Options{})
+			if err := g.GenerateUMLFromDirectory(dir); err != nil {
+				t.Fatal(err)
+			}
+
+			var changed, removed []string
+			for name, content := range tt.changed {
+				path := filepath.Join(dir, name)
+				switch content {
+				case "":
+					os.Remove(path)
+				case "/":
+					os.Mkdir(path, 0755)
+				default:
+					os.WriteFile(path, []byte(content), 0644)
+				}
+				changed = append(changed, path)
+			}
+			for _, name := range tt.removed {
+				os.Remove(filepath.Join(dir, name))
+				removed = append(removed, filepath.Join(dir, name))
+			}
+
+			err := g.UpdateFiles(changed, removed)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpdateFiles() Fehler = %v, erwartet Fehler: %v", err, tt.wantErr)
+			}
+			if got := typeNames(g); !slices.Equal(got, tt.wantTypes) {
+				t.Errorf("Typen = %v, erwartet %v", got, tt.wantTypes)
+			}
+			if got := fileSetSize(g.fset); got != len(g.files) {
+				t.Errorf("FileSet enthält %d Dateien, geparst sind %d", got, len(g.files))
+			}
+		})
+	}
+}
+
+func TestParseGoFileAndRemoveFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		versions  []string // Nacheinander geparste Stände derselben Datei
+		remove    bool
+		wantTypes []string
+	}{
+		{name: "erneutes Parsen ersetzt", versions: []string{"package p\n\ntype A struct{}\n", "package p\n\ntype A struct{}\n\ntype B int\n"}, wantTypes: []string{"A", "B"}},
+		{name: "kaputte package-Klausel behält den letzten Stand", versions: []string{"package p\n\ntype A struct{}\n", "packag p\n"}, wantTypes: []string{"A"}},
+		{name: "Entfernen", versions: []string{"package p\n\ntype A struct{}\n"}, remove: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "a.go")
+			g := NewUMLGenerator(Options{})
+			for _, version := range tt.versions {
+				// Mehrfach parsen, wie bei wiederholten Änderungen im Watch-Modus
+				for i := 0; i < 3; i++ {
+					if err := os.WriteFile(path, []byte(version), 0644); err != nil {
+						t.Fatal(err)
+					}
+					if err := g.ParseGoFile(path); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if tt.remove {
+				if err := g.RemoveFile(path); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := typeNames(g); !slices.Equal(got, tt.wantTypes) {
+				t.Errorf("Typen = %v, erwartet %v", got, tt.wantTypes)
+			}
+			if got := fileSetSize(g.fset); got != len(g.files) {
+				t.Errorf("FileSet enthält %d Dateien, geparst sind %d", got, len(g.files))
+			}
+		})
+	}
+}
+
+func TestParseOrderAndReset(t *testing.T) {
+	files := map[string]string{
+		"a.go": "package p\n\nfunc (s *S) Run() {}\n",
+		"b.go": "package p\n\ntype S struct{}\n\nfunc (s *S) Stop() {}\n",
+	}
+	for _, order := range [][]string{{"a.go", "b.go"}, {"b.go", "a.go", "b.go", "a.go"}} {
+		dir := t.TempDir()
+		writeTree(t, dir, files)
+		g := NewUMLGenerator(Options{})
+		for _, name := range order {
+			if err := g.ParseGoFile(filepath.Join(dir, name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// Methoden hängen unabhängig von der Reihenfolge genau einmal am Typ
+		if got, want := methodNames(g, "S"), []string{"Run", "Stop"}; !slices.Equal(got, want) {
+			t.Errorf("Reihenfolge %v: Methoden = %v, erwartet %v", order, got, want)
+		}
+
+		g.Reset()
+		if got := typeNames(g); len(got) != 0 || len(g.files) != 0 {
+			t.Errorf("nach Reset: Typen = %v, %d Dateien", got, len(g.files))
+		}
+	}
+}
+
+func TestSkippedFileWarnings(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"go.mod":       "module example.com/src\n\ngo 1.22\n",
+		"a.go":         "package p\n\ntype A struct{}\n",
+		"a_test.go":    "package p\n\nfunc TestA() {}\n",
+		"ignored.go":   "//go:build ignore\n\npackage p\n",
+		"generated.go": "// Code generated by stringer. DO NOT EDIT.\n\npackage p\n\ntype G struct{}\n",
+		"broken.go":    "pack p\n",
+	})
+	g := NewUMLGenerator(Options{})
+	if err := g.GenerateUMLFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	var skipped []string
+	for _, warning := range g.Warnings() {
+		if warning.Kind == WarningSkippedFile {
+			skipped = append(skipped, filepath.Base(warning.Pos.File))
+		}
+	}
+	slices.Sort(skipped)
+	if want := []string{"a_test.go", "broken.go", "generated.go", "ignored.go"}; !slices.Equal(skipped, want) {
+		t.Errorf("übersprungene Dateien = %v, erwartet %v", skipped, want)
+	}
+}
@@ -0,0 +1,135 @@
+package umlgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"path/filepath"
+)
+
+// spawnedCall ist ein Methodenaufruf, der in einer eigenen Goroutine läuft
+type spawnedCall struct {
+	target string // Typ, dessen Methode nebenläufig läuft
+	method string
+}
+
+// detectSpawns sucht go-Anweisungen in Methoden (--goroutines). Die
+// startende Struct erhält eine «spawns»-Notiz je Fundstelle; läuft eine
+// Methode eines anderen Typs nebenläufig, entsteht eine "spawns"-Beziehung
+// zu diesem Typ mit dem Methodennamen als Beschriftung.
+func (g *UMLGenerator) detectSpawns() {
+	for _, filePath := range sortedMapKeys(g.files) {
+		file := g.files[filePath]
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 || funcDecl.Body == nil {
+				continue
+			}
+			typeName := g.typeKey(g.fileScope(file), receiverTypeName(funcDecl.Recv.List[0].Type))
+			if !g.isClassType(typeName) {
+				continue
+			}
+
+			ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+				goStmt, ok := n.(*ast.GoStmt)
+				if !ok {
+					return true
+				}
+				pos := g.position(goStmt.Pos())
+				if structInfo, ok := g.structs[typeName]; ok {
+					structInfo.Notes = append(structInfo.Notes, fmt.Sprintf("«spawns» %s: go %s (%s:%d)",
+						funcDecl.Name.Name, spawnExprString(goStmt.Call), filepath.Base(pos.File), pos.Line))
+				}
+				for _, spawned := range g.spawnedCalls(typeName, funcDecl, goStmt.Call) {
+					if spawned.target == typeName {
+						continue
+					}
+					g.relations = append(g.relations, Relation{
+						From:  typeName,
+						To:    spawned.target,
+						Type:  "spawns",
+						Label: spawned.method,
+						Pos:   pos,
+					})
+				}
+				return true
+			})
+		}
+	}
+}
+
+// spawnExprString kürzt den Aufruf einer go-Anweisung für die Notiz, z.B.
+// s.worker.Run() oder func() {…}()
+func spawnExprString(call *ast.CallExpr) string {
+	if _, ok := call.Fun.(*ast.FuncLit); ok {
+		return "func() {…}()"
+	}
+	return types.ExprString(call.Fun) + "()"
+}
+
+// spawnedCalls ermittelt die Methoden bekannter Typen, die eine go-Anweisung
+// startet: direkt (go s.worker.Run()) oder im Rumpf einer Funktionsliteral
+// (go func() { s.worker.Run() }()). Aufgelöst werden der Receiver, seine
+// Felder und die Parameter der Methode.
+func (g *UMLGenerator) spawnedCalls(typeName string, funcDecl *ast.FuncDecl, call *ast.CallExpr) []spawnedCall {
+	receivers := make(map[string]string) // Variable -> Typ
+	if names := funcDecl.Recv.List[0].Names; len(names) > 0 {
+		receivers[names[0].Name] = typeName
+	}
+	for _, param := range funcDecl.Type.Params.List {
+		target, _ := containerTarget(param.Type)
+		if !g.isKnownType(target) {
+			continue
+		}
+		for _, name := range param.Names {
+			receivers[name.Name] = target
+		}
+	}
+
+	fieldTargets := make(map[string]string)
+	if structInfo, ok := g.structs[typeName]; ok {
+		for _, field := range structInfo.Fields {
+			fieldTargets[field.Name] = field.Target
+		}
+	}
+
+	resolve := func(call *ast.CallExpr) (spawnedCall, bool) {
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return spawnedCall{}, false
+		}
+		target := ""
+		switch x := sel.X.(type) {
+		case *ast.Ident:
+			target = receivers[x.Name]
+		case *ast.SelectorExpr:
+			// Feld des Receivers: s.worker.Run()
+			if ident, ok := x.X.(*ast.Ident); ok && receivers[ident.Name] == typeName {
+				target = fieldTargets[x.Sel.Name]
+			}
+		}
+		if !g.isKnownType(target) {
+			return spawnedCall{}, false
+		}
+		return spawnedCall{target: target, method: sel.Sel.Name}, true
+	}
+
+	lit, ok := call.Fun.(*ast.FuncLit)
+	if !ok {
+		if spawned, ok := resolve(call); ok {
+			return []spawnedCall{spawned}
+		}
+		return nil
+	}
+
+	var spawned []spawnedCall
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		if inner, ok := n.(*ast.CallExpr); ok {
+			if s, ok := resolve(inner); ok {
+				spawned = append(spawned, s)
+			}
+		}
+		return true
+	})
+	return spawned
+}
@@ -0,0 +1,166 @@
+package umlgen
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// sizeLimitExceeded prüft das Modell gegen --max-types und --max-edges und
+// liefert eine Beschreibung der Überschreitung oder einen leeren String
+func (g *UMLGenerator) sizeLimitExceeded() string {
+	typeCount := len(g.structs) + len(g.interfaces) + len(g.namedTypes)
+	if g.options.MaxTypes > 0 && typeCount > g.options.MaxTypes {
+		return fmt.Sprintf("Modell enthält %d Typen (Grenze: %d)", typeCount, g.options.MaxTypes)
+	}
+
+	edgeCount := len(g.relations)
+	if g.options.MaxEdges > 0 && edgeCount > g.options.MaxEdges {
+		return fmt.Sprintf("Modell enthält %d Beziehungen (Grenze: %d)", edgeCount, g.options.MaxEdges)
+	}
+
+	return ""
+}
+
+// plantUMLSource liefert die Quelle des PlantUML-Diagramms unter Beachtung
+// der Größengrenzen. Bei Überschreitung wird abgebrochen oder auf die
+// Paketansicht gewechselt.
+func (g *UMLGenerator) plantUMLSource() (io.WriterTo, error) {
+	msg := g.sizeLimitExceeded()
+	if msg == "" {
+		return g, nil
+	}
+
+	if g.options.LimitAction == "packages" {
+		fmt.Printf("Hinweis: %s, wechsle zur Paketansicht\n", msg)
+		return strings.NewReader(g.GeneratePackagePlantUML()), nil
+	}
+
+	return nil, fmt.Errorf("%s. Das Diagramm wäre kaum renderbar; Typen mit --select einschränken, "+
+		"die Grenzen erhöhen oder --limit-action packages verwenden", msg)
+}
+
+// typePackage liefert das Paket eines bekannten Typs
+func (g *UMLGenerator) typePackage(name string) (string, bool) {
+	if structInfo, ok := g.structs[name]; ok {
+		return structInfo.Package, true
+	}
+	if interfaceInfo, ok := g.interfaces[name]; ok {
+		return interfaceInfo.Package, true
+	}
+	if namedInfo, ok := g.namedTypes[name]; ok {
+		return namedInfo.Package, true
+	}
+	if factory, ok := g.factories[name]; ok {
+		return factory.Package, true
+	}
+	return "", false
+}
+
+// GeneratePackagePlantUML erzeugt eine Paketansicht: ein Element pro Paket
+// und eine gewichtete Abhängigkeit pro Paketpaar, die die Beziehungen
+// zwischen den Typen beider Pakete zusammenfasst
+func (g *UMLGenerator) GeneratePackagePlantUML() string {
+	packages := make(map[string]bool)
+	for _, structInfo := range g.structs {
+		packages[structInfo.Package] = true
+	}
+	for _, interfaceInfo := range g.interfaces {
+		packages[interfaceInfo.Package] = true
+	}
+	for _, namedInfo := range g.namedTypes {
+		packages[namedInfo.Package] = true
+	}
+
+	edges := g.packageEdges()
+
+	var sb strings.Builder
+	sb.WriteString("@startuml\n\n")
+
+	for _, pkg := range sortedMapKeys(packages) {
+		sb.WriteString(fmt.Sprintf("package %s {\n}\n\n", pkg))
+	}
+
+	for _, edge := range edges {
+		sb.WriteString(fmt.Sprintf("%s ..> %s : %s\n", edge.From, edge.To, edge.label()))
+	}
+
+	sb.WriteString("\n@enduml")
+	return sb.String()
+}
+
+// PackageEdge fasst alle Typbeziehungen zwischen zwei Paketen zusammen
+type PackageEdge struct {
+	From   string
+	To     string
+	Count  int            // Anzahl zusammengefasster Typbeziehungen
+	ByType map[string]int // Anzahl pro Beziehungsart
+}
+
+// label beschriftet die Kante mit der Gesamtzahl und der Aufteilung nach Art,
+// z.B. "14 deps (10 aggregation, 4 implements)"
+func (e PackageEdge) label() string {
+	var kinds []string
+	for kind := range e.ByType {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	var parts []string
+	for _, kind := range kinds {
+		parts = append(parts, fmt.Sprintf("%d %s", e.ByType[kind], kind))
+	}
+
+	unit := "deps"
+	if e.Count == 1 {
+		unit = "dep"
+	}
+	if len(parts) == 1 {
+		return fmt.Sprintf("%d %s (%s)", e.Count, unit, kinds[0])
+	}
+	return fmt.Sprintf("%d %s (%s)", e.Count, unit, strings.Join(parts, ", "))
+}
+
+// packageEdges aggregiert die Typbeziehungen zu sortierten Paketkanten
+func (g *UMLGenerator) packageEdges() []PackageEdge {
+	edges := make(map[[2]string]*PackageEdge)
+	for _, relation := range g.relations {
+		fromPkg, ok1 := g.typePackage(relation.From)
+		toPkg, ok2 := g.typePackage(relation.To)
+		if !ok1 || !ok2 || fromPkg == toPkg {
+			continue
+		}
+
+		key := [2]string{fromPkg, toPkg}
+		edge, ok := edges[key]
+		if !ok {
+			edge = &PackageEdge{From: fromPkg, To: toPkg, ByType: make(map[string]int)}
+			edges[key] = edge
+		}
+		edge.Count++
+		edge.ByType[relation.Type]++
+	}
+
+	result := make([]PackageEdge, 0, len(edges))
+	for _, edge := range edges {
+		result = append(result, *edge)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].From != result[j].From {
+			return result[i].From < result[j].From
+		}
+		return result[i].To < result[j].To
+	})
+	return result
+}
+
+// sortedMapKeys liefert die Schlüssel einer Map in sortierter Reihenfolge
+func sortedMapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
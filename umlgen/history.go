@@ -0,0 +1,157 @@
+package umlgen
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// historyDirName ist das Unterverzeichnis der Ausgabe für Snapshots (--keep-history)
+const historyDirName = "history"
+
+// saveHistory legt einen Snapshot von uml_diagram.puml (und mit images auch
+// des PNG) unter history/ ab, benannt nach Zeitpunkt und Commit. Unveränderte
+// Diagramme erzeugen keinen neuen Snapshot; es bleiben höchstens keep erhalten.
+func (g *UMLGenerator) saveHistory(outputDir, sourceDir string, keep int, images bool) error {
+	source, err := os.ReadFile(filepath.Join(outputDir, "uml_diagram.puml"))
+	if err != nil {
+		return nil
+	}
+
+	historyDir := filepath.Join(outputDir, historyDirName)
+	snapshots := historySnapshots(historyDir, ".puml")
+	if len(snapshots) > 0 {
+		if latest, err := os.ReadFile(snapshots[len(snapshots)-1]); err == nil && bytes.Equal(latest, source) {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(historyDir, g.dirMode()); err != nil {
+		return fmt.Errorf("Fehler beim Erstellen des Verlaufsverzeichnisses: %v", err)
+	}
+
+	// Zeitstempel zuerst, damit die Dateinamen chronologisch sortieren
+	name := "uml_diagram-" + time.Now().UTC().Format("20060102T150405Z")
+	if hash, dirty := gitCommit(sourceDir); hash != "" {
+		name += "-" + hash
+		if dirty {
+			name += "-dirty"
+		}
+	}
+
+	if err := writeFileFrom(filepath.Join(historyDir, name+".puml"), bytes.NewReader(source), g.fileMode()); err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Verlaufs: %v", err)
+	}
+	if images {
+		if image, err := os.ReadFile(filepath.Join(outputDir, "uml_diagram.png")); err == nil {
+			if err := writeFileFrom(filepath.Join(historyDir, name+".png"), bytes.NewReader(image), g.fileMode()); err != nil {
+				return fmt.Errorf("Fehler beim Speichern des Verlaufs: %v", err)
+			}
+		}
+	}
+	fmt.Printf("Verlauf gespeichert: %s\n", filepath.Join(historyDir, name))
+
+	// Älteste Snapshots über keep hinaus löschen, samt zugehörigem Bild
+	snapshots = historySnapshots(historyDir, ".puml")
+	for len(snapshots) > keep {
+		base := strings.TrimSuffix(snapshots[0], ".puml")
+		os.Remove(base + ".puml")
+		os.Remove(base + ".png")
+		snapshots = snapshots[1:]
+	}
+	return nil
+}
+
+// historySnapshots listet die Snapshots mit der Endung ext chronologisch
+func historySnapshots(historyDir, ext string) []string {
+	matches, _ := filepath.Glob(filepath.Join(historyDir, "uml_diagram-*"+ext))
+	sort.Strings(matches)
+	return matches
+}
+
+// runHistoryGIF erzeugt aus den Bild-Snapshots eines Ausgabeverzeichnisses
+// eine GIF-Animation der Architekturentwicklung (Unterbefehl "history-gif")
+func runHistoryGIF(args []string) int {
+	fs := flag.NewFlagSet("history-gif", flag.ExitOnError)
+	delay := fs.Duration("delay", time.Second, "Anzeigedauer pro Bild")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Verwendung: uml-watcher history-gif [Optionen] <Ausgabeverzeichnis> [Zieldatei.gif]")
+		fs.PrintDefaults()
+	}
+	if err := applySubcommandConfig(fs); err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 2
+	}
+
+	outputDir := fs.Arg(0)
+	target := filepath.Join(outputDir, "uml_history.gif")
+	if fs.NArg() > 1 {
+		target = fs.Arg(1)
+	}
+
+	if err := writeHistoryGIF(filepath.Join(outputDir, historyDirName), target, *delay); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	fmt.Printf("Animation erstellt: %s\n", target)
+	return 0
+}
+
+// writeHistoryGIF setzt die PNG-Snapshots zu einem animierten GIF zusammen.
+// Alle Bilder werden auf die größte Abmessung gebracht, damit das Diagramm
+// beim Abspielen nicht springt.
+func writeHistoryGIF(historyDir, target string, delay time.Duration) error {
+	paths := historySnapshots(historyDir, ".png")
+	if len(paths) == 0 {
+		return fmt.Errorf("Keine Bild-Snapshots in %s (mit -keep-history und -history-images erzeugen)", historyDir)
+	}
+
+	var images []image.Image
+	var bounds image.Rectangle
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("Fehler beim Lesen von %s: %v", path, err)
+		}
+		img, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("Fehler beim Lesen von %s: %v", path, err)
+		}
+		images = append(images, img)
+		bounds = bounds.Union(img.Bounds())
+	}
+
+	animation := &gif.GIF{}
+	for _, img := range images {
+		frame := image.NewPaletted(bounds, palette.Plan9)
+		draw.Draw(frame, bounds, image.White, image.Point{}, draw.Src)
+		draw.FloydSteinberg.Draw(frame, img.Bounds(), img, img.Bounds().Min)
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, int(delay/(10*time.Millisecond)))
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, animation); err != nil {
+		return fmt.Errorf("Fehler beim Erzeugen der Animation: %v", err)
+	}
+	if err := writeFileFrom(target, &buf, 0644); err != nil {
+		return fmt.Errorf("Fehler beim Speichern der Animation: %v", err)
+	}
+	return nil
+}
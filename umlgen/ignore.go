@@ -0,0 +1,42 @@
+package umlgen
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// defaultIgnoreGlobs sind Temporär- und Sicherungsdateien gängiger Editoren,
+// die keine Neugenerierung auslösen sollen
+var defaultIgnoreGlobs = []string{
+	".#*",           // Emacs-Lockdateien (.#main.go)
+	"#*#",           // Emacs-Autosave
+	"*~",            // Emacs- und Vim-Sicherungen
+	"*.swp",         // Vim-Swapdateien
+	"*.swx",         // Vim-Swapdateien
+	"4913",          // Vims Testdatei beim Speichern
+	"*___jb_tmp___", // JetBrains Safe Write
+	"*___jb_old___", // JetBrains Safe Write
+	".*.tmp",        // Temporärdateien beim atomaren Schreiben
+}
+
+// ignoreGlobs liefert die voreingestellten plus die per --skip-dirs und
+// --ignore angegebenen Muster
+func ignoreGlobs(options Options) []string {
+	globs := append([]string{}, defaultIgnoreGlobs...)
+	for _, glob := range strings.Split(options.SkipDirs+","+options.Ignore, ",") {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			globs = append(globs, glob)
+		}
+	}
+	return globs
+}
+
+// ignored prüft, ob ein Datei- oder Verzeichnisname auf eines der Muster passt
+func ignored(name string, globs []string) bool {
+	for _, glob := range globs {
+		if ok, _ := filepath.Match(glob, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package umlgen
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// diffHighlight ist die Farbe geänderter Pixel im Differenzbild
+var diffHighlight = color.RGBA{R: 255, A: 255}
+
+// diffImages vergleicht zwei Bilder pixelweise. Das Ergebnis zeigt das neue
+// Bild abgeschwächt und geänderte Pixel rot; bei unterschiedlicher Größe
+// zählt der überstehende Bereich als geändert.
+func diffImages(before, after image.Image) (*image.RGBA, int) {
+	bounds := before.Bounds().Union(after.Bounds())
+	result := image.NewRGBA(bounds)
+	draw.Draw(result, bounds, image.White, image.Point{}, draw.Src)
+
+	changed := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := image.Point{X: x, Y: y}
+			if !p.In(before.Bounds()) || !p.In(after.Bounds()) || !sameColor(before.At(x, y), after.At(x, y)) {
+				result.Set(x, y, diffHighlight)
+				changed++
+				continue
+			}
+			result.Set(x, y, faded(after.At(x, y)))
+		}
+	}
+	return result, changed
+}
+
+// sameColor vergleicht zwei Farben unabhängig vom Farbmodell
+func sameColor(a, b color.Color) bool {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
+
+// faded hellt eine Farbe auf, damit Änderungen hervorstechen
+func faded(c color.Color) color.Color {
+	r, g, b, _ := c.RGBA()
+	lighten := func(v uint32) uint8 { return uint8(255 - (255-v>>8)/4) }
+	return color.RGBA{R: lighten(r), G: lighten(g), B: lighten(b), A: 255}
+}
+
+// writeImageDiff schreibt <fileName>.diff.png, wenn sich das neue PNG vom
+// vorherigen unterscheidet (--diff). previous ist der alte Dateiinhalt.
+func (g *UMLGenerator) writeImageDiff(outputDir, fileName string, previous, current []byte) error {
+	before, err := png.Decode(bytes.NewReader(previous))
+	if err != nil {
+		// Vorheriges Bild unlesbar, z.B. von einem anderen Renderer: kein Vergleich
+		return nil
+	}
+	after, err := png.Decode(bytes.NewReader(current))
+	if err != nil {
+		return nil
+	}
+
+	result, changed := diffImages(before, after)
+	diffPath := filepath.Join(outputDir, fileName+".diff.png")
+	if changed == 0 {
+		// Ohne Änderungen kein Differenzbild, ein altes wäre irreführend
+		os.Remove(diffPath)
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, result); err != nil {
+		return fmt.Errorf("Fehler beim Erzeugen des Differenzbilds: %v", err)
+	}
+	if err := writeFileFrom(diffPath, &buf, g.fileMode()); err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Differenzbilds: %v", err)
+	}
+	fmt.Printf("Differenzbild erstellt: %s (%d Pixel geändert)\n", diffPath, changed)
+	return g.recordArtifact(outputDir, diffPath, "png", "diff")
+}
@@ -0,0 +1,147 @@
+package umlgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PackageInit beschreibt die Initialisierung eines Pakets: init()-Funktionen,
+// Paketvariablen mit Initialisierer und deren Verweise auf andere Pakete
+type PackageInit struct {
+	Package string
+	Inits   []Position
+	Vars    []string
+	Deps    map[string][]string // Paket -> initialisierende Elemente, die darauf verweisen
+}
+
+// PackageInits sammelt die Initialisierung aller Pakete des Modells. Verweise
+// auf Pakete außerhalb des analysierten Codes werden ignoriert.
+func (g *UMLGenerator) PackageInits() []*PackageInit {
+	known := make(map[string]bool)
+	for _, file := range g.files {
+		known[file.Name.Name] = true
+	}
+
+	inits := make(map[string]*PackageInit)
+	for _, filePath := range sortedMapKeys(g.files) {
+		file := g.files[filePath]
+		pkg := file.Name.Name
+		info, ok := inits[pkg]
+		if !ok {
+			info = &PackageInit{Package: pkg, Deps: make(map[string][]string)}
+			inits[pkg] = info
+		}
+
+		imports := importNames(file)
+		addDeps := func(element string, node ast.Node) {
+			for _, dep := range referencedPackages(node, imports) {
+				if known[dep] && dep != pkg {
+					info.Deps[dep] = appendUnique(info.Deps[dep], element)
+				}
+			}
+		}
+
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil && d.Name.Name == "init" && d.Body != nil {
+					info.Inits = append(info.Inits, g.position(d.Pos()))
+					addDeps("init()", d.Body)
+				}
+			case *ast.GenDecl:
+				if d.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range d.Specs {
+					valueSpec := spec.(*ast.ValueSpec)
+					if len(valueSpec.Values) == 0 {
+						continue
+					}
+					for _, name := range valueSpec.Names {
+						if name.Name == "_" {
+							continue
+						}
+						info.Vars = append(info.Vars, name.Name)
+						for _, value := range valueSpec.Values {
+							addDeps("var "+name.Name, value)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	result := make([]*PackageInit, 0, len(inits))
+	for _, pkg := range sortedMapKeys(inits) {
+		result = append(result, inits[pkg])
+	}
+	return result
+}
+
+// importNames bildet die im Code verwendeten Importnamen auf Paketnamen ab
+func importNames(file *ast.File) map[string]string {
+	names := make(map[string]string)
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		pkg := importName(path)
+		name := pkg
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		names[name] = pkg
+	}
+	return names
+}
+
+// referencedPackages liefert die Pakete, auf die ein Knoten per pkg.Name verweist
+func referencedPackages(node ast.Node, imports map[string]string) []string {
+	var deps []string
+	ast.Inspect(node, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			if pkg, ok := imports[ident.Name]; ok {
+				deps = appendUnique(deps, pkg)
+			}
+		}
+		return true
+	})
+	return deps
+}
+
+// GenerateInitOrderPlantUML zeichnet pro Paket dessen init()-Funktionen und
+// initialisierte Variablen sowie Abhängigkeiten zu Paketen, die vorher
+// initialisiert sein müssen
+func (g *UMLGenerator) GenerateInitOrderPlantUML(inits []*PackageInit) string {
+	var sb strings.Builder
+	sb.WriteString("@startuml\n\n")
+
+	for _, info := range inits {
+		sb.WriteString(fmt.Sprintf("class \"%s\" as pkg_%s <<package>> {\n", info.Package, info.Package))
+		for _, pos := range info.Inits {
+			sb.WriteString(fmt.Sprintf("    init() %s:%d\n", filepath.Base(pos.File), pos.Line))
+		}
+		for _, v := range info.Vars {
+			sb.WriteString(fmt.Sprintf("    var %s\n", v))
+		}
+		sb.WriteString("}\n\n")
+	}
+
+	for _, info := range inits {
+		for _, dep := range sortedMapKeys(info.Deps) {
+			sb.WriteString(fmt.Sprintf("pkg_%s ..> pkg_%s : %s\n", info.Package, dep, strings.Join(info.Deps[dep], ", ")))
+		}
+	}
+
+	sb.WriteString("\n@enduml")
+	return sb.String()
+}
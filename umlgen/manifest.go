@@ -0,0 +1,146 @@
+package umlgen
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// manifestFileName ist der Name des Manifests im Ausgabeverzeichnis
+const manifestFileName = "manifest.json"
+
+// manifestVersion ist die Version des Manifest-Formats
+const manifestVersion = 1
+
+// Artifact beschreibt eine erzeugte Ausgabedatei
+type Artifact struct {
+	Path      string    `json:"path"`     // Relativ zum Ausgabeverzeichnis
+	Format    string    `json:"format"`   // "puml", "png", "txt", ...
+	Packages  []string  `json:"packages"` // Quellpakete, aus denen die Datei entstand
+	SHA256    string    `json:"sha256"`
+	Generated time.Time `json:"generated"`
+	Renderer  string    `json:"renderer,omitempty"` // Renderer, der ein Bild erzeugt hat
+	Changed   bool      `json:"changed"`            // Inhalt weicht vom vorherigen Manifest ab
+}
+
+// Manifest listet alle Ausgabedateien eines Generierungslaufs
+type Manifest struct {
+	Version   int        `json:"version"`
+	Generated time.Time  `json:"generated"`
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+// recordArtifact erfasst eine geschriebene Datei samt Inhalts-Hash für das Manifest
+func (g *UMLGenerator) recordArtifact(outputDir, path, format, renderer string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+
+	rel, err := filepath.Rel(outputDir, path)
+	if err != nil {
+		rel = path
+	}
+
+	g.artifacts = append(g.artifacts, Artifact{
+		Path:      filepath.ToSlash(rel),
+		Format:    format,
+		Renderer:  renderer,
+		Packages:  g.packageNames(),
+		SHA256:    hex.EncodeToString(sum[:]),
+		Generated: time.Now().UTC(),
+	})
+	return nil
+}
+
+// packageNames liefert die sortierten Paketnamen aller Typen des Modells
+func (g *UMLGenerator) packageNames() []string {
+	packages := make(map[string]bool)
+	for _, typeInfo := range g.Types() {
+		packages[typeInfo.Package] = true
+	}
+	return sortedMapKeys(packages)
+}
+
+// readManifest liest das Manifest eines Ausgabeverzeichnisses, sofern vorhanden
+func readManifest(outputDir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(outputDir, manifestFileName))
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// WriteManifest schreibt manifest.json mit allen Dateien des aktuellen Laufs.
+// Dateien, deren Hash sich gegenüber dem vorherigen Manifest geändert hat
+// oder die neu sind, werden als geändert markiert.
+// Mit --prune werden Dateien des vorherigen Manifests gelöscht, die im
+// aktuellen Lauf nicht mehr erzeugt wurden.
+func (g *UMLGenerator) WriteManifest(outputDir string) error {
+	previous := make(map[string]string)
+	if manifest, err := readManifest(outputDir); err == nil {
+		for _, artifact := range manifest.Artifacts {
+			previous[artifact.Path] = artifact.SHA256
+		}
+	}
+
+	if g.options.Prune {
+		if err := g.pruneArtifacts(outputDir, previous); err != nil {
+			return err
+		}
+	}
+
+	manifest := Manifest{Version: manifestVersion, Generated: time.Now().UTC(), Artifacts: []Artifact{}}
+	for _, artifact := range g.artifacts {
+		artifact.Changed = previous[artifact.Path] != artifact.SHA256
+		manifest.Artifacts = append(manifest.Artifacts, artifact)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(outputDir, manifestFileName)
+	if err := writeFileFrom(path, bytes.NewReader(data), g.fileMode()); err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Manifests: %v", err)
+	}
+	return nil
+}
+
+// pruneArtifacts löscht Dateien aus dem vorherigen Manifest, die im aktuellen
+// Lauf nicht mehr geschrieben wurden, z.B. nach Umbenennen eines Clusters.
+// Dateien, die nie im Manifest standen, bleiben unberührt, ebenso Pfade aus
+// einem manipulierten Manifest, die aus outputDir hinausführen.
+func (g *UMLGenerator) pruneArtifacts(outputDir string, previous map[string]string) error {
+	current := make(map[string]bool)
+	for _, artifact := range g.artifacts {
+		current[artifact.Path] = true
+	}
+
+	for _, path := range sortedMapKeys(previous) {
+		if current[path] {
+			continue
+		}
+		if !filepath.IsLocal(filepath.FromSlash(path)) {
+			fmt.Printf("Veraltete Ausgabe %s liegt außerhalb von %s und wird nicht gelöscht\n", path, outputDir)
+			continue
+		}
+		fullPath := filepath.Join(outputDir, filepath.FromSlash(path))
+		if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("Fehler beim Löschen veralteter Ausgabe: %v", err)
+		}
+		fmt.Printf("Veraltete Ausgabe gelöscht: %s\n", fullPath)
+	}
+	return nil
+}
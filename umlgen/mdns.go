@@ -0,0 +1,228 @@
+package umlgen
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// mDNS-Gruppe, angekündigter Diensttyp und Gültigkeit der Einträge (--announce)
+const (
+	mdnsAddress = "224.0.0.251:5353"
+	mdnsService = "_http._tcp.local."
+	mdnsTTL     = 120
+)
+
+// DNS-Eintragstypen und -Klassen für die Antworten
+const (
+	dnsTypeA        = 1
+	dnsTypePTR      = 12
+	dnsTypeTXT      = 16
+	dnsTypeSRV      = 33
+	dnsClassIN      = 1
+	dnsCacheFlush   = 0x8000 // mDNS: Eintrag ersetzt zwischengespeicherte
+	dnsFlagResponse = 0x8400 // Antwort, autoritativ
+)
+
+// mdnsAnnouncer kündigt den Betrachter per DNS-SD über Multicast-DNS als
+// _http._tcp-Dienst an und beantwortet Anfragen danach, so dass er in
+// Bonjour-/Avahi-Browsern im lokalen Netz erscheint
+type mdnsAnnouncer struct {
+	conn     *net.UDPConn
+	group    *net.UDPAddr
+	instance string // z.B. go-uml-generator-laptop._http._tcp.local.
+	host     string // z.B. laptop.local.
+	port     int
+	ips      []net.IP
+}
+
+// startMDNSAnnouncer tritt der mDNS-Gruppe bei und kündigt den Dienst an
+func startMDNSAnnouncer(port int) (*mdnsAnnouncer, error) {
+	group, err := net.ResolveUDPAddr("udp4", mdnsAddress)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenMulticastUDP("udp4", nil, group)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Beitritt zur mDNS-Gruppe: %v", err)
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "go-uml-generator"
+	}
+	host, _, _ = strings.Cut(host, ".")
+
+	a := &mdnsAnnouncer{
+		conn:     conn,
+		group:    group,
+		instance: "go-uml-generator-" + host + "." + mdnsService,
+		host:     host + ".local.",
+		port:     port,
+		ips:      localIPv4s(),
+	}
+	if len(a.ips) == 0 {
+		conn.Close()
+		return nil, errors.New("Fehler bei der mDNS-Ankündigung: keine IPv4-Adresse im lokalen Netz")
+	}
+
+	go a.serve()
+	// Ankündigung wie in RFC 6762 empfohlen nach einer Sekunde wiederholen
+	a.send(mdnsTTL)
+	time.AfterFunc(time.Second, func() { a.send(mdnsTTL) })
+	return a, nil
+}
+
+// localIPv4s liefert die IPv4-Adressen aller Netzwerkschnittstellen außer
+// Loopback
+func localIPv4s() []net.IP {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var ips []net.IP
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+			ips = append(ips, ipNet.IP.To4())
+		}
+	}
+	return ips
+}
+
+// serve beantwortet Anfragen nach dem Diensttyp, der Instanz oder dem
+// Rechnernamen, bis die Verbindung geschlossen wird
+func (a *mdnsAnnouncer) serve() {
+	buf := make([]byte, 9000)
+	for {
+		n, _, err := a.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		names, err := dnsQuestions(buf[:n])
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			if strings.EqualFold(name, mdnsService) || strings.EqualFold(name, a.instance) || strings.EqualFold(name, a.host) {
+				a.send(mdnsTTL)
+				break
+			}
+		}
+	}
+}
+
+// Stop meldet den Dienst ab (Einträge mit TTL 0) und verlässt die Gruppe
+func (a *mdnsAnnouncer) Stop() {
+	a.send(0)
+	a.conn.Close()
+}
+
+// send verschickt PTR-, SRV-, TXT- und A-Einträge des Dienstes an die Gruppe
+func (a *mdnsAnnouncer) send(ttl uint32) {
+	var records [][]byte
+	records = append(records, dnsRecord(mdnsService, dnsTypePTR, dnsClassIN, ttl, dnsName(a.instance)))
+
+	srv := make([]byte, 6)
+	binary.BigEndian.PutUint16(srv[4:], uint16(a.port)) // Priorität und Gewicht 0
+	records = append(records, dnsRecord(a.instance, dnsTypeSRV, dnsClassIN|dnsCacheFlush, ttl, append(srv, dnsName(a.host)...)))
+
+	txt := "path=/"
+	records = append(records, dnsRecord(a.instance, dnsTypeTXT, dnsClassIN|dnsCacheFlush, ttl, append([]byte{byte(len(txt))}, txt...)))
+
+	for _, ip := range a.ips {
+		records = append(records, dnsRecord(a.host, dnsTypeA, dnsClassIN|dnsCacheFlush, ttl, ip))
+	}
+
+	msg := make([]byte, 12)
+	binary.BigEndian.PutUint16(msg[2:], dnsFlagResponse)
+	binary.BigEndian.PutUint16(msg[6:], uint16(len(records)))
+	for _, record := range records {
+		msg = append(msg, record...)
+	}
+	a.conn.WriteToUDP(msg, a.group)
+}
+
+// dnsName kodiert einen Namen als Folge von Labels, z.B. "a.local." ->
+// 1 'a' 5 'local' 0
+func dnsName(name string) []byte {
+	var out []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		out = append(out, byte(len(label)))
+		out = append(out, label...)
+	}
+	return append(out, 0)
+}
+
+// dnsRecord kodiert einen Eintrag des Antwortabschnitts
+func dnsRecord(name string, recordType, class uint16, ttl uint32, data []byte) []byte {
+	out := dnsName(name)
+	fixed := make([]byte, 10)
+	binary.BigEndian.PutUint16(fixed[0:], recordType)
+	binary.BigEndian.PutUint16(fixed[2:], class)
+	binary.BigEndian.PutUint32(fixed[4:], ttl)
+	binary.BigEndian.PutUint16(fixed[8:], uint16(len(data)))
+	return append(append(out, fixed...), data...)
+}
+
+// dnsQuestions liefert die erfragten Namen einer mDNS-Anfrage. Antworten
+// anderer Teilnehmer werden ignoriert.
+func dnsQuestions(msg []byte) ([]string, error) {
+	if len(msg) < 12 {
+		return nil, errors.New("zu kurz")
+	}
+	if binary.BigEndian.Uint16(msg[2:])&0x8000 != 0 {
+		return nil, nil
+	}
+	count := int(binary.BigEndian.Uint16(msg[4:]))
+	offset := 12
+	var names []string
+	for i := 0; i < count; i++ {
+		name, next, err := dnsReadName(msg, offset)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		offset = next + 4 // Typ und Klasse
+	}
+	return names, nil
+}
+
+// dnsReadName liest einen Namen ab offset und folgt dabei komprimierten
+// Verweisen. Geliefert wird auch der Offset hinter dem Namen.
+func dnsReadName(msg []byte, offset int) (string, int, error) {
+	var labels []string
+	end := -1
+	for jumps := 0; jumps < 16; {
+		if offset >= len(msg) {
+			return "", 0, errors.New("Name außerhalb der Nachricht")
+		}
+		length := int(msg[offset])
+		switch {
+		case length == 0:
+			if end < 0 {
+				end = offset + 1
+			}
+			return strings.Join(labels, ".") + ".", end, nil
+		case length&0xC0 == 0xC0:
+			if offset+1 >= len(msg) {
+				return "", 0, errors.New("Verweis außerhalb der Nachricht")
+			}
+			if end < 0 {
+				end = offset + 2
+			}
+			offset = int(binary.BigEndian.Uint16(msg[offset:]) & 0x3FFF)
+			jumps++
+		default:
+			if offset+1+length > len(msg) {
+				return "", 0, errors.New("Label außerhalb der Nachricht")
+			}
+			labels = append(labels, string(msg[offset+1:offset+1+length]))
+			offset += 1 + length
+		}
+	}
+	return "", 0, errors.New("zu viele Verweise")
+}
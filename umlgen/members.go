@@ -0,0 +1,68 @@
+package umlgen
+
+import (
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// Werte für --member-order
+const (
+	MemberOrderDecl       = "decl"       // Reihenfolge wie im Quelltext
+	MemberOrderAlpha      = "alpha"      // Alphabetisch
+	MemberOrderVisibility = "visibility" // Exportierte vor nicht exportierten, sonst wie im Quelltext
+)
+
+// memberLess vergleicht zwei Mitgliedsnamen gemäß --member-order
+func memberLess(order, a, b string) bool {
+	switch order {
+	case MemberOrderAlpha:
+		if la, lb := strings.ToLower(a), strings.ToLower(b); la != lb {
+			return la < lb
+		}
+		return a < b
+	case MemberOrderVisibility:
+		return token.IsExported(a) && !token.IsExported(b)
+	}
+	return false
+}
+
+// sortMethods ordnet Methoden stabil gemäß --member-order
+func sortMethods(order string, methods []MethodInfo) {
+	sort.SliceStable(methods, func(i, j int) bool { return memberLess(order, methods[i].Name, methods[j].Name) })
+}
+
+// orderMembers ordnet Felder und Methoden aller Typen gemäß --member-order.
+// Bei "decl" bleibt die Reihenfolge des Quelltexts erhalten.
+func (g *UMLGenerator) orderMembers() {
+	order := g.options.MemberOrder
+	if order == "" || order == MemberOrderDecl {
+		return
+	}
+	for _, structInfo := range g.structs {
+		sort.SliceStable(structInfo.Fields, func(i, j int) bool {
+			return memberLess(order, structInfo.Fields[i].Name, structInfo.Fields[j].Name)
+		})
+		for _, promoted := range structInfo.Promoted {
+			sort.SliceStable(promoted.Fields, func(i, j int) bool {
+				return memberLess(order, promoted.Fields[i].Name, promoted.Fields[j].Name)
+			})
+		}
+		sortMethods(order, structInfo.Methods)
+		sortMethods(order, structInfo.Constructors)
+		for _, group := range structInfo.MethodGroups {
+			sortMethods(order, group.Methods)
+		}
+	}
+	for _, interfaceInfo := range g.interfaces {
+		sortMethods(order, interfaceInfo.Methods)
+		sortMethods(order, interfaceInfo.Inherited)
+	}
+	for _, namedInfo := range g.namedTypes {
+		sortMethods(order, namedInfo.Methods)
+		sortMethods(order, namedInfo.Constructors)
+	}
+	for _, functions := range g.functions {
+		sortMethods(order, functions)
+	}
+}
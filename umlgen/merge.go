@@ -0,0 +1,229 @@
+package umlgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// WarningMerge kennzeichnet Typen aus --merge, die nicht übernommen werden konnten
+const WarningMerge = "merge"
+
+// derivedRelationKinds sind Beziehungsarten, die identifyRelations aus
+// Feldern, Einbettungen und Methodenmengen neu ableitet. Alle übrigen
+// (uses, casts, creates, spawns, ...) stammen aus Funktionsrümpfen und
+// werden beim Zusammenführen aus dem Modell übernommen.
+var derivedRelationKinds = map[string]bool{
+	"extends":     true,
+	"implements":  true,
+	"aggregation": true,
+	"composition": true,
+	"association": true,
+	"alias":       true,
+}
+
+// loadModel liest ein mit --emit json geschriebenes Modell
+func loadModel(path string) (*ModelDocument, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Lesen des Modells: %v", err)
+	}
+	var doc ModelDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("Fehler im Modell %s: %v", path, err)
+	}
+	if doc.Version < 1 || doc.Version > modelSchemaVersion {
+		return nil, fmt.Errorf("Modell %s hat Schemaversion %d, unterstützt wird bis %d", path, doc.Version, modelSchemaVersion)
+	}
+	return &doc, nil
+}
+
+// mergeModels führt die Modelle aus --merge mit dem geparsten Modell zusammen,
+// z.B. Teilmodelle mehrerer CI-Jobs für verschiedene Module. Ein Typ, der
+// schon mit gleichem Paket und Namen im Modell steht, wird nicht doppelt
+// übernommen; gleichnamige Typen anderer Pakete werden mit dem Paketnamen
+// qualifiziert. Danach werden modulübergreifende Verweise wie billing.Invoice
+// auf die Typen der anderen Module aufgelöst, so dass identifyRelations die
+// Beziehungen zwischen den Modulen findet.
+func (g *UMLGenerator) mergeModels(paths string) error {
+	for _, path := range strings.Split(paths, ",") {
+		doc, err := loadModel(strings.TrimSpace(path))
+		if err != nil {
+			return err
+		}
+		g.mergeModel(doc)
+	}
+	g.resolveQualifiedTargets()
+	return nil
+}
+
+// mergeModel übernimmt Typen, Rumpf-Beziehungen und Warnungen eines Modells
+func (g *UMLGenerator) mergeModel(doc *ModelDocument) {
+	keys := make(map[string]string) // ID im Modell -> Modellschlüssel
+	added := make(map[string]bool)
+	for _, modelType := range doc.Types {
+		key, ok := g.mergedTypeKey(modelType)
+		keys[modelType.ID] = key
+		if !ok {
+			continue
+		}
+		added[key] = true
+		g.addModelType(key, modelType)
+	}
+
+	// Verweise innerhalb des Modells auf die neuen Schlüssel umstellen
+	rekey := func(target string) string {
+		if key, ok := keys[target]; ok {
+			return key
+		}
+		return target
+	}
+	for key := range added {
+		if structInfo, ok := g.structs[key]; ok {
+			for i := range structInfo.Fields {
+				structInfo.Fields[i].Target = rekey(structInfo.Fields[i].Target)
+				structInfo.Fields[i].MapKey = rekey(structInfo.Fields[i].MapKey)
+			}
+		}
+		if interfaceInfo, ok := g.interfaces[key]; ok {
+			for i := range interfaceInfo.Embeds {
+				interfaceInfo.Embeds[i] = rekey(interfaceInfo.Embeds[i])
+			}
+		}
+		if namedInfo, ok := g.namedTypes[key]; ok {
+			namedInfo.Target = rekey(namedInfo.Target)
+		}
+	}
+
+	for _, relation := range doc.Relations {
+		if derivedRelationKinds[relation.Kind] || !added[keys[relation.From]] {
+			continue
+		}
+		g.relations = append(g.relations, Relation{From: rekey(relation.From), To: rekey(relation.To), Type: relation.Kind,
+			Cardinality: relation.Cardinality, Label: relation.Label, Pos: relation.Pos, Weight: relation.Weight})
+	}
+
+	// Unbekannte Typen und Namensgleichheiten werden im Gesamtmodell neu geprüft
+	for _, warning := range doc.Warnings {
+		if warning.Kind != WarningUnresolvedType && warning.Kind != WarningNameOnlyMatch {
+			g.warnings = append(g.warnings, warning)
+		}
+	}
+}
+
+// mergedTypeKey wählt den Modellschlüssel eines übernommenen Typs. false
+// bedeutet, dass derselbe Typ (gleiches Paket und gleicher Name) schon im
+// Modell steht oder der Schlüssel auch qualifiziert vergeben ist.
+func (g *UMLGenerator) mergedTypeKey(modelType ModelType) (string, bool) {
+	for _, key := range []string{modelType.ID, modelType.Package + "." + modelType.Name} {
+		pkg, known := g.typePackage(key)
+		if !known {
+			return key, true
+		}
+		if pkg == modelType.Package && bareTypeName(key) == modelType.Name {
+			return key, false
+		}
+	}
+	g.warn(WarningMerge, modelType.Pos, "Typ %s aus --merge nicht übernommen: Name %s.%s ist bereits vergeben",
+		modelType.ID, modelType.Package, modelType.Name)
+	return modelType.ID, false
+}
+
+// addModelType trägt einen Typ aus einem Modell unter key ein
+func (g *UMLGenerator) addModelType(key string, modelType ModelType) {
+	var typeParams []TypeParamInfo
+	for _, param := range modelType.TypeParams {
+		typeParams = append(typeParams, TypeParamInfo{Name: param.Name, Constraint: param.Constraint})
+	}
+	methods := g.methodsFromModel(modelType.Methods)
+	constructors := g.methodsFromModel(modelType.Constructors)
+
+	switch modelType.Kind {
+	case "struct":
+		structInfo := &StructInfo{Name: key, Package: modelType.Package, Pos: modelType.Pos, TypeParams: typeParams,
+			Doc: modelType.Doc, Fields: []FieldInfo{}, Methods: methods, Constructors: constructors,
+			Stereotypes: modelType.Stereotypes}
+		for _, field := range modelType.Fields {
+			if field.Promoted {
+				continue // entsteht mit --expand-embedded neu
+			}
+			tag := reflect.StructTag(field.Tag)
+			structInfo.Fields = append(structInfo.Fields, FieldInfo{Name: field.Name, Type: field.Type, Pos: field.Pos,
+				Target: field.Target, Multiple: field.Multiple, ChanDir: field.ChanDir, IsMap: field.Map, MapKey: field.MapKey,
+				Tag: tag, Shown: shownTags(tag, g.options.ShowTags), Doc: field.Doc, Default: field.Default,
+				Embedded: field.Embedded})
+		}
+		g.structs[key] = structInfo
+	case "interface":
+		g.interfaces[key] = &InterfaceInfo{Name: key, Package: modelType.Package, Pos: modelType.Pos, TypeParams: typeParams,
+			Doc: modelType.Doc, Methods: methods, Embeds: append([]string(nil), modelType.Embeds...),
+			TypeSet: modelType.TypeSet, Stereotypes: modelType.Stereotypes}
+	default:
+		g.namedTypes[key] = &NamedTypeInfo{Name: key, Package: modelType.Package, Pos: modelType.Pos,
+			Underlying: modelType.Underlying, TypeParams: typeParams, Doc: modelType.Doc, Alias: modelType.Kind == "alias",
+			EnumValues: modelType.EnumValues, Stereotypes: modelType.Stereotypes, Target: modelType.Target,
+			Multiple: modelType.Multiple, Methods: methods, Constructors: constructors}
+	}
+}
+
+// methodsFromModel überträgt Methoden aus einem Modell zurück
+func (g *UMLGenerator) methodsFromModel(methods []ModelMethod) []MethodInfo {
+	out := []MethodInfo{}
+	for _, method := range methods {
+		methodInfo := MethodInfo{Name: method.Name, Pos: method.Pos, Parameters: []ParameterInfo{},
+			Pointer: method.Pointer, Doc: method.Doc, Generated: method.Generated}
+		for _, param := range method.Parameters {
+			methodInfo.Parameters = append(methodInfo.Parameters, ParameterInfo{Name: param.Name, Type: param.Type})
+		}
+		for _, result := range method.Results {
+			methodInfo.Results = append(methodInfo.Results, ParameterInfo{Name: result.Name, Type: result.Type})
+		}
+		if len(methodInfo.Results) > 0 {
+			methodInfo.ReturnType = formatResults(methodInfo.Results)
+		}
+		if methodInfo.Pointer {
+			methodInfo.Marker = pointerReceiverMarkers[g.options.PointerReceivers]
+		}
+		out = append(out, methodInfo)
+	}
+	return out
+}
+
+// resolveQualifiedTargets löst Verweise wie billing.Invoice, deren Paket
+// beim Parsen nicht analysiert war, auf den Typ Invoice des Pakets billing
+// im zusammengeführten Modell auf
+func (g *UMLGenerator) resolveQualifiedTargets() {
+	qualified := make(map[string]string) // paket.Name -> Modellschlüssel
+	for _, typeInfo := range g.Types() {
+		name := typeInfo.Package + "." + bareTypeName(typeInfo.Name)
+		if _, ok := qualified[name]; !ok {
+			qualified[name] = typeInfo.Name
+		}
+	}
+	resolve := func(target string) string {
+		if !strings.Contains(target, ".") || g.isKnownType(target) {
+			return target
+		}
+		if key, ok := qualified[target]; ok {
+			return key
+		}
+		return target
+	}
+
+	for _, structInfo := range g.structs {
+		for i := range structInfo.Fields {
+			structInfo.Fields[i].Target = resolve(structInfo.Fields[i].Target)
+			structInfo.Fields[i].MapKey = resolve(structInfo.Fields[i].MapKey)
+		}
+	}
+	for _, interfaceInfo := range g.interfaces {
+		for i := range interfaceInfo.Embeds {
+			interfaceInfo.Embeds[i] = resolve(interfaceInfo.Embeds[i])
+		}
+	}
+	for _, namedInfo := range g.namedTypes {
+		namedInfo.Target = resolve(namedInfo.Target)
+	}
+}
@@ -0,0 +1,120 @@
+package umlgen
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMergeModels(t *testing.T) {
+	authFiles := map[string]string{"auth/user.go": `package auth
+
+type Role struct{}
+
+type User struct {
+	Role Role
+}
+
+func NewUser() *User {
+	return &User{}
+}
+`}
+
+	tests := []struct {
+		name      string
+		merged    map[string]string // Modul, dessen Modell mit --merge übernommen wird
+		files     map[string]string // Geparstes Modul
+		wantTypes []string
+		wantEdges []string
+		wantErr   bool
+	}{
+		{
+			name:   "Verweise zwischen den Modulen werden aufgelöst",
+			merged: authFiles,
+			files: map[string]string{"billing/invoice.go": `package billing
+
+import "example.com/auth"
+
+type Invoice struct {
+	Owner *auth.User
+}
+`},
+			wantTypes: []string{"Invoice", "Role", "User"},
+			wantEdges: []string{"Invoice composition User", "User aggregation Role"},
+		},
+		{
+			name:      "derselbe Typ wird nicht doppelt übernommen",
+			merged:    authFiles,
+			files:     authFiles,
+			wantTypes: []string{"Role", "User"},
+			wantEdges: []string{"User aggregation Role"},
+		},
+		{
+			name:   "gleichnamiger Typ eines anderen Pakets wird qualifiziert",
+			merged: authFiles,
+			files: map[string]string{"billing/user.go": `package billing
+
+type User struct {
+	Total int
+}
+`},
+			wantTypes: []string{"Role", "User", "auth.User"},
+			wantEdges: []string{"auth.User aggregation Role"},
+		},
+		{
+			name: "Beziehungen aus Funktionsrümpfen bleiben erhalten",
+			merged: map[string]string{"auth/user.go": `package auth
+
+type User struct{}
+
+type Session struct{}
+
+func (s *Session) Refresh() {
+	_ = User{}
+}
+`},
+			files:     map[string]string{"billing/invoice.go": "package billing\n\ntype Invoice struct{}\n"},
+			wantTypes: []string{"Invoice", "Session", "User"},
+			wantEdges: []string{"Session uses User"},
+		},
+		{
+			name:    "fehlendes Modell",
+			files:   map[string]string{"billing/invoice.go": "package billing\n\ntype Invoice struct{}\n"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modelDir := t.TempDir()
+			if tt.merged != nil {
+				mergedDir := t.TempDir()
+				writeTree(t, mergedDir, tt.merged)
+				other := NewUMLGenerator(Options{AnalyzeBodies: true})
+				if err := other.GenerateUMLFromDirectory(mergedDir); err != nil {
+					t.Fatal(err)
+				}
+				if err := other.WriteModel(modelDir); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			dir := t.TempDir()
+			writeTree(t, dir, tt.files)
+			g := NewUMLGenerator(Options{Merge: filepath.Join(modelDir, modelFileName)})
+			err := g.GenerateUMLFromDirectory(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fehler = %v, erwartet Fehler: %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := typeNames(g); !slices.Equal(got, tt.wantTypes) {
+				t.Errorf("Typen = %v, erwartet %v", got, tt.wantTypes)
+			}
+			if got := relationEdges(g); !slices.Equal(got, tt.wantEdges) {
+				t.Errorf("Beziehungen = %q, erwartet %q", got, tt.wantEdges)
+			}
+		})
+	}
+}
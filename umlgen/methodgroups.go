@@ -0,0 +1,95 @@
+package umlgen
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MethodGroup sind die Methoden eines Typs, die ein Interface erfüllen
+type MethodGroup struct {
+	Interface string
+	Methods   []MethodInfo
+}
+
+// groupMethodsByInterface teilt die Methoden jeder Struct und jedes
+// benannten Typs nach den implementierten Interfaces auf (--group-methods). Erfüllt eine Methode
+// mehrere Interfaces, erscheint sie nur beim kleinsten; Interfaces, deren
+// Methoden schon vollständig vergeben sind, erhalten keinen Abschnitt.
+func (g *UMLGenerator) groupMethodsByInterface() {
+	implemented := make(map[string][]string)
+	for _, relation := range g.relations {
+		if relation.Type == "implements" {
+			implemented[relation.From] = appendUnique(implemented[relation.From], relation.To)
+		}
+	}
+
+	for _, name := range sortedMapKeys(implemented) {
+		if structInfo, ok := g.structs[name]; ok {
+			structInfo.MethodGroups = g.methodGroups(structInfo.Methods, implemented[name])
+		} else if namedInfo, ok := g.namedTypes[name]; ok {
+			namedInfo.MethodGroups = g.methodGroups(namedInfo.Methods, implemented[name])
+		}
+	}
+}
+
+// methodGroups ordnet Methoden den Interfaces zu, die ihr Typ implementiert
+func (g *UMLGenerator) methodGroups(methods []MethodInfo, interfaces []string) []MethodGroup {
+	// Kleinere Interfaces zuerst, damit Methoden bei Reader statt beim
+	// umfassenden ReadCloser landen
+	sort.Slice(interfaces, func(i, j int) bool {
+		si, _ := g.interfaceMethodSet(interfaces[i])
+		sj, _ := g.interfaceMethodSet(interfaces[j])
+		if len(si) != len(sj) {
+			return len(si) < len(sj)
+		}
+		return interfaces[i] < interfaces[j]
+	})
+
+	var groups []MethodGroup
+	grouped := make(map[string]bool)
+	for _, interfaceName := range interfaces {
+		required, _ := g.interfaceMethodSet(interfaceName)
+		group := MethodGroup{Interface: interfaceName}
+		for _, method := range methods {
+			if !grouped[method.Name] && hasMethod(required, method.Name) {
+				grouped[method.Name] = true
+				group.Methods = append(group.Methods, method)
+			}
+		}
+		if len(group.Methods) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// groupedMethod prüft, ob eine Methode einem Interface-Abschnitt zugeordnet ist
+func groupedMethod(groups []MethodGroup, name string) bool {
+	for _, group := range groups {
+		if hasMethod(group.Methods, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeMethodsPlantUML schreibt die Methoden eines Typs, mit --group-methods
+// je implementiertem Interface ein Abschnitt und danach die übrigen
+func writeMethodsPlantUML(out *plantUMLWriter, methods []MethodInfo, groups []MethodGroup, typeParams []TypeParamInfo) {
+	for _, group := range groups {
+		out.WriteString(fmt.Sprintf("    .. %s ..\n", group.Interface))
+		for _, method := range group.Methods {
+			out.WriteString(formatMethodPlantUML(renameTypeParams(method, typeParams)))
+		}
+	}
+	header := ""
+	if len(groups) > 0 {
+		header = "    .. eigene Methoden ..\n"
+	}
+	for _, method := range methods {
+		if !groupedMethod(groups, method.Name) {
+			out.WriteString(header + formatMethodPlantUML(renameTypeParams(method, typeParams)))
+			header = ""
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package umlgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PackageMetrics enthält die Stabilitätsmetriken nach Robert C. Martin für
+// ein Paket, abgeleitet aus den Beziehungen zwischen Typen
+type PackageMetrics struct {
+	Package      string  `json:"package"`
+	Types        int     `json:"types"`        // Structs, Interfaces und benannte Typen
+	Abstract     int     `json:"abstract"`     // Interfaces
+	Afferent     int     `json:"ca"`           // Typen anderer Pakete, die von diesem Paket abhängen
+	Efferent     int     `json:"ce"`           // Typen dieses Pakets, die von anderen Paketen abhängen
+	Instability  float64 `json:"instability"`  // I = Ce / (Ca + Ce)
+	Abstractness float64 `json:"abstractness"` // A = Interfaces / Typen
+	Distance     float64 `json:"distance"`     // D = |A + I - 1|, Abstand zur Hauptreihe
+}
+
+// PackageMetrics berechnet Instabilität, Abstraktheit und den Abstand zur
+// Hauptreihe für jedes Paket mit Typen, sortiert nach absteigendem Abstand
+func (g *UMLGenerator) PackageMetrics() []PackageMetrics {
+	metrics := make(map[string]*PackageMetrics)
+	count := func(name string, abstract bool) {
+		pkg, _ := g.typePackage(name)
+		if metrics[pkg] == nil {
+			metrics[pkg] = &PackageMetrics{Package: pkg}
+		}
+		metrics[pkg].Types++
+		if abstract {
+			metrics[pkg].Abstract++
+		}
+	}
+	for name := range g.structs {
+		count(name, false)
+	}
+	for name := range g.interfaces {
+		count(name, true)
+	}
+	for name, namedInfo := range g.namedTypes {
+		if !namedInfo.Alias {
+			count(name, false)
+		}
+	}
+
+	afferent := make(map[string]map[string]bool) // Paket -> abhängige Typen anderer Pakete
+	efferent := make(map[string]map[string]bool) // Paket -> eigene Typen mit Abhängigkeiten nach außen
+	for _, relation := range g.relations {
+		if !g.isKnownType(relation.From) || !g.isKnownType(relation.To) {
+			continue
+		}
+		fromPkg, _ := g.typePackage(relation.From)
+		toPkg, _ := g.typePackage(relation.To)
+		if fromPkg == toPkg || metrics[fromPkg] == nil || metrics[toPkg] == nil {
+			continue
+		}
+		if afferent[toPkg] == nil {
+			afferent[toPkg] = make(map[string]bool)
+		}
+		afferent[toPkg][relation.From] = true
+		if efferent[fromPkg] == nil {
+			efferent[fromPkg] = make(map[string]bool)
+		}
+		efferent[fromPkg][relation.From] = true
+	}
+
+	var result []PackageMetrics
+	for _, pkg := range sortedMapKeys(metrics) {
+		m := metrics[pkg]
+		m.Afferent, m.Efferent = len(afferent[pkg]), len(efferent[pkg])
+		if m.Afferent+m.Efferent > 0 {
+			m.Instability = float64(m.Efferent) / float64(m.Afferent+m.Efferent)
+		}
+		m.Abstractness = float64(m.Abstract) / float64(m.Types)
+		m.Distance = math.Abs(m.Abstractness + m.Instability - 1)
+		result = append(result, *m)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Distance > result[j].Distance })
+	return result
+}
+
+// mainSequenceZone ordnet ein Paket fern der Hauptreihe einer der beiden
+// Problemzonen zu
+func mainSequenceZone(m PackageMetrics) string {
+	switch {
+	case m.Distance < 0.5:
+		return ""
+	case m.Abstractness+m.Instability < 1:
+		return "Zone des Schmerzes"
+	default:
+		return "Zone der Nutzlosigkeit"
+	}
+}
+
+// printPackageMetrics gibt die Paketmetriken im gewählten Format (--metrics) aus
+func printPackageMetrics(metrics []PackageMetrics, format string) {
+	switch format {
+	case "text":
+		fmt.Println("Paketmetriken (Abstand zur Hauptreihe):")
+		fmt.Printf("  %-20s %5s %5s %5s %5s %5s\n", "Paket", "Ca", "Ce", "I", "A", "D")
+		for _, m := range metrics {
+			line := fmt.Sprintf("  %-20s %5d %5d %5.2f %5.2f %5.2f", m.Package, m.Afferent, m.Efferent, m.Instability, m.Abstractness, m.Distance)
+			if zone := mainSequenceZone(m); zone != "" {
+				line += "  " + zone
+			}
+			fmt.Println(line)
+		}
+	case "json":
+		data, err := json.MarshalIndent(metrics, "", "  ")
+		if err != nil {
+			fmt.Printf("Fehler beim Erzeugen der Paketmetriken: %v\n", err)
+			return
+		}
+		fmt.Println(string(data))
+	}
+}
+
+// Abmessungen des Hauptreihen-Diagramms
+const (
+	metricsPlotSize   = 400
+	metricsPlotMargin = 50
+)
+
+// mainSequenceSVG zeichnet die Pakete als Streudiagramm mit Instabilität
+// (x) und Abstraktheit (y) und der Hauptreihe A + I = 1 als Diagonale
+func mainSequenceSVG(metrics []PackageMetrics) string {
+	size, margin := metricsPlotSize, metricsPlotMargin
+	x := func(instability float64) int { return margin + int(instability*float64(size)) }
+	y := func(abstractness float64) int { return margin + size - int(abstractness*float64(size)) }
+
+	var sb strings.Builder
+	total := size + 2*margin
+	sb.WriteString(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+"\n", total, total))
+	sb.WriteString("<title>Abstand zur Hauptreihe</title>\n")
+	sb.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" fill="#fff" stroke="#555"/>`+"\n", margin, margin, size, size))
+	sb.WriteString(fmt.Sprintf(`<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-dasharray="6,4"/>`+"\n", x(0), y(1), x(1), y(0), badgeBlue))
+	sb.WriteString(fmt.Sprintf(`<text x="%d" y="%d" fill="#999">Zone des Schmerzes</text>`+"\n", x(0)+6, y(0)-6))
+	sb.WriteString(fmt.Sprintf(`<text x="%d" y="%d" fill="#999" text-anchor="end">Zone der Nutzlosigkeit</text>`+"\n", x(1)-6, y(1)+16))
+	sb.WriteString(fmt.Sprintf(`<text x="%d" y="%d" text-anchor="middle">Instabilität I</text>`+"\n", margin+size/2, total-15))
+	sb.WriteString(fmt.Sprintf(`<text x="15" y="%d" text-anchor="middle" transform="rotate(-90 15 %d)">Abstraktheit A</text>`+"\n", margin+size/2, margin+size/2))
+	for _, tick := range []float64{0, 0.5, 1} {
+		sb.WriteString(fmt.Sprintf(`<text x="%d" y="%d" text-anchor="middle">%.1f</text>`+"\n", x(tick), y(0)+15, tick))
+		sb.WriteString(fmt.Sprintf(`<text x="%d" y="%d" text-anchor="end">%.1f</text>`+"\n", x(0)-5, y(tick)+4, tick))
+	}
+
+	for _, m := range metrics {
+		color := badgeGreen
+		if m.Distance >= 0.5 {
+			color = "#e05d44"
+		} else if m.Distance >= 0.25 {
+			color = badgeYellow
+		}
+		name := html.EscapeString(m.Package)
+		sb.WriteString(fmt.Sprintf(`<circle cx="%d" cy="%d" r="5" fill="%s"><title>%s: I=%.2f A=%.2f D=%.2f</title></circle>`+"\n",
+			x(m.Instability), y(m.Abstractness), color, name, m.Instability, m.Abstractness, m.Distance))
+		sb.WriteString(fmt.Sprintf(`<text x="%d" y="%d">%s</text>`+"\n", x(m.Instability)+8, y(m.Abstractness)-6, name))
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+// GenerateMainSequencePlot schreibt das Hauptreihen-Diagramm als
+// main_sequence.svg ins Ausgabeverzeichnis
+func (g *UMLGenerator) GenerateMainSequencePlot(outputDir string) error {
+	path := filepath.Join(outputDir, "main_sequence.svg")
+	if err := writeFileFrom(path, strings.NewReader(mainSequenceSVG(g.PackageMetrics())), g.fileMode()); err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Hauptreihen-Diagramms: %v", err)
+	}
+	if err := g.recordArtifact(outputDir, path, "svg", ""); err != nil {
+		return err
+	}
+	fmt.Printf("Hauptreihen-Diagramm erstellt: %s\n", path)
+	return nil
+}
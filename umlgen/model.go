@@ -0,0 +1,206 @@
+package umlgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// modelFileName ist der Name des exportierten Modells im Ausgabeverzeichnis
+const modelFileName = "uml_model.json"
+
+// modelSchemaVersion ist die Version des JSON-Schemas (--emit json). Sie wird
+// nur erhöht, wenn sich Bedeutung oder Name eines Felds ändern; neue
+// optionale Felder lassen sie unverändert.
+const modelSchemaVersion = 1
+
+// ModelDocument ist das Modell als stabile Zwischendarstellung, mit der
+// eigene Skripte weiterarbeiten können, ohne Go neu zu parsen:
+//
+//	{"version": 1, "generated": "...",
+//	 "types": [{"id": "auth.User", "kind": "struct", "name": "User", "package": "auth",
+//	            "pos": {"file": "auth/user.go", "line": 12},
+//	            "fields": [{"name": "ID", "type": "int"}],
+//	            "methods": [{"name": "Valid", "results": [{"type": "bool"}]}]}],
+//	 "relations": [{"from": "auth.User", "to": "auth.Role", "kind": "aggregation", "weight": 1}],
+//	 "warnings": []}
+type ModelDocument struct {
+	Version   int             `json:"version"`
+	Generated time.Time       `json:"generated"`
+	Types     []ModelType     `json:"types"`
+	Relations []ModelRelation `json:"relations"`
+	Warnings  []Warning       `json:"warnings"`
+}
+
+// ModelType ist eine Struct, ein Interface oder ein benannter Typ. ID ist der
+// Schlüssel, auf den sich Beziehungen beziehen.
+type ModelType struct {
+	ID           string           `json:"id"`
+	Kind         string           `json:"kind"` // "struct", "interface", "type" oder "alias"
+	Name         string           `json:"name"`
+	Package      string           `json:"package"`
+	Pos          Position         `json:"pos"`
+	TypeParams   []ModelTypeParam `json:"typeParams,omitempty"`
+	Underlying   string           `json:"underlying,omitempty"` // Nur bei "type" und "alias"
+	Target       string           `json:"target,omitempty"`     // Benannter Elementtyp des zugrundeliegenden Typs
+	Multiple     bool             `json:"multiple,omitempty"`   // Zugrundeliegender Typ ist ein Container
+	Doc          string           `json:"doc,omitempty"`
+	Stereotypes  []string         `json:"stereotypes,omitempty"`
+	Embeds       []string         `json:"embeds,omitempty"`  // Eingebettete Interfaces, auch fremde wie io.Reader
+	TypeSet      []string         `json:"typeSet,omitempty"` // Typmengen eines Constraints, z.B. "~int | ~float64"
+	EnumValues   []string         `json:"enumValues,omitempty"`
+	Fields       []ModelField     `json:"fields,omitempty"`
+	Methods      []ModelMethod    `json:"methods,omitempty"`
+	Constructors []ModelMethod    `json:"constructors,omitempty"`
+}
+
+// ModelTypeParam ist ein Typparameter samt Constraint
+type ModelTypeParam struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint"`
+}
+
+// ModelField ist ein Feld einer Struct. Target ist der benannte Elementtyp
+// nach Entpacken von Pointern und Containern, so wie er für Beziehungen
+// verwendet wird.
+type ModelField struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Pos      Position `json:"pos"`
+	Target   string   `json:"target,omitempty"`
+	Multiple bool     `json:"multiple,omitempty"`
+	ChanDir  string   `json:"chanDir,omitempty"` // Bei Channels: "both", "send" oder "recv"
+	Map      bool     `json:"map,omitempty"`     // Target ist dann der Werttyp
+	MapKey   string   `json:"mapKey,omitempty"`  // Benannter Schlüsseltyp einer Map
+	Tag      string   `json:"tag,omitempty"`
+	Doc      string   `json:"doc,omitempty"`
+	Default  string   `json:"default,omitempty"`  // Standardwert aus dem Konstruktor (--field-defaults)
+	Promoted bool     `json:"promoted,omitempty"` // Aus einem eingebetteten Typ übernommen (--expand-embedded)
+	Embedded bool     `json:"embedded,omitempty"` // Eingebettetes Feld, Name ist dann der Typ
+}
+
+// ModelMethod ist eine Methode, Interface-Methode oder ein Konstruktor
+type ModelMethod struct {
+	Name       string       `json:"name"`
+	Pos        Position     `json:"pos"`
+	Parameters []ModelParam `json:"parameters,omitempty"`
+	Results    []ModelParam `json:"results,omitempty"`
+	Pointer    bool         `json:"pointer,omitempty"` // Pointer-Receiver
+	Doc        string       `json:"doc,omitempty"`
+	Generated  bool         `json:"generated,omitempty"`
+}
+
+// ModelParam ist ein Parameter oder Rückgabewert, Name ist bei unbenannten leer
+type ModelParam struct {
+	Name string `json:"name,omitempty"`
+	Type string `json:"type"`
+}
+
+// ModelRelation ist eine Beziehung zwischen zwei Typen (IDs)
+type ModelRelation struct {
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	Kind        string   `json:"kind"` // wie Relation.Type, z.B. "implements" oder "aggregation"
+	Cardinality string   `json:"cardinality,omitempty"`
+	Label       string   `json:"label,omitempty"`
+	Weight      int      `json:"weight"`
+	Pos         Position `json:"pos"`
+}
+
+// Model liefert das aktuelle Modell als ModelDocument
+func (g *UMLGenerator) Model() ModelDocument {
+	doc := ModelDocument{
+		Version:   modelSchemaVersion,
+		Generated: time.Now().UTC(),
+		Types:     []ModelType{},
+		Relations: []ModelRelation{},
+		Warnings:  []Warning{},
+	}
+
+	for _, name := range sortedMapKeys(g.structs) {
+		s := g.structs[name]
+		modelType := ModelType{ID: name, Kind: "struct", Name: bareTypeName(name), Package: s.Package, Pos: s.Pos,
+			TypeParams: modelTypeParams(s.TypeParams), Doc: s.Doc, Stereotypes: s.Stereotypes,
+			Methods: modelMethods(s.Methods), Constructors: modelMethods(s.Constructors)}
+		for _, field := range s.Fields {
+			modelType.Fields = append(modelType.Fields, ModelField{Name: field.Name, Type: field.Type, Pos: field.Pos,
+				Target: field.Target, Multiple: field.Multiple, ChanDir: field.ChanDir, Map: field.IsMap, MapKey: field.MapKey,
+				Tag: string(field.Tag), Doc: field.Doc, Default: field.Default,
+				Promoted: field.Marker == promotedMarker, Embedded: field.Embedded})
+		}
+		doc.Types = append(doc.Types, modelType)
+	}
+	for _, name := range sortedMapKeys(g.interfaces) {
+		i := g.interfaces[name]
+		doc.Types = append(doc.Types, ModelType{ID: name, Kind: "interface", Name: bareTypeName(name), Package: i.Package, Pos: i.Pos,
+			TypeParams: modelTypeParams(i.TypeParams), Doc: i.Doc, Stereotypes: i.Stereotypes,
+			Embeds: append(append([]string(nil), i.Embeds...), i.Foreign...), TypeSet: i.TypeSet, Methods: modelMethods(i.Methods)})
+	}
+	for _, name := range sortedMapKeys(g.namedTypes) {
+		n := g.namedTypes[name]
+		kind := "type"
+		if n.Alias {
+			kind = "alias"
+		}
+		doc.Types = append(doc.Types, ModelType{ID: name, Kind: kind, Name: bareTypeName(name), Package: n.Package, Pos: n.Pos,
+			TypeParams: modelTypeParams(n.TypeParams), Underlying: n.Underlying, Target: n.Target, Multiple: n.Multiple,
+			Doc: n.Doc, Stereotypes: n.Stereotypes,
+			EnumValues: n.EnumValues, Methods: modelMethods(n.Methods), Constructors: modelMethods(n.Constructors)})
+	}
+
+	for _, relation := range g.relations {
+		doc.Relations = append(doc.Relations, ModelRelation{From: relation.From, To: relation.To, Kind: relation.Type,
+			Cardinality: relation.Cardinality, Label: relation.Label, Weight: relation.weight(), Pos: relation.Pos})
+	}
+	doc.Warnings = append(doc.Warnings, g.warnings...)
+	return doc
+}
+
+// modelTypeParams überträgt Typparameter ins Schema
+func modelTypeParams(params []TypeParamInfo) []ModelTypeParam {
+	var out []ModelTypeParam
+	for _, param := range params {
+		out = append(out, ModelTypeParam{Name: param.Name, Constraint: param.Constraint})
+	}
+	return out
+}
+
+// modelMethods überträgt Methoden ins Schema
+func modelMethods(methods []MethodInfo) []ModelMethod {
+	var out []ModelMethod
+	for _, method := range methods {
+		out = append(out, ModelMethod{Name: method.Name, Pos: method.Pos, Parameters: modelParams(method.Parameters),
+			Results: modelParams(method.Results), Pointer: method.Pointer, Doc: method.Doc, Generated: method.Generated})
+	}
+	return out
+}
+
+// modelParams überträgt Parameter oder Rückgabewerte ins Schema
+func modelParams(params []ParameterInfo) []ModelParam {
+	var out []ModelParam
+	for _, param := range params {
+		out = append(out, ModelParam{Name: param.Name, Type: param.Type})
+	}
+	return out
+}
+
+// WriteModel schreibt das Modell als uml_model.json ins Ausgabeverzeichnis
+// (--emit json)
+func (g *UMLGenerator) WriteModel(outputDir string) error {
+	// Ohne HTML-Escaping bleiben Typen wie chan<- int lesbar
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(g.Model()); err != nil {
+		return err
+	}
+	path := filepath.Join(outputDir, modelFileName)
+	if err := writeFileFrom(path, &data, g.fileMode()); err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Modells: %v", err)
+	}
+	fmt.Printf("Modell erstellt: %s\n", path)
+	return g.recordArtifact(outputDir, path, "json", "")
+}
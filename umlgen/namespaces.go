@@ -0,0 +1,235 @@
+package umlgen
+
+import (
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// fileScope kennzeichnet das Paket einer Datei über Verzeichnis und
+// Paketnamen. Externe Testpakete (pkg_test) bilden ein eigenes Paket.
+func (g *UMLGenerator) fileScope(node *ast.File) string {
+	return filepath.Dir(g.fset.Position(node.Package).Filename) + ":" + node.Name.Name
+}
+
+// collectTypeKeys legt die Modellschlüssel aller Typdeklarationen fest.
+// Typnamen, die nur in einem Paket vorkommen, bleiben unverändert; kommt ein
+// Name in mehreren Paketen vor, wird er mit dem Paketnamen qualifiziert
+// (auth.User, billing.User). Tragen auch die Pakete denselben Namen, dient
+// der Importpfad als Qualifizierer.
+func (g *UMLGenerator) collectTypeKeys() {
+	g.typeKeys = make(map[string]map[string]string)
+	g.scopePackages = make(map[string]string)
+	g.scopePaths = make(map[string]string)
+	g.pathScopes = make(map[string]string)
+	g.typePaths = make(map[string]string)
+
+	declared := make(map[string][]string) // Typname -> Pakete
+	for _, filePath := range sortedMapKeys(g.files) {
+		file := g.files[filePath]
+		scope := g.fileScope(file)
+		if _, seen := g.scopePackages[scope]; !seen {
+			g.scopePackages[scope] = file.Name.Name
+			g.scopePaths[scope] = scopeImportPath(scope)
+			// Ein externes Testpaket teilt den Importpfad mit seinem Paket
+			if other, ok := g.pathScopes[g.scopePaths[scope]]; !ok || strings.HasSuffix(g.scopePackages[other], "_test") {
+				g.pathScopes[g.scopePaths[scope]] = scope
+			}
+		}
+		if !g.modelsFile(filePath) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				name := spec.(*ast.TypeSpec).Name.Name
+				declared[name] = appendUnique(declared[name], scope)
+			}
+		}
+	}
+
+	for name, scopes := range declared {
+		for _, scope := range scopes {
+			if g.typeKeys[scope] == nil {
+				g.typeKeys[scope] = make(map[string]string)
+			}
+			if len(scopes) == 1 {
+				g.typeKeys[scope][name] = name
+			} else {
+				g.typeKeys[scope][name] = g.scopeQualifier(scope, scopes) + "." + name
+			}
+			g.typePaths[g.typeKeys[scope][name]] = g.scopePaths[scope]
+		}
+	}
+}
+
+// scopeImportPath liefert den Importpfad eines Pakets
+func scopeImportPath(scope string) string {
+	dir, _ := splitScope(scope)
+	root, modulePath := findModule(dir)
+	return packageImportPath(root, modulePath, dir)
+}
+
+// scopeQualifier liefert den Qualifizierer eines Pakets unter den Paketen,
+// die denselben Typnamen deklarieren. Gleichnamige Pakete verschiedener
+// Services (federate) werden mit dem Servicenamen qualifiziert, sonst mit
+// dem Importpfad.
+func (g *UMLGenerator) scopeQualifier(scope string, scopes []string) string {
+	pkgName := g.scopePackages[scope]
+	service := g.scopeService(scope)
+	qualifier := pkgName
+	for _, other := range scopes {
+		if other == scope || g.scopePackages[other] != pkgName {
+			continue
+		}
+		if service == "" || g.scopeService(other) == service {
+			return strings.NewReplacer("/", ".", ":", "").Replace(g.scopePaths[scope])
+		}
+		qualifier = service + "." + pkgName
+	}
+	return qualifier
+}
+
+// scopeService liefert den Service, zu dessen Verzeichnis ein Paket gehört,
+// außerhalb von federate ""
+func (g *UMLGenerator) scopeService(scope string) string {
+	if len(g.serviceDirs) == 0 {
+		return ""
+	}
+	dir, err := filepath.Abs(scope[:strings.LastIndex(scope, ":")])
+	if err != nil {
+		return ""
+	}
+	service, longest := "", -1
+	for serviceDir, name := range g.serviceDirs {
+		rel, err := filepath.Rel(serviceDir, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(serviceDir) > longest {
+			service, longest = name, len(serviceDir)
+		}
+	}
+	return service
+}
+
+// typeKey liefert den Modellschlüssel eines im Paket scope deklarierten
+// oder referenzierten Typnamens
+func (g *UMLGenerator) typeKey(scope, name string) string {
+	if key, ok := g.typeKeys[scope][name]; ok {
+		return key
+	}
+	return name
+}
+
+// modelTarget bildet einen Elementtyp, wie er in der Datei file geschrieben
+// ist, auf seinen Modellschlüssel ab. Qualifizierte Namen wie billing.User
+// werden über die Importe der Datei dem analysierten Paket zugeordnet.
+func (g *UMLGenerator) modelTarget(file *ast.File, target string) string {
+	dot := strings.LastIndex(target, ".")
+	if dot < 0 {
+		return g.typeKey(g.fileScope(file), target)
+	}
+	if scope := g.importedScope(file, target[:dot]); scope != "" {
+		if key, ok := g.typeKeys[scope][target[dot+1:]]; ok {
+			return key
+		}
+	}
+	return target
+}
+
+// importedScope liefert das analysierte Paket, das file unter dem Namen
+// pkgName importiert, oder ""
+func (g *UMLGenerator) importedScope(file *ast.File, pkgName string) string {
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		scope, ok := g.pathScopes[importPath]
+		if !ok {
+			continue
+		}
+		name := g.scopePackages[scope]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name == pkgName {
+			return scope
+		}
+	}
+	return ""
+}
+
+// bareTypeName liefert den Typnamen ohne Qualifizierer
+func bareTypeName(key string) string {
+	return key[strings.LastIndex(key, ".")+1:]
+}
+
+// packageID kennzeichnet das Paket eines Typs oder einer Factory im
+// Diagramm: analysierte Pakete über ihren Importpfad, Typen aus Overlay und
+// --merge über den Paketnamen
+func (g *UMLGenerator) packageID(key, pkg string) string {
+	if importPath, ok := g.typePaths[key]; ok {
+		return importPath
+	}
+	return pkg
+}
+
+// modelPackages liefert die Pakete aller Typen und Funktionen des Modells in
+// sortierter Reihenfolge, jeweils mit dem Namen, unter dem sie im Diagramm
+// erscheinen. Das ist der Paketname, solange er eindeutig ist, sonst der
+// Importpfad.
+func (g *UMLGenerator) modelPackages() (ids []string, labels map[string]string) {
+	names := make(map[string]string) // ID -> Paketname
+	for key, structInfo := range g.structs {
+		names[g.packageID(key, structInfo.Package)] = structInfo.Package
+	}
+	for key, interfaceInfo := range g.interfaces {
+		names[g.packageID(key, interfaceInfo.Package)] = interfaceInfo.Package
+	}
+	for key, namedInfo := range g.namedTypes {
+		names[g.packageID(key, namedInfo.Package)] = namedInfo.Package
+	}
+	for key, factory := range g.factories {
+		names[g.packageID(key, factory.Package)] = factory.Package
+	}
+	for id := range g.functions {
+		names[id] = g.scopePackages[g.pathScopes[id]]
+	}
+
+	count := make(map[string]int)
+	for _, name := range names {
+		count[name]++
+	}
+	labels = make(map[string]string)
+	for id, name := range names {
+		labels[id] = name
+		if count[name] > 1 {
+			labels[id] = id
+		}
+	}
+	return sortedMapKeys(names), labels
+}
+
+// hasQualifiedKeys prüft, ob das Modell paketqualifizierte Schlüssel enthält
+func (g *UMLGenerator) hasQualifiedKeys() bool {
+	for _, keys := range g.typeKeys {
+		for name, key := range keys {
+			if key != name {
+				return true
+			}
+		}
+	}
+	for key, factory := range g.factories {
+		if key != factory.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+package umlgen
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// relationEdges liefert die Beziehungen als "Von Art Nach"
+func relationEdges(g *UMLGenerator) []string {
+	var edges []string
+	for _, relation := range g.relations {
+		edges = append(edges, relation.From+" "+relation.Type+" "+relation.To)
+	}
+	slices.Sort(edges)
+	return edges
+}
+
+func TestTypeKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		wantTypes []string
+		wantEdges []string
+	}{
+		{
+			name: "eindeutige Namen bleiben unqualifiziert",
+			files: map[string]string{
+				"auth/user.go":       "package auth\n\ntype User struct{ Role Role }\n\ntype Role struct{}\n",
+				"billing/invoice.go": "package billing\n\nimport \"example.com/src/auth\"\n\ntype Invoice struct{ Owner auth.User }\n",
+			},
+			wantTypes: []string{"Invoice", "Role", "User"},
+			wantEdges: []string{"Invoice aggregation User", "User aggregation Role"},
+		},
+		{
+			name: "Kollision wird mit dem Paketnamen qualifiziert",
+			files: map[string]string{
+				"auth/user.go":    "package auth\n\ntype User struct{ Role Role }\n\ntype Role struct{}\n",
+				"billing/user.go": "package billing\n\nimport \"example.com/src/auth\"\n\ntype User struct{ Owner *auth.User }\n\ntype Invoice struct{ Users []*User }\n",
+			},
+			wantTypes: []string{"Invoice", "Role", "auth.User", "billing.User"},
+			wantEdges: []string{"Invoice aggregation billing.User", "auth.User aggregation Role", "billing.User composition auth.User"},
+		},
+		{
+			name: "gleichnamige Pakete werden über den Importpfad unterschieden",
+			files: map[string]string{
+				"a/model/item.go": "package model\n\ntype Item struct{}\n",
+				"b/model/item.go": "package model\n\ntype Item struct{ Next *Item }\n",
+			},
+			wantTypes: []string{"example.com.src.a.model.Item", "example.com.src.b.model.Item"},
+			wantEdges: []string{"example.com.src.b.model.Item composition example.com.src.b.model.Item"},
+		},
+		{
+			name: "Selektor wird über die Importe der Datei aufgelöst",
+			files: map[string]string{
+				"a/model/item.go": "package model\n\ntype Item struct{}\n",
+				"b/model/item.go": "package model\n\ntype Item struct{ N int }\n",
+				"c/use.go":        "package c\n\nimport \"example.com/src/b/model\"\n\ntype Use struct{ I model.Item }\n",
+			},
+			wantTypes: []string{"Use", "example.com.src.a.model.Item", "example.com.src.b.model.Item"},
+			wantEdges: []string{"Use aggregation example.com.src.b.model.Item"},
+		},
+		{
+			name: "Signaturen werden im Paket der Methode verglichen",
+			files: map[string]string{
+				"store/store.go": "package store\n\ntype User struct{}\n\ntype Getter interface {\n\tGet(id int) (*User, error)\n}\n",
+				"mem/mem.go":     "package mem\n\nimport \"example.com/src/store\"\n\ntype Mem struct{}\n\nfunc (m *Mem) Get(id int) (user *store.User, err error) { return nil, nil }\n",
+			},
+			wantTypes: []string{"Getter", "Mem", "User"},
+			wantEdges: []string{"Mem implements Getter"},
+		},
+		{
+			name: "externes Testpaket ist ein eigenes Paket",
+			files: map[string]string{
+				"store/store.go":      "package store\n\ntype Store struct{}\n",
+				"store/store_test.go": "package store_test\n\ntype Store struct{}\n",
+			},
+			wantTypes: []string{"Store"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.files["go.mod"] = "module example.com/src\n\ngo 1.22\n"
+			writeTree(t, dir, tt.files)
+
+			g := NewUMLGenerator(Options{})
+			if err := g.GenerateUMLFromDirectory(dir); err != nil {
+				t.Fatal(err)
+			}
+			if got := typeNames(g); !slices.Equal(got, tt.wantTypes) {
+				t.Errorf("Typen = %v, erwartet %v", got, tt.wantTypes)
+			}
+			if got := relationEdges(g); !slices.Equal(got, tt.wantEdges) {
+				t.Errorf("Beziehungen = %q, erwartet %q", got, tt.wantEdges)
+			}
+			for _, warning := range g.Warnings() {
+				if warning.Kind == WarningNameOnlyMatch {
+					t.Errorf("unerwartete Warnung: %s", warning)
+				}
+			}
+		})
+	}
+}
+
+func TestBareTypeName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"User", "User"},
+		{"auth.User", "User"},
+		{"example.com.src.a.model.Item", "Item"},
+	}
+	for _, tt := range tests {
+		if got := bareTypeName(tt.key); got != tt.want {
+			t.Errorf("bareTypeName(%q) = %q, erwartet %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+// TestPackageBlocks prüft, dass gleichnamige Pakete eigene Blöcke erhalten
+func TestPackageBlocks(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"go.mod":          "module example.com/src\n\ngo 1.22\n",
+		"a/model/item.go": "package model\n\ntype Item struct{}\n",
+		"b/model/item.go": "package model\n\ntype Item struct{ N int }\n",
+		"c/use.go":        "package c\n\ntype Use struct{}\n",
+	})
+	g := NewUMLGenerator(Options{})
+	if err := g.GenerateUMLFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	puml := g.GeneratePlantUML()
+	for _, block := range []string{`package "example.com/src/a/model" {`, `package "example.com/src/b/model" {`, "package c {"} {
+		if strings.Count(puml, block) != 1 {
+			t.Errorf("Block %q fehlt oder ist doppelt:\n%s", block, puml)
+		}
+	}
+}
+
+// TestScopedPatterns prüft, dass die Mustererkennung gleichnamige Typen
+// anderer Pakete nicht verwechselt
+func TestScopedPatterns(t *testing.T) {
+	builder := "package a\n\ntype Query struct{}\n\ntype Builder struct{}\n\nfunc (b *Builder) Where() *Builder { return b }\n\nfunc (b *Builder) Limit() *Builder { return b }\n\nfunc (b *Builder) Build() *Query { return &Query{} }\n"
+	store := "package %s\n\ntype Store interface{ %[2]s() }\n\ntype mem struct{}\n\nfunc (mem) %[2]s() {}\n\nfunc NewStore() Store { return &mem{} }\n"
+
+	tests := []struct {
+		name      string
+		files     map[string]string
+		options   Options
+		wantEdges []string
+		wantTypes map[string]string // Typ -> erwarteter Stereotyp
+	}{
+		{
+			name: "Builder neben gleichnamiger Struct",
+			files: map[string]string{
+				"a/builder.go": builder,
+				"b/builder.go": "package b\n\ntype Builder struct{}\n",
+			},
+			options:   Options{BuildProducts: true},
+			wantEdges: []string{"a.Builder builds Query"},
+			wantTypes: map[string]string{"a.Builder": "builder"},
+		},
+		{
+			name: "Singleton neben gleichnamiger Struct",
+			files: map[string]string{
+				"a/config.go": "package a\n\ntype Config struct{}\n\nvar instance *Config\n\nfunc init() {\n\tinstance = &Config{}\n}\n",
+				"b/config.go": "package b\n\ntype Config struct{}\n",
+			},
+			wantTypes: map[string]string{"a.Config": "singleton"},
+		},
+		{
+			name: "gleichnamige Factories",
+			files: map[string]string{
+				"a/store.go": fmt.Sprintf(store, "a", "Get"),
+				"b/store.go": fmt.Sprintf(store, "b", "Put"),
+			},
+			options: Options{AnalyzeBodies: true},
+			wantEdges: []string{
+				"a.NewStore creates a.mem", "a.NewStore exposes a.Store", "a.mem implements a.Store",
+				"b.NewStore creates b.mem", "b.NewStore exposes b.Store", "b.mem implements b.Store",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.files["go.mod"] = "module example.com/src\n\ngo 1.22\n"
+			writeTree(t, dir, tt.files)
+
+			g := NewUMLGenerator(tt.options)
+			if err := g.GenerateUMLFromDirectory(dir); err != nil {
+				t.Fatal(err)
+			}
+			if got := relationEdges(g); !slices.Equal(got, tt.wantEdges) {
+				t.Errorf("Beziehungen = %q, erwartet %q", got, tt.wantEdges)
+			}
+			for name, stereotype := range tt.wantTypes {
+				structInfo, ok := g.structs[name]
+				if !ok || !slices.Contains(structInfo.Stereotypes, stereotype) {
+					t.Errorf("%s ohne «%s»: %v", name, stereotype, typeNames(g))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,370 @@
+package umlgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WarningOverlay kennzeichnet Einträge des Overlays, die nicht zum Modell passen
+const WarningOverlay = "overlay"
+
+// ModelOverlay verändert das aus dem Code gewonnene Modell vor dem Rendern,
+// z.B. um die Zielarchitektur neben dem Ist-Stand zu zeigen (--overlay):
+//
+//	{
+//	  "remove": ["debugHook", "*Mock"],
+//	  "rename": {"UserRepo": "UserRepository"},
+//	  "add": [{"name": "PaymentGateway", "kind": "interface", "package": "billing",
+//	           "methods": ["Charge(amount: int): error"]}],
+//	  "elements": [{"name": "Postgres", "kind": "database", "label": "Kundendaten"}],
+//	  "relations": [{"from": "Order", "to": "PaymentGateway", "type": "association"},
+//	                {"from": "UserRepo", "to": "Postgres", "type": "uses", "label": "SQL"}],
+//	  "removeRelations": [{"from": "Order", "to": "LegacyBilling"}]
+//	}
+//
+// Die Abschnitte werden in dieser Reihenfolge angewendet. Hinzugefügte Typen
+// erhalten den Stereotyp «planned», hinzugefügte Beziehungen ohne eigene
+// Beschriftung die Beschriftung «planned». Elemente sind Teile des
+// Systemkontexts ohne Code (externe Dienste, Datenbanken, Queues). Über die
+// Konfigurationsdatei (overlay = pfad) gilt ein Overlay für jeden Lauf.
+type ModelOverlay struct {
+	Remove          []string          `json:"remove,omitempty"`
+	Rename          map[string]string `json:"rename,omitempty"`
+	Add             []OverlayType     `json:"add,omitempty"`
+	Elements        []OverlayElement  `json:"elements,omitempty"`
+	Relations       []OverlayRelation `json:"relations,omitempty"`
+	RemoveRelations []OverlayRelation `json:"removeRelations,omitempty"`
+}
+
+// OverlayType ist ein geplanter Typ. Felder werden als "name: Typ", Methoden
+// als "Name(p: Typ): Ergebnis" angegeben, wie sie im Diagramm erscheinen.
+type OverlayType struct {
+	Name        string   `json:"name"`
+	Kind        string   `json:"kind,omitempty"` // "struct" (Standard) oder "interface"
+	Package     string   `json:"package,omitempty"`
+	Fields      []string `json:"fields,omitempty"`
+	Methods     []string `json:"methods,omitempty"`
+	Stereotypes []string `json:"stereotypes,omitempty"`
+}
+
+// OverlayElement ist ein Element des Systemkontexts ohne Entsprechung im Code
+type OverlayElement struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind,omitempty"`  // "system" (Standard), "database", "queue", "cloud" oder "actor"
+	Label   string `json:"label,omitempty"` // Anzeigename, Standard: Name
+	Planned bool   `json:"planned,omitempty"`
+}
+
+// elementShapes bildet die Arten von Elementen auf PlantUML-Elemente ab
+var elementShapes = map[string]string{
+	"system": "component", "database": "database", "queue": "queue", "cloud": "cloud", "actor": "actor",
+}
+
+// OverlayRelation ist eine hinzuzufügende oder zu entfernende Beziehung. Beim
+// Entfernen passt ein leerer Typ auf alle Beziehungen zwischen From und To.
+type OverlayRelation struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Type        string `json:"type,omitempty"`
+	Cardinality string `json:"cardinality,omitempty"`
+	Label       string `json:"label,omitempty"`
+}
+
+// overlayRelationTypes sind die Beziehungsarten, die ein Overlay hinzufügen kann
+var overlayRelationTypes = map[string]bool{
+	"extends": true, "implements": true, "composition": true, "aggregation": true,
+	"association": true, "uses": true, "creates": true,
+}
+
+// loadOverlay liest eine Overlay-Datei
+func loadOverlay(path string) (*ModelOverlay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Lesen des Overlays: %v", err)
+	}
+	var overlay ModelOverlay
+	if err := json.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("Fehler im Overlay %s: %v", path, err)
+	}
+	for i, t := range overlay.Add {
+		if t.Name == "" {
+			return nil, fmt.Errorf("Fehler im Overlay %s: Typ %d ohne Namen", path, i+1)
+		}
+		switch t.Kind {
+		case "", "struct", "interface":
+		default:
+			return nil, fmt.Errorf("Fehler im Overlay %s: ungültige Art %q für %s (erlaubt: struct, interface)", path, t.Kind, t.Name)
+		}
+	}
+	for i, element := range overlay.Elements {
+		if !sketchIdentPattern.MatchString(element.Name) {
+			return nil, fmt.Errorf("Fehler im Overlay %s: Element %d braucht einen Namen aus Buchstaben, Ziffern und _", path, i+1)
+		}
+		if element.Kind == "" {
+			overlay.Elements[i].Kind = "system"
+		} else if elementShapes[element.Kind] == "" {
+			return nil, fmt.Errorf("Fehler im Overlay %s: ungültige Art %q für %s (erlaubt: system, database, queue, cloud, actor)", path, element.Kind, element.Name)
+		}
+	}
+	for _, relation := range overlay.Relations {
+		if relation.From == "" || relation.To == "" || relation.Type == "" {
+			return nil, fmt.Errorf("Fehler im Overlay %s: Beziehungen brauchen from, to und type", path)
+		}
+		if !overlayRelationTypes[relation.Type] {
+			return nil, fmt.Errorf("Fehler im Overlay %s: unbekannte Beziehungsart %q", path, relation.Type)
+		}
+	}
+	return &overlay, nil
+}
+
+// ApplyOverlay wendet eine Overlay-Datei auf das Modell an. Einträge, die
+// sich auf unbekannte Typen beziehen, ergeben Warnungen.
+func (g *UMLGenerator) ApplyOverlay(path string) error {
+	overlay, err := loadOverlay(path)
+	if err != nil {
+		return err
+	}
+	pos := Position{File: path}
+
+	// Entfernen, auch mit Mustern wie *Mock
+	for _, pattern := range overlay.Remove {
+		keep := make(map[string]bool)
+		removed := false
+		for _, typeInfo := range g.Types() {
+			if matched, _ := filepath.Match(pattern, typeInfo.Name); matched {
+				removed = true
+			} else {
+				keep[typeInfo.Name] = true
+			}
+		}
+		if !removed {
+			g.warn(WarningOverlay, pos, "remove: kein Typ passt auf %s", pattern)
+			continue
+		}
+		g.retainTypes(keep)
+	}
+
+	for _, oldName := range sortedMapKeys(overlay.Rename) {
+		if !g.renameType(oldName, overlay.Rename[oldName]) {
+			g.warn(WarningOverlay, pos, "rename: Typ %s ist unbekannt oder %s existiert bereits", oldName, overlay.Rename[oldName])
+		}
+	}
+
+	var added []string
+	for _, t := range overlay.Add {
+		if g.isKnownType(t.Name) {
+			g.warn(WarningOverlay, pos, "add: Typ %s existiert bereits", t.Name)
+			continue
+		}
+		if err := g.addPlannedType(t, pos); err != nil {
+			return fmt.Errorf("Fehler im Overlay %s: %v", path, err)
+		}
+		added = append(added, t.Name)
+	}
+	// Felder geplanter Structs ergeben Beziehungen wie im Code
+	for _, name := range added {
+		if structInfo, ok := g.structs[name]; ok {
+			g.identifyFieldRelations(name, structInfo)
+		}
+	}
+
+	for _, element := range overlay.Elements {
+		if g.isKnownType(element.Name) || g.elements[element.Name] != nil {
+			g.warn(WarningOverlay, pos, "elements: %s existiert bereits", element.Name)
+			continue
+		}
+		g.elements[element.Name] = &ElementInfo{Name: element.Name, Kind: element.Kind, Label: element.Label, Planned: element.Planned}
+	}
+
+	known := func(name string) bool { return g.isKnownType(name) || g.elements[name] != nil }
+	for _, relation := range overlay.Relations {
+		if !known(relation.From) || !known(relation.To) {
+			g.warn(WarningOverlay, pos, "relations: %s -> %s verweist auf einen unbekannten Typ", relation.From, relation.To)
+			continue
+		}
+		// Beziehungen zwischen Typen zeigen die Zielarchitektur, solche zu
+		// Kontextelementen den Ist-Zustand
+		label := relation.Label
+		if label == "" && g.elements[relation.From] == nil && g.elements[relation.To] == nil {
+			label = "<<planned>>"
+		}
+		g.relations = append(g.relations, Relation{
+			From:        relation.From,
+			To:          relation.To,
+			Type:        relation.Type,
+			Cardinality: relation.Cardinality,
+			Label:       label,
+			Pos:         pos,
+		})
+	}
+
+	for _, remove := range overlay.RemoveRelations {
+		var relations []Relation
+		for _, relation := range g.relations {
+			if relation.From == remove.From && relation.To == remove.To && (remove.Type == "" || relation.Type == remove.Type) {
+				continue
+			}
+			relations = append(relations, relation)
+		}
+		if len(relations) == len(g.relations) {
+			g.warn(WarningOverlay, pos, "removeRelations: keine Beziehung %s -> %s", remove.From, remove.To)
+		}
+		g.relations = relations
+	}
+	return nil
+}
+
+// ElementInfo ist ein Element des Systemkontexts aus dem Overlay
+type ElementInfo struct {
+	Name    string
+	Kind    string
+	Label   string
+	Planned bool
+}
+
+// writeElementPlantUML schreibt ein Kontextelement, z.B.
+// database "Kundendaten" as Postgres <<external>>
+func writeElementPlantUML(out *plantUMLWriter, element *ElementInfo) {
+	label := element.Label
+	if label == "" {
+		label = element.Name
+	}
+	stereotype := "external"
+	if element.Kind == "system" {
+		stereotype = "external system"
+	}
+	if element.Planned {
+		stereotype = "planned"
+	}
+	out.WriteString(fmt.Sprintf("%s \"%s\" as %s <<%s>>\n\n", elementShapes[element.Kind], label, element.Name, stereotype))
+}
+
+// renameType benennt einen Typ samt aller Verweise um
+func (g *UMLGenerator) renameType(oldName, newName string) bool {
+	if !g.isKnownType(oldName) || g.isKnownType(newName) {
+		return false
+	}
+	rename := func(name string) string {
+		if name == oldName {
+			return newName
+		}
+		return name
+	}
+
+	if structInfo, ok := g.structs[oldName]; ok {
+		delete(g.structs, oldName)
+		structInfo.Name = newName
+		g.structs[newName] = structInfo
+	}
+	if interfaceInfo, ok := g.interfaces[oldName]; ok {
+		delete(g.interfaces, oldName)
+		interfaceInfo.Name = newName
+		g.interfaces[newName] = interfaceInfo
+	}
+	if namedInfo, ok := g.namedTypes[oldName]; ok {
+		delete(g.namedTypes, oldName)
+		namedInfo.Name = newName
+		g.namedTypes[newName] = namedInfo
+	}
+
+	for _, structInfo := range g.structs {
+		for i := range structInfo.Fields {
+			field := &structInfo.Fields[i]
+			field.Target, field.MapKey = rename(field.Target), rename(field.MapKey)
+		}
+	}
+	for _, namedInfo := range g.namedTypes {
+		namedInfo.Target = rename(namedInfo.Target)
+	}
+	for _, factory := range g.factories {
+		factory.Exposes = rename(factory.Exposes)
+		for i, created := range factory.Creates {
+			factory.Creates[i] = rename(created)
+		}
+	}
+	for i := range g.relations {
+		g.relations[i].From, g.relations[i].To = rename(g.relations[i].From), rename(g.relations[i].To)
+	}
+	return true
+}
+
+// addPlannedType fügt einen geplanten Typ mit Stereotyp «planned» hinzu
+func (g *UMLGenerator) addPlannedType(t OverlayType, pos Position) error {
+	stereotypes := append([]string{"planned"}, t.Stereotypes...)
+
+	var methods []MethodInfo
+	for _, method := range t.Methods {
+		methodInfo, err := parseOverlayMethod(method)
+		if err != nil {
+			return fmt.Errorf("%s: %v", t.Name, err)
+		}
+		methodInfo.Pos = pos
+		methods = append(methods, methodInfo)
+	}
+
+	if t.Kind == "interface" {
+		if len(t.Fields) > 0 {
+			return fmt.Errorf("Interface %s kann keine Felder haben", t.Name)
+		}
+		g.interfaces[t.Name] = &InterfaceInfo{Name: t.Name, Package: t.Package, Pos: pos, Methods: methods, Stereotypes: stereotypes}
+		return nil
+	}
+
+	structInfo := &StructInfo{Name: t.Name, Package: t.Package, Pos: pos, Fields: []FieldInfo{}, Methods: methods, Stereotypes: stereotypes}
+	for _, field := range t.Fields {
+		colon := strings.Index(field, ":")
+		if colon < 0 {
+			return fmt.Errorf("%s: Feld %q hat nicht die Form name: Typ", t.Name, field)
+		}
+		fieldType := strings.TrimSpace(field[colon+1:])
+		expr, err := parser.ParseExpr(fieldType)
+		if err != nil {
+			return fmt.Errorf("%s: ungültiger Feldtyp %q", t.Name, fieldType)
+		}
+		target, multiple := containerTarget(expr)
+		mapKey, isMap := mapKeyTarget(expr)
+		structInfo.Fields = append(structInfo.Fields, FieldInfo{
+			Name:     strings.TrimSpace(field[:colon]),
+			Type:     fieldType,
+			Pos:      pos,
+			Target:   target,
+			Multiple: multiple,
+			ChanDir:  channelDirection(expr),
+			IsMap:    isMap,
+			MapKey:   mapKey,
+		})
+	}
+	g.structs[t.Name] = structInfo
+	return nil
+}
+
+// parseOverlayMethod zerlegt eine Methode in Diagrammschreibweise wie
+// "Charge(amount: int, currency: string): error"
+func parseOverlayMethod(method string) (MethodInfo, error) {
+	open := strings.Index(method, "(")
+	if open <= 0 {
+		return MethodInfo{}, fmt.Errorf("Methode %q hat nicht die Form Name(...)", method)
+	}
+	close := matchingParen(method, open)
+	if close < 0 {
+		return MethodInfo{}, fmt.Errorf("unvollständige Methode %q", method)
+	}
+
+	methodInfo := MethodInfo{Name: strings.TrimSpace(method[:open]), Parameters: []ParameterInfo{}}
+	for _, param := range splitTopLevel(method[open+1 : close]) {
+		if colon := strings.Index(param, ":"); colon >= 0 {
+			methodInfo.Parameters = append(methodInfo.Parameters, ParameterInfo{
+				Name: strings.TrimSpace(param[:colon]),
+				Type: strings.TrimSpace(param[colon+1:]),
+			})
+		} else {
+			methodInfo.Parameters = append(methodInfo.Parameters, ParameterInfo{Type: param})
+		}
+	}
+	methodInfo.ReturnType = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(method[close+1:]), ":"))
+	return methodInfo, nil
+}
@@ -0,0 +1,143 @@
+package umlgen
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// overlayWarnings zählt die Warnungen des Overlays
+func overlayWarnings(g *UMLGenerator) int {
+	count := 0
+	for _, warning := range g.Warnings() {
+		if warning.Kind == WarningOverlay {
+			count++
+		}
+	}
+	return count
+}
+
+func TestApplyOverlay(t *testing.T) {
+	files := map[string]string{"shop/shop.go": `package shop
+
+type UserRepo struct{}
+
+type UserRepoMock struct{}
+
+type Order struct {
+	Users *UserRepo
+}
+`}
+
+	tests := []struct {
+		name         string
+		overlay      string
+		wantTypes    []string
+		wantEdges    []string
+		wantWarnings int
+		wantErr      bool
+	}{
+		{
+			name:      "Entfernen mit Muster",
+			overlay:   `{"remove": ["*Mock"]}`,
+			wantTypes: []string{"Order", "UserRepo"},
+			wantEdges: []string{"Order composition UserRepo"},
+		},
+		{
+			name:      "Umbenennen zieht Beziehungen mit",
+			overlay:   `{"rename": {"UserRepo": "UserRepository"}}`,
+			wantTypes: []string{"Order", "UserRepoMock", "UserRepository"},
+			wantEdges: []string{"Order composition UserRepository"},
+		},
+		{
+			name: "geplanter Typ mit Feldern und Beziehung",
+			overlay: `{"add": [{"name": "Gateway", "kind": "interface", "methods": ["Charge(amount: int): error"]},
+			                   {"name": "Payment", "fields": ["orders: []*Order"]}],
+			           "relations": [{"from": "Order", "to": "Gateway", "type": "association"}]}`,
+			wantTypes: []string{"Gateway", "Order", "Payment", "UserRepo", "UserRepoMock"},
+			wantEdges: []string{"Order association Gateway", "Order composition UserRepo", "Payment aggregation Order"},
+		},
+		{
+			name:      "Beziehung entfernen",
+			overlay:   `{"removeRelations": [{"from": "Order", "to": "UserRepo"}]}`,
+			wantTypes: []string{"Order", "UserRepo", "UserRepoMock"},
+		},
+		{
+			name: "unbekannte Typen ergeben Warnungen",
+			overlay: `{"remove": ["Missing"], "rename": {"Missing": "Other"}, "add": [{"name": "Order"}],
+			           "relations": [{"from": "Order", "to": "Missing", "type": "uses"}],
+			           "removeRelations": [{"from": "UserRepo", "to": "Order"}]}`,
+			wantTypes:    []string{"Order", "UserRepo", "UserRepoMock"},
+			wantEdges:    []string{"Order composition UserRepo"},
+			wantWarnings: 5,
+		},
+		{name: "ungültige Art", overlay: `{"add": [{"name": "X", "kind": "enum"}]}`, wantErr: true},
+		{name: "Beziehung ohne Art", overlay: `{"relations": [{"from": "Order", "to": "UserRepo"}]}`, wantErr: true},
+		{name: "unbekannte Beziehungsart", overlay: `{"relations": [{"from": "Order", "to": "UserRepo", "type": "owns"}]}`, wantErr: true},
+		{name: "Element ohne gültigen Namen", overlay: `{"elements": [{"name": "Post gres"}]}`, wantErr: true},
+		{name: "Interface mit Feldern", overlay: `{"add": [{"name": "X", "kind": "interface", "fields": ["a: int"]}]}`, wantErr: true},
+		{name: "Feld ohne Typ", overlay: `{"add": [{"name": "X", "fields": ["a"]}]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTree(t, dir, files)
+			path := filepath.Join(t.TempDir(), "overlay.json")
+			writeTree(t, filepath.Dir(path), map[string]string{"overlay.json": tt.overlay})
+
+			g := NewUMLGenerator(Options{Overlay: path})
+			err := g.GenerateUMLFromDirectory(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fehler = %v, erwartet Fehler: %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := typeNames(g); !slices.Equal(got, tt.wantTypes) {
+				t.Errorf("Typen = %v, erwartet %v", got, tt.wantTypes)
+			}
+			if got := relationEdges(g); !slices.Equal(got, tt.wantEdges) {
+				t.Errorf("Beziehungen = %q, erwartet %q", got, tt.wantEdges)
+			}
+			if got := overlayWarnings(g); got != tt.wantWarnings {
+				t.Errorf("%d Overlay-Warnungen, erwartet %d: %v", got, tt.wantWarnings, g.Warnings())
+			}
+		})
+	}
+}
+
+func TestParseOverlayMethod(t *testing.T) {
+	tests := []struct {
+		method     string
+		wantName   string
+		wantParams []ParameterInfo
+		wantReturn string
+		wantErr    bool
+	}{
+		{method: "Close()", wantName: "Close", wantParams: []ParameterInfo{}},
+		{method: "Charge(amount: int, currency: string): error", wantName: "Charge",
+			wantParams: []ParameterInfo{{Name: "amount", Type: "int"}, {Name: "currency", Type: "string"}}, wantReturn: "error"},
+		{method: "Apply(fn: func(a, b int) error, m: map[string]int): (int, error)", wantName: "Apply",
+			wantParams: []ParameterInfo{{Name: "fn", Type: "func(a, b int) error"}, {Name: "m", Type: "map[string]int"}}, wantReturn: "(int, error)"},
+		{method: "Load(string)", wantName: "Load", wantParams: []ParameterInfo{{Type: "string"}}},
+		{method: "Close", wantErr: true},
+		{method: "(x: int)", wantErr: true},
+		{method: "Open(path: string", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			got, err := parseOverlayMethod(tt.method)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fehler = %v, erwartet Fehler: %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Name != tt.wantName || got.ReturnType != tt.wantReturn || !slices.Equal(got.Parameters, tt.wantParams) {
+				t.Errorf("parseOverlayMethod(%q) = %s %v %q, erwartet %s %v %q", tt.method,
+					got.Name, got.Parameters, got.ReturnType, tt.wantName, tt.wantParams, tt.wantReturn)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package umlgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// packageVar ist eine Variable auf Paketebene mit bekanntem Struct-Typ
+type packageVar struct {
+	name   string
+	target string // Struct-Typ der Variable
+	pos    Position
+}
+
+// detectSingletons markiert Structs, deren Instanz in einer Paketvariable
+// liegt, die in init() oder über sync.Once.Do belegt wird, als «singleton».
+// Structs, die ein sync.Pool auf Paketebene erzeugt, werden als «pooled»
+// markiert. Beide erhalten eine Notiz, wo die Instanz liegt.
+func (g *UMLGenerator) detectSingletons() {
+	// Dateien nach Paket (Verzeichnis + Paketname) gruppieren
+	packages := make(map[string][]*ast.File)
+	for _, filePath := range sortedMapKeys(g.files) {
+		file := g.files[filePath]
+		key := filepath.Dir(filePath) + ":" + file.Name.Name
+		packages[key] = append(packages[key], file)
+	}
+
+	for _, key := range sortedMapKeys(packages) {
+		files := packages[key]
+
+		vars := make(map[string]packageVar)
+		for _, file := range files {
+			for _, decl := range file.Decls {
+				genDecl, ok := decl.(*ast.GenDecl)
+				if !ok || genDecl.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range genDecl.Specs {
+					g.collectPackageVars(file, spec.(*ast.ValueSpec), vars)
+				}
+			}
+		}
+		if len(vars) == 0 {
+			continue
+		}
+
+		// Zuweisungen in init() oder in Funktionen, die an Do(...) übergeben werden
+		singletons := make(map[string]bool)
+		for _, file := range files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				switch node := n.(type) {
+				case *ast.FuncDecl:
+					if node.Recv == nil && node.Name.Name == "init" && node.Body != nil {
+						markAssignedVars(node.Body, vars, singletons)
+					}
+				case *ast.CallExpr:
+					sel, ok := node.Fun.(*ast.SelectorExpr)
+					if !ok || sel.Sel.Name != "Do" || len(node.Args) != 1 {
+						return true
+					}
+					if lit, ok := node.Args[0].(*ast.FuncLit); ok {
+						markAssignedVars(lit.Body, vars, singletons)
+					}
+				}
+				return true
+			})
+		}
+
+		for _, name := range sortedMapKeys(singletons) {
+			v := vars[name]
+			if structInfo, ok := g.structs[v.target]; ok {
+				structInfo.Stereotypes = appendUnique(structInfo.Stereotypes, "singleton")
+				structInfo.Notes = append(structInfo.Notes, fmt.Sprintf("Instanz: %s (%s:%d)", v.name, filepath.Base(v.pos.File), v.pos.Line))
+			}
+		}
+	}
+}
+
+// collectPackageVars erfasst Paketvariablen mit Struct-Typ. Variablen vom Typ
+// sync.Pool, deren New-Funktion eine Struct erzeugt, markieren diese direkt
+// als «pooled».
+func (g *UMLGenerator) collectPackageVars(file *ast.File, spec *ast.ValueSpec, vars map[string]packageVar) {
+	for i, name := range spec.Names {
+		var value ast.Expr
+		if i < len(spec.Values) {
+			value = spec.Values[i]
+		}
+
+		if g.detectPool(file, name, spec.Type, value) {
+			continue
+		}
+
+		target := ""
+		if spec.Type != nil {
+			target, _ = containerTarget(spec.Type)
+			target = g.modelTarget(file, target)
+		} else if value != nil {
+			target = g.literalType(file, value)
+		}
+		if _, ok := g.structs[target]; ok {
+			vars[name.Name] = packageVar{name: name.Name, target: target, pos: g.position(name.Pos())}
+		}
+	}
+}
+
+// detectPool prüft, ob eine Paketvariable ein sync.Pool ist, und markiert die
+// in dessen New-Funktion erzeugte Struct als «pooled»
+func (g *UMLGenerator) detectPool(file *ast.File, name *ast.Ident, typeExpr, value ast.Expr) bool {
+	lit, ok := value.(*ast.CompositeLit)
+	if !ok {
+		return false
+	}
+	if lit.Type != nil {
+		typeExpr = lit.Type
+	}
+	if typeExpr == nil || getTypeString(typeExpr) != "sync.Pool" {
+		return false
+	}
+
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != "New" {
+			continue
+		}
+		fn, ok := kv.Value.(*ast.FuncLit)
+		if !ok {
+			continue
+		}
+		pos := g.position(name.Pos())
+		for _, created := range g.createdTypes(file, fn.Body) {
+			structInfo := g.structs[created]
+			structInfo.Stereotypes = appendUnique(structInfo.Stereotypes, "pooled")
+			structInfo.Notes = append(structInfo.Notes, fmt.Sprintf("Pool: %s (%s:%d)", name.Name, filepath.Base(pos.File), pos.Line))
+		}
+	}
+	return true
+}
+
+// markAssignedVars markiert alle Paketvariablen, denen im Block ein Wert
+// zugewiesen wird
+func markAssignedVars(body *ast.BlockStmt, vars map[string]packageVar, marked map[string]bool) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || assign.Tok != token.ASSIGN {
+			return true
+		}
+		for _, lhs := range assign.Lhs {
+			if ident, ok := lhs.(*ast.Ident); ok {
+				if _, ok := vars[ident.Name]; ok {
+					marked[ident.Name] = true
+				}
+			}
+		}
+		return true
+	})
+}
+
+// appendUnique hängt einen Wert an, sofern er noch nicht enthalten ist
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// detectFunctionalOptions erkennt das Functional-Options-Muster: ein
+// Funktionstyp wie type Option func(*Config) und Paketfunktionen, die diesen
+// Typ zurückgeben (WithTimeout, ...). Die Optionsfunktionen werden der
+// konfigurierten Struct zugeordnet.
+func (g *UMLGenerator) detectFunctionalOptions() {
+	// Optionstypen: Funktionstyp mit genau einem Parameter *T bzw. T
+	optionTypes := make(map[string]string)
+	for _, filePath := range sortedMapKeys(g.files) {
+		file := g.files[filePath]
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				funcType, ok := typeSpec.Type.(*ast.FuncType)
+				if !ok || funcType.Params == nil || len(funcType.Params.List) != 1 || len(funcType.Params.List[0].Names) > 1 {
+					continue
+				}
+				target, _ := containerTarget(funcType.Params.List[0].Type)
+				target = g.modelTarget(file, target)
+				if _, ok := g.structs[target]; ok {
+					optionTypes[g.modelTarget(file, typeSpec.Name.Name)] = target
+				}
+			}
+		}
+	}
+	if len(optionTypes) == 0 {
+		return
+	}
+
+	// Optionsfunktionen: Paketfunktionen mit genau einem Optionstyp als Ergebnis
+	for _, filePath := range sortedMapKeys(g.files) {
+		file := g.files[filePath]
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Recv != nil || funcDecl.Type.Results == nil || len(funcDecl.Type.Results.List) != 1 {
+				continue
+			}
+			target, ok := optionTypes[g.modelTarget(file, getTypeString(funcDecl.Type.Results.List[0].Type))]
+			if !ok {
+				continue
+			}
+			structInfo := g.structs[target]
+			structInfo.OptionFuncs = append(structInfo.OptionFuncs, g.buildMethodInfo(funcDecl.Name.Name, funcDecl.Type, funcDecl.Pos()))
+		}
+	}
+}
+
+// minBuilderChainMethods ist die Mindestanzahl verkettbarer Methoden, ab der
+// eine Struct als Builder gilt (schließt Einzelfälle wie Clone() aus)
+const minBuilderChainMethods = 2
+
+// detectBuilders markiert Structs mit mehreren Methoden, die den eigenen Typ
+// zurückgeben, als «builder». Mit --build-products wird zusätzlich eine
+// Beziehung zum Rückgabetyp der Build()-Methode gezeichnet.
+func (g *UMLGenerator) detectBuilders() {
+	for _, name := range sortedMapKeys(g.structs) {
+		structInfo := g.structs[name]
+
+		// Rückgabetypen sind so geschrieben, wie sie im Paket der Struct stehen
+		typeName := bareTypeName(name)
+		chainMethods := 0
+		var buildMethod *MethodInfo
+		for i, method := range structInfo.Methods {
+			switch method.ReturnType {
+			case typeName, "*" + typeName:
+				chainMethods++
+			}
+			if method.Name == "Build" {
+				buildMethod = &structInfo.Methods[i]
+			}
+		}
+		if chainMethods < minBuilderChainMethods {
+			continue
+		}
+
+		structInfo.Stereotypes = appendUnique(structInfo.Stereotypes, "builder")
+
+		if g.options.BuildProducts && buildMethod != nil {
+			// Erster Rückgabewert, z.B. *Query bei (*Query, error)
+			product := ""
+			if results := buildMethod.resultTypes(); len(results) > 0 {
+				product = strings.TrimPrefix(results[0], "*")
+			}
+			if file, ok := g.files[buildMethod.Pos.File]; ok {
+				product = g.modelTarget(file, product)
+			}
+			if g.isKnownType(product) && product != name {
+				g.relations = append(g.relations, Relation{
+					From: name,
+					To:   product,
+					Type: "builds",
+					Pos:  buildMethod.Pos,
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package umlgen
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// picowebStartTimeout begrenzt die Wartezeit, bis der PlantUML-Server antwortet
+const picowebStartTimeout = 30 * time.Second
+
+// plantUMLServer ist ein als Kindprozess gestarteter PlantUML-Server
+// (java -jar plantuml.jar -picoweb), gegen den im Watch-Modus gerendert
+// wird, ohne für jedes Diagramm eine neue JVM zu starten
+type plantUMLServer struct {
+	cmd  *exec.Cmd
+	url  string
+	done chan error
+}
+
+// startPlantUMLServer startet den Server auf dem angegebenen oder einem
+// freien Port und wartet, bis er Anfragen beantwortet. Der Port muss vor dem
+// Start frei sein, sonst würde die Bereitschaftsprüfung einen fremden Server
+// (z.B. den unter defaultLocalServer) erreichen.
+func startPlantUMLServer(options Options) (*plantUMLServer, error) {
+	port, err := freePort(options.ServerPort)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Starten des PlantUML-Servers: %v", err)
+	}
+	jarPath, err := ensurePlantUMLJar(options)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Starten des PlantUML-Servers: %v", err)
+	}
+
+	cmd := exec.Command("java", "-jar", jarPath, fmt.Sprintf("-picoweb:%d", port))
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("Fehler beim Starten des PlantUML-Servers: %v", err)
+	}
+
+	server := &plantUMLServer{
+		cmd:  cmd,
+		url:  fmt.Sprintf("http://127.0.0.1:%d/plantuml", port),
+		done: make(chan error, 1),
+	}
+	go func() { server.done <- cmd.Wait() }()
+
+	client := &http.Client{Timeout: time.Second}
+	probe := server.url + "/png/" + encodePlantUML([]byte("@startuml\n@enduml"))
+	deadline := time.Now().Add(picowebStartTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-server.done:
+			return nil, fmt.Errorf("Fehler beim Starten des PlantUML-Servers: Prozess beendet: %v", err)
+		case <-time.After(200 * time.Millisecond):
+		}
+
+		// Nur eine PNG-Antwort stammt vom gerade gestarteten Server
+		resp, err := client.Get(probe)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK && strings.HasPrefix(resp.Header.Get("Content-Type"), "image/png") {
+				fmt.Printf("PlantUML-Server gestartet: %s\n", server.url)
+				return server, nil
+			}
+		}
+	}
+
+	server.Stop()
+	return nil, fmt.Errorf("Fehler beim Starten des PlantUML-Servers: keine Antwort nach %s", picowebStartTimeout)
+}
+
+// freePort prüft, ob port auf 127.0.0.1 frei ist; 0 wählt einen freien Port
+func freePort(port int) (int, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return 0, fmt.Errorf("Port %d ist belegt: %v", port, err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// Stop beendet den Serverprozess
+func (s *plantUMLServer) Stop() {
+	if s.cmd.Process == nil {
+		return
+	}
+	s.cmd.Process.Kill()
+	<-s.done
+}
@@ -0,0 +1,171 @@
+package umlgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InterfacePort beschreibt ein Interface als Port: welche Pakete es mit
+// konkreten Typen bereitstellen und welche es verwenden
+type InterfacePort struct {
+	Interface string
+	Package   string
+	Providers map[string][]string // Paket -> implementierende Typen
+	Consumers map[string][]string // Paket -> Typen, die das Interface verwenden
+}
+
+// InterfacePorts sammelt für jedes Interface die bereitstellenden und die
+// verwendenden Pakete. Als Verwendung zählen Felder, Methodenparameter und
+// Rückgabetypen mit dem Interface als Elementtyp.
+func (g *UMLGenerator) InterfacePorts() []*InterfacePort {
+	ports := make(map[string]*InterfacePort)
+	for _, name := range sortedMapKeys(g.interfaces) {
+		ports[name] = &InterfacePort{
+			Interface: name,
+			Package:   g.interfaces[name].Package,
+			Providers: make(map[string][]string),
+			Consumers: make(map[string][]string),
+		}
+	}
+
+	for _, relation := range g.relations {
+		port, ok := ports[relation.To]
+		if !ok || relation.Type != "implements" || !g.providesInterface(relation.From, relation.To) {
+			continue
+		}
+		pkg, _ := g.typePackage(relation.From)
+		port.Providers[pkg] = appendUnique(port.Providers[pkg], relation.From)
+	}
+	for _, name := range sortedMapKeys(g.factories) {
+		factory := g.factories[name]
+		if port, ok := ports[factory.Exposes]; ok {
+			port.Providers[factory.Package] = appendUnique(port.Providers[factory.Package], factory.Name+"()")
+		}
+	}
+
+	consume := func(typeName, pkg, used string) {
+		if port, ok := ports[used]; ok && used != typeName {
+			port.Consumers[pkg] = appendUnique(port.Consumers[pkg], typeName)
+		}
+	}
+	consumeMethods := func(typeName, pkg string, methods []MethodInfo) {
+		for _, method := range methods {
+			for _, param := range method.Parameters {
+				consume(typeName, pkg, elementTypeName(param.Type))
+			}
+			for _, result := range method.resultTypes() {
+				consume(typeName, pkg, elementTypeName(result))
+			}
+		}
+	}
+	for _, name := range sortedMapKeys(g.structs) {
+		structInfo := g.structs[name]
+		for _, field := range structInfo.Fields {
+			consume(name, structInfo.Package, field.Target)
+		}
+		consumeMethods(name, structInfo.Package, structInfo.Methods)
+	}
+	for _, name := range sortedMapKeys(g.namedTypes) {
+		namedInfo := g.namedTypes[name]
+		consume(name, namedInfo.Package, namedInfo.Target)
+		consumeMethods(name, namedInfo.Package, namedInfo.Methods)
+	}
+
+	result := make([]*InterfacePort, 0, len(ports))
+	for _, name := range sortedMapKeys(ports) {
+		result = append(result, ports[name])
+	}
+	return result
+}
+
+// providesInterface prüft, ob ein Typ alle Methoden des Interfaces besitzt.
+// So zählen Felder vom Typ eines Interfaces, die ebenfalls als "implements"
+// erfasst werden, nicht als Bereitstellung.
+func (g *UMLGenerator) providesInterface(typeName, interfaceName string) bool {
+	var methods []MethodInfo
+	if structInfo, ok := g.structs[typeName]; ok {
+		methods = structInfo.Methods
+	} else if namedInfo, ok := g.namedTypes[typeName]; ok {
+		methods = namedInfo.Methods
+	} else {
+		return false
+	}
+
+	required, _ := g.interfaceMethodSet(interfaceName)
+	if len(required) == 0 {
+		return false
+	}
+	for _, interfaceMethod := range required {
+		found := false
+		for _, method := range methods {
+			if method.Name == interfaceMethod.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// elementTypeName entfernt Pointer, Container und variadische Punkte von
+// einer Typangabe, z.B. "[]*Store" -> "Store"
+func elementTypeName(typeName string) string {
+	typeName = strings.TrimSpace(typeName)
+	for {
+		trimmed := strings.TrimLeft(typeName, "*.[]")
+		if strings.HasPrefix(trimmed, "map[") {
+			if i := strings.Index(trimmed, "]"); i >= 0 {
+				trimmed = trimmed[i+1:]
+			}
+		}
+		trimmed = strings.TrimPrefix(strings.TrimPrefix(trimmed, "<-"), "chan ")
+		if trimmed == typeName {
+			return typeName
+		}
+		typeName = trimmed
+	}
+}
+
+// GeneratePortsPlantUML zeichnet nur Interfaces und Pakete: Pakete, die ein
+// Interface implementieren, und Pakete, die es verwenden. Kanten zwischen
+// konkreten Typen entfallen, so dass Ports und Adapter sichtbar werden.
+func (g *UMLGenerator) GeneratePortsPlantUML(ports []*InterfacePort) string {
+	var sb strings.Builder
+	sb.WriteString("@startuml\n\n")
+
+	packages := make(map[string]bool)
+	for _, port := range ports {
+		packages[port.Package] = true
+		for pkg := range port.Providers {
+			packages[pkg] = true
+		}
+		for pkg := range port.Consumers {
+			packages[pkg] = true
+		}
+	}
+	for _, pkg := range sortedMapKeys(packages) {
+		sb.WriteString(fmt.Sprintf("class \"%s\" as pkg_%s <<package>>\n", pkg, pkg))
+	}
+	sb.WriteString("\n")
+
+	for _, port := range ports {
+		sb.WriteString(fmt.Sprintf("interface %s\n", port.Interface))
+		sb.WriteString(fmt.Sprintf("pkg_%s +-- %s\n", port.Package, port.Interface))
+	}
+	sb.WriteString("\n")
+
+	for _, port := range ports {
+		for _, pkg := range sortedMapKeys(port.Providers) {
+			sb.WriteString(fmt.Sprintf("%s <|.. pkg_%s : liefert (%s)\n", port.Interface, pkg, strings.Join(port.Providers[pkg], ", ")))
+		}
+		for _, pkg := range sortedMapKeys(port.Consumers) {
+			sb.WriteString(fmt.Sprintf("pkg_%s ..> %s : nutzt (%s)\n", pkg, port.Interface, strings.Join(port.Consumers[pkg], ", ")))
+		}
+	}
+
+	sb.WriteString("\n@enduml")
+	return sb.String()
+}
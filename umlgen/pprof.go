@@ -0,0 +1,335 @@
+package umlgen
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// hotShareThreshold ist der CPU-Anteil, ab dem ein Typ hervorgehoben wird
+const hotShareThreshold = 0.05
+
+// cpuProfile enthält die für die Hervorhebung benötigten Teile eines
+// pprof-Profils: pro Sample den Stack als Funktionsnamen (Blatt zuerst) und
+// den CPU-Wert
+type cpuProfile struct {
+	stacks [][]string
+	values []int64
+}
+
+// readCPUProfile liest ein pprof-Profil (gzip-komprimiertes Protobuf, wie es
+// go test -cpuprofile oder runtime/pprof schreiben)
+func readCPUProfile(path string) (*cpuProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if data, err = io.ReadAll(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	// Rohdaten der Nachrichten sammeln; die Auflösung erfolgt erst, wenn die
+	// Stringtabelle vollständig ist
+	type sample struct {
+		locations []uint64
+		values    []int64
+	}
+	var (
+		sampleTypes []int64 // Stringindex des Typs je Wertspalte
+		samples     []sample
+		locations   = make(map[uint64][]uint64) // Location -> Funktionen, innerste zuerst
+		functions   = make(map[uint64]int64)    // Funktion -> Stringindex des Namens
+		stringTable []string
+	)
+
+	err = protoFields(data, func(field int, value uint64, message []byte) error {
+		switch field {
+		case 1: // sample_type
+			return protoFields(message, func(field int, value uint64, _ []byte) error {
+				if field == 1 {
+					sampleTypes = append(sampleTypes, int64(value))
+				}
+				return nil
+			})
+		case 2: // sample
+			var s sample
+			err := protoFields(message, func(field int, value uint64, packed []byte) error {
+				switch field {
+				case 1:
+					s.locations = append(s.locations, protoVarints(value, packed)...)
+				case 2:
+					for _, v := range protoVarints(value, packed) {
+						s.values = append(s.values, int64(v))
+					}
+				}
+				return nil
+			})
+			samples = append(samples, s)
+			return err
+		case 4: // location
+			var id uint64
+			var funcs []uint64
+			err := protoFields(message, func(field int, value uint64, line []byte) error {
+				switch field {
+				case 1:
+					id = value
+				case 4:
+					return protoFields(line, func(field int, value uint64, _ []byte) error {
+						if field == 1 {
+							funcs = append(funcs, value)
+						}
+						return nil
+					})
+				}
+				return nil
+			})
+			locations[id] = funcs
+			return err
+		case 5: // function
+			var id uint64
+			var name int64
+			err := protoFields(message, func(field int, value uint64, _ []byte) error {
+				switch field {
+				case 1:
+					id = value
+				case 2:
+					name = int64(value)
+				}
+				return nil
+			})
+			functions[id] = name
+			return err
+		case 6: // string_table
+			stringTable = append(stringTable, string(message))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kein gültiges pprof-Profil: %v", err)
+	}
+
+	str := func(index int64) string {
+		if index < 0 || int(index) >= len(stringTable) {
+			return ""
+		}
+		return stringTable[index]
+	}
+
+	// Wertspalte "cpu" bevorzugen, sonst die letzte (bei CPU-Profilen die Zeit)
+	column := len(sampleTypes) - 1
+	for i, t := range sampleTypes {
+		if str(t) == "cpu" {
+			column = i
+		}
+	}
+
+	profile := &cpuProfile{}
+	for _, s := range samples {
+		if column < 0 || column >= len(s.values) {
+			continue
+		}
+		var stack []string
+		for _, location := range s.locations {
+			for _, function := range locations[location] {
+				stack = append(stack, str(functions[function]))
+			}
+		}
+		profile.stacks = append(profile.stacks, stack)
+		profile.values = append(profile.values, s.values[column])
+	}
+	return profile, nil
+}
+
+// protoFields durchläuft die Felder einer Protobuf-Nachricht. Für Varints
+// wird value gesetzt, für längenbegrenzte Felder message.
+func protoFields(data []byte, fn func(field int, value uint64, message []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("ungültiger Feldschlüssel")
+		}
+		data = data[n:]
+		field, wireType := int(key>>3), key&7
+
+		var value uint64
+		var message []byte
+		switch wireType {
+		case 0:
+			value, n = binary.Uvarint(data)
+			if n <= 0 {
+				return fmt.Errorf("ungültiger Varint in Feld %d", field)
+			}
+			data = data[n:]
+		case 1:
+			if len(data) < 8 {
+				return fmt.Errorf("Feld %d ist abgeschnitten", field)
+			}
+			value = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case 2:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return fmt.Errorf("Feld %d ist abgeschnitten", field)
+			}
+			message = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case 5:
+			if len(data) < 4 {
+				return fmt.Errorf("Feld %d ist abgeschnitten", field)
+			}
+			value = uint64(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		default:
+			return fmt.Errorf("nicht unterstützter Wire-Typ %d in Feld %d", wireType, field)
+		}
+		if err := fn(field, value, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// protoVarints liefert die Werte eines wiederholten Zahlenfelds, das entweder
+// einzeln (value) oder gepackt (packed) kodiert ist
+func protoVarints(value uint64, packed []byte) []uint64 {
+	if packed == nil {
+		return []uint64{value}
+	}
+	var values []uint64
+	for len(packed) > 0 {
+		v, n := binary.Uvarint(packed)
+		if n <= 0 {
+			break
+		}
+		values = append(values, v)
+		packed = packed[n:]
+	}
+	return values
+}
+
+// profileMethod zerlegt einen pprof-Funktionsnamen wie
+// "example.com/app/store.(*Repo).Add.func1" in Paket, Typ und Methode.
+// Paketfunktionen liefern einen leeren Typ.
+func profileMethod(function string) (pkg, typeName, method string) {
+	slash := strings.LastIndex(function, "/")
+	rest := function[slash+1:]
+	dot := strings.Index(rest, ".")
+	if dot < 0 {
+		return "", "", ""
+	}
+	pkg, rest = rest[:dot], rest[dot+1:]
+
+	// Typargumente generischer Typen entfernen: Stack[...]
+	if i := strings.Index(rest, "["); i >= 0 {
+		if j := strings.Index(rest[i:], "]"); j >= 0 {
+			rest = rest[:i] + rest[i+j+1:]
+		}
+	}
+	rest = strings.TrimPrefix(rest, "(*")
+	rest = strings.Replace(rest, ")", "", 1)
+
+	parts := strings.Split(rest, ".")
+	if len(parts) < 2 {
+		return pkg, "", ""
+	}
+	return pkg, parts[0], parts[1]
+}
+
+// ApplyProfile ordnet die CPU-Zeit eines pprof-Profils den Typen des Modells
+// zu. Jedes Sample zählt für jeden Typ auf seinem Stack einmal (kumulierte
+// Zeit), Methoden werden entsprechend aufgeschlüsselt. Typen ab
+// hotShareThreshold werden im Diagramm eingefärbt und mit ihrem Anteil
+// beschriftet.
+func (g *UMLGenerator) ApplyProfile(path string) error {
+	profile, err := readCPUProfile(path)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Lesen des Profils %s: %v", path, err)
+	}
+
+	var total int64
+	typeTime := make(map[string]int64)
+	methodTime := make(map[string]map[string]int64)
+	for i, stack := range profile.stacks {
+		value := profile.values[i]
+		total += value
+		seenTypes := make(map[string]bool)
+		seenMethods := make(map[[2]string]bool)
+		for _, function := range stack {
+			pkg, typeName, method := profileMethod(function)
+			if typeName == "" {
+				continue
+			}
+			if typePkg, ok := g.typePackage(typeName); !ok || typePkg != pkg {
+				continue
+			}
+			if !seenTypes[typeName] {
+				seenTypes[typeName] = true
+				typeTime[typeName] += value
+			}
+			if key := [2]string{typeName, method}; !seenMethods[key] {
+				seenMethods[key] = true
+				if methodTime[typeName] == nil {
+					methodTime[typeName] = make(map[string]int64)
+				}
+				methodTime[typeName][method] += value
+			}
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	for typeName, value := range typeTime {
+		share := float64(value) / float64(total)
+		if share < hotShareThreshold {
+			continue
+		}
+		if namedInfo, ok := g.namedTypes[typeName]; ok {
+			namedInfo.CPUShare = share
+			continue
+		}
+		structInfo, ok := g.structs[typeName]
+		if !ok {
+			continue
+		}
+		structInfo.CPUShare = share
+
+		methods := sortedMapKeys(methodTime[typeName])
+		sort.SliceStable(methods, func(i, j int) bool {
+			return methodTime[typeName][methods[i]] > methodTime[typeName][methods[j]]
+		})
+		var parts []string
+		for _, method := range methods {
+			parts = append(parts, fmt.Sprintf("%s %.1f %%", method, 100*float64(methodTime[typeName][method])/float64(total)))
+		}
+		structInfo.Notes = append(structInfo.Notes, "CPU: "+strings.Join(parts, ", "))
+	}
+	return nil
+}
+
+// formatHotness liefert Stereotyp und Farbangabe für den Klassenkopf eines
+// Typs mit nennenswertem CPU-Anteil (--pprof)
+func formatHotness(share float64) string {
+	if share < hotShareThreshold {
+		return ""
+	}
+	color := "#FFE699"
+	switch {
+	case share >= 0.25:
+		color = "#FF6666"
+	case share >= 0.10:
+		color = "#FFB366"
+	}
+	return fmt.Sprintf(" <<CPU %.1f %%>> %s;line:red;line.bold", 100*share, color)
+}
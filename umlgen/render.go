@@ -0,0 +1,347 @@
+package umlgen
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRenderers ist die voreingestellte Renderer-Kette. Server werden nur
+// auf Wunsch genutzt, weil dabei Quelltextstrukturen das Haus verlassen.
+const defaultRenderers = "jar,puml"
+
+// Voreingestellte Adressen der Server-Renderer
+const (
+	defaultLocalServer = "http://localhost:8080"
+	defaultKrokiURL    = "https://kroki.io"
+)
+
+// renderTimeout begrenzt die Dauer einer Anfrage an einen Server-Renderer
+const renderTimeout = 30 * time.Second
+
+// Renderer erzeugt aus PlantUML-Quelltext ein PNG
+type Renderer interface {
+	Name() string
+	Render(source []byte) ([]byte, error)
+}
+
+// errUnavailable kennzeichnet Renderer, die gar nicht erst nutzbar sind
+// (fehlendes Java, Server nicht erreichbar), im Unterschied zu Renderfehlern
+var errUnavailable = errors.New("nicht verfügbar")
+
+// validRenderers listet die erlaubten Namen für --renderers
+var validRenderers = map[string]bool{
+	"jar":    true,
+	"local":  true,
+	"kroki":  true,
+	"public": true,
+	"puml":   true,
+}
+
+// parseRenderers prüft eine kommagetrennte Renderer-Liste
+func parseRenderers(value string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !validRenderers[name] {
+			return nil, fmt.Errorf("unbekannter Renderer %q (erlaubt: jar, local, kroki, public, puml)", name)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// renderChain baut die Renderer-Kette aus --renderers auf. "puml" beendet
+// die Kette; danach folgende Einträge werden ignoriert. Mit --puml-only ist
+// die Kette leer.
+func (g *UMLGenerator) renderChain() []Renderer {
+	if g.renderers != nil {
+		return g.renderers
+	}
+	if g.options.PumlOnly {
+		g.renderers = []Renderer{}
+		return g.renderers
+	}
+
+	value := g.options.Renderers
+	if value == "" {
+		value = defaultRenderers
+	}
+	names, _ := parseRenderers(value)
+
+	chain := []Renderer{}
+	for _, name := range names {
+		switch name {
+		case "jar":
+			chain = append(chain, &jarRenderer{options: g.options})
+		case "local":
+			chain = append(chain, serverRenderer{name: name, baseURL: optionOr(g.options.LocalServer, defaultLocalServer)})
+		case "kroki":
+			chain = append(chain, serverRenderer{name: name, baseURL: optionOr(g.options.KrokiURL, defaultKrokiURL), kroki: true})
+		case "public":
+			chain = append(chain, serverRenderer{name: name, baseURL: optionOr(g.options.Server, defaultPlantUMLServer)})
+		}
+		if name == "puml" {
+			break
+		}
+	}
+	g.renderers = chain
+	return chain
+}
+
+// optionOr liefert value oder, falls leer, fallback
+func optionOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// renderPNG erzeugt das PNG zu einer .puml-Datei mit dem ersten Renderer der
+// Kette, der erfolgreich ist, und meldet, welcher Renderer es erzeugt hat
+func (g *UMLGenerator) renderPNG(outputDir, fileName, plantUMLFilePath string) error {
+	if g.options.PumlOnly {
+		return nil
+	}
+	if g.options.RenderQueue != "" {
+		return g.enqueueRender(outputDir, fileName, plantUMLFilePath)
+	}
+
+	source, err := os.ReadFile(plantUMLFilePath)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Lesen der PlantUML-Datei: %v", err)
+	}
+
+	var failures []string
+	renderFailed := false
+	for _, renderer := range g.renderChain() {
+		png, err := renderer.Render(source)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", renderer.Name(), err))
+			renderFailed = renderFailed || !errors.Is(err, errUnavailable)
+			continue
+		}
+
+		pngFilePath := filepath.Join(outputDir, fileName+".png")
+		var previous []byte
+		if g.options.Diff {
+			previous, _ = os.ReadFile(pngFilePath)
+		}
+		if err := writeFileFrom(pngFilePath, bytes.NewReader(png), g.fileMode()); err != nil {
+			return fmt.Errorf("Fehler beim Speichern des PNG: %v", err)
+		}
+		fmt.Printf("UML-Diagramm erstellt: %s (%s)\n", pngFilePath, renderer.Name())
+		if err := g.recordArtifact(outputDir, pngFilePath, "png", renderer.Name()); err != nil {
+			return err
+		}
+		if previous != nil {
+			return g.writeImageDiff(outputDir, fileName, previous, png)
+		}
+		return nil
+	}
+
+	if renderFailed {
+		return fmt.Errorf("Fehler beim Rendern von %s:\n  %s", plantUMLFilePath, strings.Join(failures, "\n  "))
+	}
+
+	fmt.Println("Hinweis: kein Renderer verfügbar. Nur .puml-Datei wurde erstellt.")
+	for _, failure := range failures {
+		fmt.Printf("  %s\n", failure)
+	}
+	fmt.Println("Um ein PNG-Bild zu erzeugen, führen Sie folgenden Befehl aus:")
+	fmt.Printf("java -jar plantuml.jar %s\n", plantUMLFilePath)
+	return nil
+}
+
+// pipeDelimiter trennt im -pipe-Modus die Bilder in der Ausgabe von PlantUML
+const pipeDelimiter = "___GO_UML_GENERATOR_END___"
+
+// jarRenderer rendert mit einer lokalen plantuml.jar. Die JVM läuft im
+// -pipe-Modus dauerhaft weiter, damit im Watch-Modus nicht jedes Rendern die
+// Startzeit der JVM bezahlt. Stirbt der Prozess, wird er beim nächsten
+// Aufruf neu gestartet. plantuml.jar wird erst beim ersten Rendern beschafft.
+type jarRenderer struct {
+	options        Options
+	downloadFailed bool // Download schon einmal gescheitert, nicht erneut versuchen
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+func (r *jarRenderer) Name() string { return "jar" }
+
+func (r *jarRenderer) Render(source []byte) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cmd == nil {
+		if err := r.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	type result struct {
+		png []byte
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		png, err := roundTrip(r.stdin, r.stdout, source)
+		done <- result{png, err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			r.stop()
+			return nil, res.err
+		}
+		return res.png, nil
+	case <-time.After(renderTimeout):
+		r.stop()
+		return nil, fmt.Errorf("PlantUML antwortet nicht nach %s", renderTimeout)
+	}
+}
+
+// start startet die JVM im -pipe-Modus
+func (r *jarRenderer) start() error {
+	if _, err := exec.LookPath("java"); err != nil {
+		return fmt.Errorf("%w: java nicht im PATH", errUnavailable)
+	}
+
+	options := r.options
+	options.NoDownload = options.NoDownload || r.downloadFailed
+	jarPath, err := ensurePlantUMLJar(options)
+	if err != nil {
+		r.downloadFailed = true
+		return fmt.Errorf("%w: %v", errUnavailable, err)
+	}
+
+	cmd := exec.Command("java", "-jar", jarPath, "-pipe", "-tpng", "-pipedelimitor", pipeDelimiter)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	// Fehlermeldungen von PlantUML direkt durchreichen
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%w: %v", errUnavailable, err)
+	}
+
+	r.cmd = cmd
+	r.stdin = stdin
+	r.stdout = bufio.NewReader(stdout)
+	return nil
+}
+
+// roundTrip schickt ein Diagramm an die JVM und liest das Bild bis zum Trenner
+func roundTrip(stdin io.Writer, stdout *bufio.Reader, source []byte) ([]byte, error) {
+	if _, err := stdin.Write(source); err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(stdin, "\n"); err != nil {
+		return nil, err
+	}
+
+	var png []byte
+	for {
+		chunk, err := stdout.ReadBytes('\n')
+		png = append(png, chunk...)
+		for _, end := range []string{pipeDelimiter + "\n", pipeDelimiter + "\r\n"} {
+			if bytes.HasSuffix(png, []byte(end)) {
+				return png[:len(png)-len(end)], nil
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// stop beendet die JVM; der nächste Aufruf startet sie neu
+func (r *jarRenderer) stop() {
+	if r.cmd == nil {
+		return
+	}
+	r.stdin.Close()
+	r.cmd.Process.Kill()
+	r.cmd.Wait()
+	r.cmd = nil
+}
+
+// serverRenderer rendert über einen PlantUML-Server oder Kroki
+type serverRenderer struct {
+	name    string
+	baseURL string
+	kroki   bool          // Kroki-API (POST /plantuml/png) statt PlantUML-Server (GET /png/<kodiert>)
+	timeout time.Duration // Zeitlimit je Anfrage, 0 = renderTimeout
+}
+
+func (r serverRenderer) Name() string { return r.name }
+
+func (r serverRenderer) Render(source []byte) ([]byte, error) {
+	client := &http.Client{Timeout: renderTimeout}
+	if r.timeout > 0 {
+		client.Timeout = r.timeout
+	}
+	base := strings.TrimSuffix(r.baseURL, "/")
+
+	var resp *http.Response
+	var err error
+	if r.kroki {
+		resp, err = client.Post(base+"/plantuml/png", "text/plain", bytes.NewReader(source))
+	} else {
+		resp, err = client.Get(base + "/png/" + encodePlantUML(source))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: HTTP %d", base, resp.StatusCode)
+	}
+	return body, nil
+}
+
+// plantUMLEncoding ist das Base64-Alphabet der PlantUML-Textkodierung
+var plantUMLEncoding = base64.NewEncoding("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-_").WithPadding(base64.NoPadding)
+
+// encodePlantUML kodiert Quelltext für PlantUML-Server-URLs
+// (Deflate plus PlantUML-Base64)
+func encodePlantUML(source []byte) string {
+	var buf bytes.Buffer
+	writer, _ := flate.NewWriter(&buf, flate.BestCompression)
+	writer.Write(source)
+	writer.Close()
+
+	// PlantUML kodiert immer ganze Dreiergruppen
+	for buf.Len()%3 != 0 {
+		buf.WriteByte(0)
+	}
+	return plantUMLEncoding.EncodeToString(buf.Bytes())
+}
@@ -0,0 +1,202 @@
+package umlgen
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultRedisQueueKey ist die Liste, in die Aufträge ohne Schlüssel in der
+// URL eingereiht werden
+const defaultRedisQueueKey = "go-uml-generator:jobs"
+
+// redisTimeout begrenzt Verbindungsaufbau und Antwort von Redis
+const redisTimeout = 10 * time.Second
+
+// RenderJob ist ein Auftrag an externe PlantUML-Worker (--render-queue).
+// Die Worker schreiben <Output>/<ID>.<Format> für jedes gewünschte Format.
+type RenderJob struct {
+	ID      string    `json:"id"`      // Dateiname ohne Endung, z.B. uml_diagram oder cards/auth.User
+	Source  string    `json:"source"`  // PlantUML-Text
+	Formats []string  `json:"formats"` // Gewünschte Formate, z.B. png, svg
+	Output  string    `json:"output"`  // Absolutes Ausgabeverzeichnis
+	SHA256  string    `json:"sha256"`  // Hash des PlantUML-Texts
+	Created time.Time `json:"created"`
+}
+
+// renderQueue ist ein Verzeichnis, in das je Auftrag eine JSON-Datei
+// geschrieben wird, oder eine Redis-Liste (RPUSH). Die Redis-Verbindung wird
+// beim ersten Auftrag aufgebaut und für alle Aufträge eines Laufs genutzt.
+type renderQueue struct {
+	dir      string
+	addr     string // Redis-Adresse host:port
+	password string
+	key      string
+	formats  []string // Formate aus --queue-formats
+	conn     net.Conn
+	reader   *bufio.Reader
+}
+
+// parseRenderQueue zerlegt das Ziel von --render-queue: redis://[:passwort@]host:port[/schlüssel]
+// für eine Redis-Liste, sonst ein Verzeichnis
+func parseRenderQueue(raw string) (*renderQueue, error) {
+	if !strings.HasPrefix(raw, "redis://") {
+		return &renderQueue{dir: raw}, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("ungültige Render-Warteschlange %q (erwartet redis://host:port/schlüssel oder ein Verzeichnis)", raw)
+	}
+	queue := &renderQueue{addr: u.Host, key: optionOr(strings.Trim(u.Path, "/"), defaultRedisQueueKey)}
+	if u.Port() == "" {
+		queue.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		queue.password, _ = u.User.Password()
+	}
+	return queue, nil
+}
+
+// push reiht einen Auftrag ein
+func (q *renderQueue) push(job RenderJob, mode os.FileMode) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	if q.dir != "" {
+		name := strings.ReplaceAll(job.ID, "/", "_") + ".json"
+		return writeFileFrom(filepath.Join(q.dir, name), bytes.NewReader(data), mode)
+	}
+
+	if err := q.connect(); err != nil {
+		return err
+	}
+	q.conn.SetDeadline(time.Now().Add(redisTimeout))
+	if err := redisCommand(q.conn, q.reader, "RPUSH", q.key, string(data)); err != nil {
+		// Nach einem Fehler ist der Zustand der Verbindung unklar
+		q.close()
+		return err
+	}
+	return nil
+}
+
+// connect baut die Redis-Verbindung auf, sofern sie noch nicht besteht
+func (q *renderQueue) connect() error {
+	if q.conn != nil {
+		return nil
+	}
+	conn, err := net.DialTimeout("tcp", q.addr, redisTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(redisTimeout))
+	reader := bufio.NewReader(conn)
+	if q.password != "" {
+		if err := redisCommand(conn, reader, "AUTH", q.password); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+	q.conn, q.reader = conn, reader
+	return nil
+}
+
+// close beendet die Redis-Verbindung
+func (q *renderQueue) close() {
+	if q.conn != nil {
+		q.conn.Close()
+		q.conn, q.reader = nil, nil
+	}
+}
+
+// redisCommand sendet einen Befehl im RESP-Protokoll und prüft die Antwort
+func redisCommand(conn net.Conn, reader *bufio.Reader, args ...string) error {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("*%d\r\n", len(args)))
+	for _, arg := range args {
+		sb.WriteString(fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg))
+	}
+	if _, err := conn.Write([]byte(sb.String())); err != nil {
+		return err
+	}
+	reply, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(reply, "-") {
+		return fmt.Errorf("Redis: %s", strings.TrimSpace(reply[1:]))
+	}
+	return nil
+}
+
+// queueTarget liefert das einmal zerlegte Ziel von --render-queue samt der
+// bereinigten Formate aus --queue-formats
+func (g *UMLGenerator) queueTarget() (*renderQueue, error) {
+	if g.queue != nil {
+		return g.queue, nil
+	}
+	queue, err := parseRenderQueue(g.options.RenderQueue)
+	if err != nil {
+		return nil, err
+	}
+	for _, format := range strings.Split(g.options.QueueFormats, ",") {
+		if format = strings.TrimSpace(format); format != "" {
+			queue.formats = append(queue.formats, format)
+		}
+	}
+	if queue.dir != "" {
+		if err := os.MkdirAll(queue.dir, g.dirMode()); err != nil {
+			return nil, fmt.Errorf("Fehler beim Erstellen der Render-Warteschlange: %v", err)
+		}
+	}
+	g.queue = queue
+	return queue, nil
+}
+
+// closeRenderQueue schließt am Ende eines Laufs die Redis-Verbindung
+func (g *UMLGenerator) closeRenderQueue() {
+	if g.queue != nil {
+		g.queue.close()
+	}
+}
+
+// enqueueRender reiht eine .puml-Datei als Auftrag ein, statt sie selbst zu
+// rendern (--render-queue)
+func (g *UMLGenerator) enqueueRender(outputDir, fileName, plantUMLFilePath string) error {
+	queue, err := g.queueTarget()
+	if err != nil {
+		return err
+	}
+	source, err := os.ReadFile(plantUMLFilePath)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Lesen der PlantUML-Datei: %v", err)
+	}
+	absOutput, err := filepath.Abs(outputDir)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(source)
+
+	job := RenderJob{
+		ID:      fileName,
+		Source:  string(source),
+		Formats: queue.formats,
+		Output:  absOutput,
+		SHA256:  hex.EncodeToString(sum[:]),
+		Created: time.Now().UTC(),
+	}
+	if err := queue.push(job, g.fileMode()); err != nil {
+		return fmt.Errorf("Fehler beim Einreihen von %s: %v", fileName, err)
+	}
+	fmt.Printf("Renderauftrag eingereiht: %s\n", fileName)
+	return nil
+}
@@ -0,0 +1,382 @@
+package umlgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Auswahlausdrücke (--select) filtern das Modell nach Typen, z.B.
+//
+//	type.name =~ "Repo$" && relations.to contains "DB"
+//
+// Pfade:
+//
+//	type.name, type.kind              Name und Art ("struct", "interface", "type")
+//	fields.name, fields.type          Felder des Typs
+//	methods.name                      Methoden des Typs
+//	relations.to, relations.type      ausgehende Beziehungen
+//	relations.from                    eingehende Beziehungen (Quelle)
+//
+// Operatoren: ==, !=, =~, !~, contains; Verknüpfung mit &&, ||, ! und Klammern.
+// Listenwertige Pfade sind erfüllt, wenn irgendein Element passt; != und !~
+// verlangen, dass kein Element passt.
+
+// SelectExpr ist ein geparster Auswahlausdruck
+type SelectExpr interface {
+	eval(subject *selectSubject) bool
+}
+
+// selectSubject enthält die abfragbaren Werte eines Typs
+type selectSubject struct {
+	values map[string][]string
+}
+
+type selectAnd struct{ left, right SelectExpr }
+type selectOr struct{ left, right SelectExpr }
+type selectNot struct{ expr SelectExpr }
+
+type selectCompare struct {
+	path  string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (e selectAnd) eval(s *selectSubject) bool { return e.left.eval(s) && e.right.eval(s) }
+func (e selectOr) eval(s *selectSubject) bool  { return e.left.eval(s) || e.right.eval(s) }
+func (e selectNot) eval(s *selectSubject) bool { return !e.expr.eval(s) }
+
+func (e selectCompare) eval(s *selectSubject) bool {
+	values := s.values[e.path]
+
+	switch e.op {
+	case "!=":
+		return !anyValue(values, func(v string) bool { return v == e.value })
+	case "!~":
+		return !anyValue(values, e.re.MatchString)
+	case "==":
+		return anyValue(values, func(v string) bool { return v == e.value })
+	case "=~":
+		return anyValue(values, e.re.MatchString)
+	case "contains":
+		return anyValue(values, func(v string) bool { return strings.Contains(v, e.value) })
+	}
+	return false
+}
+
+func anyValue(values []string, match func(string) bool) bool {
+	for _, v := range values {
+		if match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectPaths listet alle gültigen Pfade eines Auswahlausdrucks
+var selectPaths = map[string]bool{
+	"type.name":      true,
+	"type.kind":      true,
+	"fields.name":    true,
+	"fields.type":    true,
+	"methods.name":   true,
+	"relations.to":   true,
+	"relations.from": true,
+	"relations.type": true,
+}
+
+// ParseSelection parst einen Auswahlausdruck
+func ParseSelection(input string) (SelectExpr, error) {
+	tokens, err := tokenizeSelection(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &selectParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("Fehler im Auswahlausdruck: unerwartetes %q", p.tokens[p.pos].text)
+	}
+	return expr, nil
+}
+
+type selectToken struct {
+	text   string
+	quoted bool
+}
+
+func tokenizeSelection(input string) ([]selectToken, error) {
+	var tokens []selectToken
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			var sb strings.Builder
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("Fehler im Auswahlausdruck: nicht geschlossene Zeichenkette")
+			}
+			i++
+			tokens = append(tokens, selectToken{text: sb.String(), quoted: true})
+		case r == '(' || r == ')':
+			tokens = append(tokens, selectToken{text: string(r)})
+			i++
+		default:
+			// Zweistellige Operatoren
+			if i+1 < len(runes) {
+				op := string(runes[i : i+2])
+				switch op {
+				case "&&", "||", "==", "!=", "=~", "!~":
+					tokens = append(tokens, selectToken{text: op})
+					i += 2
+					continue
+				}
+			}
+			if r == '!' {
+				tokens = append(tokens, selectToken{text: "!"})
+				i++
+				continue
+			}
+
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == '_') {
+				i++
+			}
+			if start == i {
+				return nil, fmt.Errorf("Fehler im Auswahlausdruck: unerwartetes Zeichen %q", r)
+			}
+			tokens = append(tokens, selectToken{text: string(runes[start:i])})
+		}
+	}
+
+	return tokens, nil
+}
+
+type selectParser struct {
+	tokens []selectToken
+	pos    int
+}
+
+func (p *selectParser) peek() (selectToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return selectToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *selectParser) next() (selectToken, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return tok, fmt.Errorf("Fehler im Auswahlausdruck: unerwartetes Ende")
+	}
+	p.pos++
+	return tok, nil
+}
+
+func (p *selectParser) parseOr() (SelectExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.quoted || tok.text != "||" {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = selectOr{left, right}
+	}
+}
+
+func (p *selectParser) parseAnd() (SelectExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.quoted || tok.text != "&&" {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = selectAnd{left, right}
+	}
+}
+
+func (p *selectParser) parseUnary() (SelectExpr, error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if !tok.quoted {
+		switch tok.text {
+		case "!":
+			expr, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return selectNot{expr}, nil
+		case "(":
+			expr, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			closing, err := p.next()
+			if err != nil || closing.quoted || closing.text != ")" {
+				return nil, fmt.Errorf("Fehler im Auswahlausdruck: ')' erwartet")
+			}
+			return expr, nil
+		}
+	}
+
+	// Vergleich: <Pfad> <Operator> <Wert>
+	if tok.quoted || !selectPaths[tok.text] {
+		return nil, fmt.Errorf("Fehler im Auswahlausdruck: unbekannter Pfad %q", tok.text)
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch op.text {
+	case "==", "!=", "=~", "!~", "contains":
+	default:
+		return nil, fmt.Errorf("Fehler im Auswahlausdruck: unbekannter Operator %q", op.text)
+	}
+
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := selectCompare{path: tok.text, op: op.text, value: value.text}
+	if op.text == "=~" || op.text == "!~" {
+		cmp.re, err = regexp.Compile(value.text)
+		if err != nil {
+			return nil, fmt.Errorf("Fehler im Auswahlausdruck: ungültiger regulärer Ausdruck %q: %v", value.text, err)
+		}
+	}
+	return cmp, nil
+}
+
+// ApplySelection entfernt alle Typen, die den Auswahlausdruck nicht erfüllen,
+// samt ihrer Beziehungen aus dem Modell
+func (g *UMLGenerator) ApplySelection(input string) error {
+	expr, err := ParseSelection(input)
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool)
+	for name, structInfo := range g.structs {
+		if expr.eval(g.selectSubject("struct", name, structInfo.Fields, structInfo.Methods)) {
+			keep[name] = true
+		}
+	}
+	for name, interfaceInfo := range g.interfaces {
+		if expr.eval(g.selectSubject("interface", name, nil, interfaceInfo.Methods)) {
+			keep[name] = true
+		}
+	}
+	for name, namedInfo := range g.namedTypes {
+		if expr.eval(g.selectSubject("type", name, nil, namedInfo.Methods)) {
+			keep[name] = true
+		}
+	}
+
+	g.retainTypes(keep)
+	return nil
+}
+
+func (g *UMLGenerator) selectSubject(kind, name string, fields []FieldInfo, methods []MethodInfo) *selectSubject {
+	values := map[string][]string{
+		"type.name": {name},
+		"type.kind": {kind},
+	}
+	for _, field := range fields {
+		values["fields.name"] = append(values["fields.name"], field.Name)
+		values["fields.type"] = append(values["fields.type"], field.Type)
+	}
+	for _, method := range methods {
+		values["methods.name"] = append(values["methods.name"], method.Name)
+	}
+	for _, relation := range g.relations {
+		if relation.From == name {
+			values["relations.to"] = append(values["relations.to"], relation.To)
+			values["relations.type"] = append(values["relations.type"], relation.Type)
+		}
+		if relation.To == name {
+			values["relations.from"] = append(values["relations.from"], relation.From)
+		}
+	}
+	return &selectSubject{values: values}
+}
+
+// retainTypes behält nur die angegebenen Typen und die Beziehungen zwischen ihnen
+func (g *UMLGenerator) retainTypes(keep map[string]bool) {
+	// Kontextelemente gehören nicht zum Code und bleiben immer erhalten
+	for name := range g.elements {
+		keep[name] = true
+	}
+	for name := range g.structs {
+		if !keep[name] {
+			delete(g.structs, name)
+		}
+	}
+	for name := range g.interfaces {
+		if !keep[name] {
+			delete(g.interfaces, name)
+		}
+	}
+	for name := range g.namedTypes {
+		if !keep[name] {
+			delete(g.namedTypes, name)
+		}
+	}
+
+	// Factories bleiben erhalten, solange eines ihrer Ziele erhalten bleibt
+	for name, factory := range g.factories {
+		kept := keep[factory.Exposes]
+		for _, created := range factory.Creates {
+			kept = kept || keep[created]
+		}
+		if kept {
+			keep[name] = true
+		} else {
+			delete(g.factories, name)
+		}
+	}
+
+	var relations []Relation
+	for _, relation := range g.relations {
+		if keep[relation.From] && keep[relation.To] {
+			relations = append(relations, relation)
+		}
+	}
+	g.relations = relations
+}
@@ -0,0 +1,87 @@
+package umlgen
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSelectionErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"unbekannter Pfad", `type.size == "1"`},
+		{"unbekannter Operator", `type.name > "A"`},
+		{"fehlender Wert", `type.name ==`},
+		{"ungültiger regulärer Ausdruck", `type.name =~ "("`},
+		{"offene Klammer", `(type.name == "A"`},
+		{"überzähliges Token", `type.name == "A" )`},
+		{"Pfad in Anführungszeichen", `"type.name" == "A"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSelection(tt.input); err == nil {
+				t.Errorf("ParseSelection(%q) ohne Fehler", tt.input)
+			}
+		})
+	}
+}
+
+func TestApplySelection(t *testing.T) {
+	files := map[string]string{"repo/repo.go": `package repo
+
+type DB interface {
+	Query(sql string) error
+}
+
+type UserRepo struct {
+	db   DB
+	Name string
+}
+
+func (r *UserRepo) Find(id int) error { return nil }
+
+type OrderRepo struct {
+	Users *UserRepo
+}
+
+type Status int
+`}
+
+	tests := []struct {
+		name      string
+		selection string
+		wantTypes []string
+	}{
+		{"Name mit regulärem Ausdruck", `type.name =~ "Repo$"`, []string{"OrderRepo", "UserRepo"}},
+		{"Art", `type.kind == "interface"`, []string{"DB"}},
+		{"Negation", `!(type.kind == "struct")`, []string{"DB", "Status"}},
+		{"Feldtyp", `fields.type contains "DB"`, []string{"UserRepo"}},
+		{"Methode", `methods.name == "Find"`, []string{"UserRepo"}},
+		{"ausgehende Beziehung", `relations.to == "UserRepo"`, []string{"OrderRepo"}},
+		{"eingehende Beziehung", `relations.from == "OrderRepo"`, []string{"UserRepo"}},
+		{"!= verlangt, dass kein Element passt", `type.kind == "struct" && fields.name != "Name"`, []string{"OrderRepo"}},
+		{"Oder", `type.name == "Status" || type.name == "DB"`, []string{"DB", "Status"}},
+		{"nichts passt", `type.name == "Missing"`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTree(t, dir, files)
+
+			g := NewUMLGenerator(Options{Select: tt.selection})
+			if err := g.GenerateUMLFromDirectory(dir); err != nil {
+				t.Fatal(err)
+			}
+			if got := typeNames(g); !slices.Equal(got, tt.wantTypes) {
+				t.Errorf("Typen = %v, erwartet %v", got, tt.wantTypes)
+			}
+			for _, relation := range g.relations {
+				if !g.isKnownType(relation.From) || !g.isKnownType(relation.To) {
+					t.Errorf("Beziehung %s -> %s zu entferntem Typ", relation.From, relation.To)
+				}
+			}
+		})
+	}
+}
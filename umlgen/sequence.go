@@ -0,0 +1,141 @@
+package umlgen
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nichtaru64/go-uml-generator/umltrace"
+)
+
+// sequenceCaller ist der Teilnehmer für Aufrufe ohne aufzeichnenden Aufrufer
+const sequenceCaller = "Aufrufer"
+
+// readTrace liest eine mit dem Paket umltrace aufgezeichnete Datei
+func readTrace(path string) ([]umltrace.Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []umltrace.Event
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var event umltrace.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time < events[j].Time })
+	return events, nil
+}
+
+// GenerateSequencePlantUML wandelt die Events in ein Sequenzdiagramm. Pro
+// Goroutine wird ein Aufrufstapel geführt: der Typ oben auf dem Stapel ist
+// der Aufrufer des nächsten Eintritts. Laufen mehrere Goroutinen, werden die
+// Nachrichten mit ihrer Nummer beschriftet.
+func GenerateSequencePlantUML(events []umltrace.Event) string {
+	goroutines := make(map[uint64]bool)
+	var participants []string
+	for _, event := range events {
+		goroutines[event.Goroutine] = true
+		participants = appendUnique(participants, event.Type)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("@startuml\n\n")
+	sb.WriteString(fmt.Sprintf("actor %s\n", sequenceCaller))
+	for _, participant := range participants {
+		sb.WriteString(fmt.Sprintf("participant %s\n", participant))
+	}
+	sb.WriteString("\n")
+
+	stacks := make(map[uint64][]string)
+	for _, event := range events {
+		prefix := ""
+		if len(goroutines) > 1 {
+			prefix = fmt.Sprintf("[g%d] ", event.Goroutine)
+		}
+		stack := stacks[event.Goroutine]
+		caller := sequenceCaller
+		if len(stack) > 0 {
+			caller = stack[len(stack)-1]
+		}
+
+		switch event.Kind {
+		case "enter":
+			sb.WriteString(fmt.Sprintf("%s -> %s : %s%s()\n", caller, event.Type, prefix, event.Method))
+			sb.WriteString(fmt.Sprintf("activate %s\n", event.Type))
+			stacks[event.Goroutine] = append(stack, event.Type)
+		case "exit":
+			if len(stack) == 0 || stack[len(stack)-1] != event.Type {
+				continue // Austritt ohne passenden Eintritt
+			}
+			stack = stack[:len(stack)-1]
+			stacks[event.Goroutine] = stack
+			returnTo := sequenceCaller
+			if len(stack) > 0 {
+				returnTo = stack[len(stack)-1]
+			}
+			sb.WriteString(fmt.Sprintf("%s --> %s\n", event.Type, returnTo))
+			sb.WriteString(fmt.Sprintf("deactivate %s\n", event.Type))
+		}
+	}
+
+	sb.WriteString("\n@enduml")
+	return sb.String()
+}
+
+// runSequence ist der Unterbefehl "sequence": er wandelt eine Aufzeichnung
+// des Pakets umltrace in ein Sequenzdiagramm
+func runSequence(args []string) int {
+	fs := flag.NewFlagSet("sequence", flag.ExitOnError)
+	var options Options
+	fs.BoolVar(&options.PumlOnly, "puml-only", false, "nur .puml-Dateien schreiben, keine Bilder erzeugen")
+	fs.StringVar(&options.Renderers, "renderers", defaultRenderers, "Renderer in Reihenfolge der Präferenz: jar, local, kroki, public, puml")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Verwendung: uml-watcher sequence [Optionen] <trace.jsonl> [Ausgabeverzeichnis]")
+		fs.PrintDefaults()
+	}
+	if err := applySubcommandConfig(fs); err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 2
+	}
+	outputDir := "output"
+	if fs.NArg() > 1 {
+		outputDir = fs.Arg(1)
+	}
+
+	events, err := readTrace(fs.Arg(0))
+	if err != nil {
+		fmt.Printf("Fehler beim Lesen der Aufzeichnung: %v\n", err)
+		return 1
+	}
+
+	// Dateiname nach der Aufzeichnung, damit mehrere Abläufe nebeneinander liegen
+	name := strings.TrimSuffix(filepath.Base(fs.Arg(0)), filepath.Ext(fs.Arg(0)))
+	g := NewUMLGenerator(options)
+	if err := g.writeDiagram(outputDir, "uml_sequence_"+name, GenerateSequencePlantUML(events)); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,133 @@
+package umlgen
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// viewerPage ist die Seite für Betrachter (--serve). Sie fragt jede Sekunde
+// das Manifest ab und lädt das Diagramm neu, sobald sich sein Inhalt ändert.
+// Ohne PNG (--puml-only) zeigt sie den PlantUML-Text.
+const viewerPage = `<!DOCTYPE html>
+<html lang="de">
+<head>
+<meta charset="utf-8">
+<title>go-uml-generator</title>
+<style>
+body { margin: 0; font-family: Verdana, Geneva, sans-serif; }
+header { padding: 6px 12px; background: #eee; font-size: 12px; color: #555; }
+img { display: block; max-width: 100%; margin: 12px auto; }
+pre { margin: 12px; }
+</style>
+</head>
+<body>
+<header id="status">Warte auf Diagramm …</header>
+<img id="diagram" alt="UML-Diagramm" hidden>
+<pre id="source" hidden></pre>
+<script>
+let version = "";
+async function poll() {
+	try {
+		const manifest = await (await fetch("manifest.json", {cache: "no-store"})).json();
+		const artifact = manifest.artifacts.find(a => a.path === "uml_diagram.png") ||
+			manifest.artifacts.find(a => a.path === "uml_diagram.puml");
+		if (artifact && artifact.sha256 !== version) {
+			version = artifact.sha256;
+			const image = document.getElementById("diagram");
+			const source = document.getElementById("source");
+			if (artifact.format === "png") {
+				image.src = artifact.path + "?v=" + version;
+				image.hidden = false;
+				source.hidden = true;
+			} else {
+				source.textContent = await (await fetch(artifact.path, {cache: "no-store"})).text();
+				source.hidden = false;
+				image.hidden = true;
+			}
+			document.getElementById("status").textContent = "Stand: " + new Date(manifest.generated).toLocaleTimeString();
+		}
+	} catch (e) {
+		document.getElementById("status").textContent = "Keine Verbindung zum Generator";
+	}
+	setTimeout(poll, 1000);
+}
+poll();
+</script>
+</body>
+</html>
+`
+
+// viewer stellt das Ausgabeverzeichnis im Watch-Modus schreibgeschützt per
+// HTTP bereit, so dass beliebig viele Betrachter das Diagramm live verfolgen
+// können (--serve). Mit --announce wird der Dienst per mDNS im lokalen Netz
+// angekündigt.
+type viewer struct {
+	server    *http.Server
+	url       string
+	announcer *mdnsAnnouncer
+}
+
+// startViewer startet den HTTP-Server auf addr, z.B. ":8000"
+func startViewer(outputDir, addr string, announce bool) (*viewer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Starten des Betrachters: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	files := http.FileServer(http.Dir(outputDir))
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Nur lesender Zugriff", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.URL.Path == "/" {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, viewerPage)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+
+	v := &viewer{
+		server: &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second},
+		url:    fmt.Sprintf("http://%s:%d/", viewerHost(), port),
+	}
+	go v.server.Serve(listener)
+	fmt.Printf("Betrachter gestartet: %s\n", v.url)
+
+	if announce {
+		v.announcer, err = startMDNSAnnouncer(port)
+		if err != nil {
+			v.Stop()
+			return nil, err
+		}
+		fmt.Printf("Per mDNS angekündigt als %s\n", v.announcer.instance)
+	}
+	return v, nil
+}
+
+// viewerHost liefert den Rechnernamen für die ausgegebene Adresse
+func viewerHost() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return "localhost"
+	}
+	return host
+}
+
+// Stop meldet den Dienst per mDNS ab und beendet den HTTP-Server
+func (v *viewer) Stop() {
+	if v.announcer != nil {
+		v.announcer.Stop()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	v.server.Shutdown(ctx)
+}
@@ -0,0 +1,160 @@
+package umlgen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// siteSection ist der Abschnitt der Dokumentationsseite für die Diagramme
+const siteSection = "architecture"
+
+// diagramTitle liefert eine lesbare Überschrift für einen Diagrammnamen
+func diagramTitle(name string) string {
+	switch {
+	case name == "uml_diagram":
+		return "Gesamtdiagramm"
+	case name == "uml_clusters":
+		return "Cluster-Übersicht"
+	case name == "uml_init_order":
+		return "Initialisierung"
+	case name == "uml_context_keys":
+		return "Context-Schlüssel"
+	case strings.HasPrefix(name, "uml_cluster_"):
+		return "Cluster " + strings.TrimPrefix(name, "uml_cluster_")
+	case strings.HasPrefix(name, "uml_package_"):
+		return "Paket " + strings.TrimPrefix(name, "uml_package_")
+	}
+	return name
+}
+
+// siteDiagram ist ein Diagramm des aktuellen Laufs für die Dokumentationsseite
+type siteDiagram struct {
+	name  string
+	puml  string // Pfad der .puml-Datei
+	image string // Pfad des PNG, leer ohne Renderer
+}
+
+// siteDiagrams sammelt die Diagramme des aktuellen Laufs aus den Artefakten
+func (g *UMLGenerator) siteDiagrams(outputDir string) []siteDiagram {
+	var diagrams []siteDiagram
+	index := make(map[string]int)
+	for _, artifact := range g.artifacts {
+		path := filepath.Join(outputDir, filepath.FromSlash(artifact.Path))
+		switch {
+		case artifact.Format == "puml":
+			name := strings.TrimSuffix(artifact.Path, ".puml")
+			index[name] = len(diagrams)
+			diagrams = append(diagrams, siteDiagram{name: name, puml: path})
+		case artifact.Format == "png" && artifact.Renderer != "diff":
+			if i, ok := index[strings.TrimSuffix(artifact.Path, ".png")]; ok {
+				diagrams[i].image = path
+			}
+		}
+	}
+	return diagrams
+}
+
+// GenerateSite legt die Diagramme als Markdown-Seiten in der Struktur ab, die
+// MkDocs (docs/architecture, .pages für awesome-pages) bzw. Hugo
+// (content/architecture mit Front Matter, Bilder unter static/) erwartet.
+// Ohne Bilder wird der PlantUML-Quelltext als Codeblock eingebettet.
+func (g *UMLGenerator) GenerateSite(outputDir, kind, siteDir string) error {
+	var sectionDir, imageDir, imageLink string
+	switch kind {
+	case "mkdocs":
+		sectionDir = filepath.Join(siteDir, "docs", siteSection)
+		imageDir = filepath.Join(sectionDir, "img")
+		imageLink = "img/"
+	case "hugo":
+		sectionDir = filepath.Join(siteDir, "content", siteSection)
+		imageDir = filepath.Join(siteDir, "static", siteSection, "img")
+		imageLink = "/" + siteSection + "/img/"
+	default:
+		return fmt.Errorf("unbekanntes Seitenformat %q (erlaubt: mkdocs, hugo)", kind)
+	}
+	for _, dir := range []string{sectionDir, imageDir} {
+		if err := os.MkdirAll(dir, g.dirMode()); err != nil {
+			return fmt.Errorf("Fehler beim Erstellen von %s: %v", dir, err)
+		}
+	}
+
+	diagrams := g.siteDiagrams(outputDir)
+	var index strings.Builder
+	var nav strings.Builder
+	nav.WriteString("nav:\n  - index.md\n")
+
+	for i, diagram := range diagrams {
+		title := diagramTitle(diagram.name)
+		alt := title
+		if diagram.name == "uml_diagram" {
+			alt = g.AltText()
+		}
+
+		var page strings.Builder
+		if kind == "hugo" {
+			page.WriteString(fmt.Sprintf("---\ntitle: %s\nweight: %d\ndescription: %s\n---\n\n", strconv.Quote(title), i+1, strconv.Quote(alt)))
+		} else {
+			page.WriteString(fmt.Sprintf("# %s\n\n", title))
+		}
+
+		if diagram.image != "" {
+			data, err := os.ReadFile(diagram.image)
+			if err != nil {
+				return err
+			}
+			imageName := filepath.Base(diagram.image)
+			if err := writeFileFrom(filepath.Join(imageDir, imageName), bytes.NewReader(data), g.fileMode()); err != nil {
+				return fmt.Errorf("Fehler beim Kopieren von %s: %v", imageName, err)
+			}
+			page.WriteString(fmt.Sprintf("![%s](%s)\n", alt, imageLink+imageName))
+		} else {
+			source, err := os.ReadFile(diagram.puml)
+			if err != nil {
+				return err
+			}
+			page.WriteString(fmt.Sprintf("```plantuml\n%s\n```\n", strings.TrimSpace(string(source))))
+		}
+
+		pageName := diagram.name + ".md"
+		if err := writeFileFrom(filepath.Join(sectionDir, pageName), strings.NewReader(page.String()), g.fileMode()); err != nil {
+			return fmt.Errorf("Fehler beim Schreiben von %s: %v", pageName, err)
+		}
+		index.WriteString(fmt.Sprintf("- [%s](%s)\n", title, pageLink(kind, diagram.name)))
+		nav.WriteString(fmt.Sprintf("  - %s\n", pageName))
+	}
+
+	indexName := "index.md"
+	var indexPage strings.Builder
+	if kind == "hugo" {
+		indexName = "_index.md"
+		indexPage.WriteString("---\ntitle: \"Architektur\"\n---\n\n")
+	} else {
+		indexPage.WriteString("# Architektur\n\n")
+	}
+	indexPage.WriteString("Automatisch aus dem Quelltext erzeugte Diagramme.\n\n")
+	indexPage.WriteString(index.String())
+	if err := writeFileFrom(filepath.Join(sectionDir, indexName), strings.NewReader(indexPage.String()), g.fileMode()); err != nil {
+		return fmt.Errorf("Fehler beim Schreiben von %s: %v", indexName, err)
+	}
+
+	if kind == "mkdocs" {
+		if err := writeFileFrom(filepath.Join(sectionDir, ".pages"), strings.NewReader("title: Architektur\n"+nav.String()), g.fileMode()); err != nil {
+			return fmt.Errorf("Fehler beim Schreiben von .pages: %v", err)
+		}
+	}
+
+	fmt.Printf("Dokumentationsseite aktualisiert: %s (%d Diagramme)\n", sectionDir, len(diagrams))
+	return nil
+}
+
+// pageLink liefert den Verweis von der Übersichtsseite auf eine Diagrammseite
+func pageLink(kind, name string) string {
+	if kind == "hugo" {
+		return name + "/"
+	}
+	return name + ".md"
+}
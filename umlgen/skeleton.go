@@ -0,0 +1,653 @@
+package umlgen
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// sketchType ist ein Typ aus einem PlantUML-Klassendiagramm, aus dem
+// Go-Code erzeugt wird
+type sketchType struct {
+	Kind       string // "class", "interface" oder "enum"
+	Key        string // Name im Diagramm, bei Namenskollisionen qualifiziert (auth.User)
+	Name       string
+	Package    string
+	TypeParams string   // Typparameter in Go-Schreibweise, z.B. "T any"
+	Underlying string   // Bei «type»: zugrundeliegender Typ, bei «alias»: Zieltyp
+	Alias      bool     // «alias»
+	Fields     []string // "name Typ"
+	Methods    []string // "Name(p Typ) Ergebnis"
+	Values     []string // Werte einer Aufzählung
+	Embeds     []string
+	Implements []string
+}
+
+// sketch ist ein eingelesenes Klassendiagramm
+type sketch struct {
+	types  []*sketchType
+	byName map[string]*sketchType // Name im Diagramm -> Typ
+}
+
+var (
+	sketchHeaderPattern   = regexp.MustCompile(`^(?:abstract\s+)?(class|interface|enum)\s+("[^"]*"|[\pL_][\pL\pN_]*(?:\.[\pL_][\pL\pN_]*)*)(?:\s+as\s+([\pL_][\pL\pN_]*))?(<[^<>]*>)?\s*((?:<<[^>]*>>\s*)*)(#\S*)?\s*(\{)?\s*$`)
+	sketchPackagePattern  = regexp.MustCompile(`^(?:package|namespace)\s+"?([^"\s{]+)"?.*\{\s*$`)
+	sketchRelationPattern = regexp.MustCompile(`^([\pL_][\pL\pN_]*(?:\.[\pL_][\pL\pN_]*)*)\s*(?:"([^"]*)"\s*)?(<\|--|--\|>|<\|\.\.|\.\.\|>|\*--|--\*|o--|--o|-->|<--|\.\.>|<\.\.|--|\.\.)\s*(?:"([^"]*)"\s*)?([\pL_][\pL\pN_]*(?:\.[\pL_][\pL\pN_]*)*)\s*(?::\s*(.*))?$`)
+	sketchIdentPattern    = regexp.MustCompile(`^[\pL_][\pL\pN_]*$`)
+	sketchTypePattern     = regexp.MustCompile(`[\pL_][\pL\pN_]*(?:\.[\pL_][\pL\pN_]*)*`)
+)
+
+// sketchStdlib bildet Paketnamen der Standardbibliothek, die in Typangaben
+// eines Diagramms vorkommen (context.Context, time.Duration), auf ihre
+// Importpfade ab. Bei mehrdeutigen Namen gilt das gebräuchlichere Paket.
+var sketchStdlib = map[string]string{
+	"bufio": "bufio", "bytes": "bytes", "context": "context", "driver": "database/sql/driver",
+	"ecdsa": "crypto/ecdsa", "ed25519": "crypto/ed25519", "errors": "errors", "fs": "io/fs",
+	"hash": "hash", "http": "net/http", "io": "io", "json": "encoding/json", "big": "math/big",
+	"log": "log", "mail": "net/mail", "multipart": "mime/multipart", "net": "net", "netip": "net/netip",
+	"os": "os", "rand": "math/rand", "reflect": "reflect", "regexp": "regexp", "rsa": "crypto/rsa",
+	"slog": "log/slog", "sql": "database/sql", "strings": "strings", "sync": "sync", "atomic": "sync/atomic",
+	"template": "text/template", "time": "time", "tls": "crypto/tls", "url": "net/url", "x509": "crypto/x509",
+	"xml": "encoding/xml", "exec": "os/exec", "signal": "os/signal", "token": "go/token", "ast": "go/ast",
+	"heap": "container/heap", "list": "container/list", "ring": "container/ring", "csv": "encoding/csv",
+	"binary": "encoding/binary", "base64": "encoding/base64", "hex": "encoding/hex", "flag": "flag",
+	"fmt": "fmt", "image": "image", "color": "image/color", "sort": "sort", "strconv": "strconv",
+	"tar": "archive/tar", "zip": "archive/zip", "gzip": "compress/gzip", "embed": "embed",
+	"unicode": "unicode", "utf8": "unicode/utf8", "iter": "iter", "cookiejar": "net/http/cookiejar",
+	"httptest": "net/http/httptest", "smtp": "net/smtp", "rpc": "net/rpc", "expvar": "expvar",
+}
+
+// parseSketch liest ein eingeschränktes PlantUML-Klassendiagramm: class-,
+// interface- und enum-Blöcke mit Feldern ("+name: Typ") und Methoden
+// ("+Name(p: Typ): Ergebnis"), package-Blöcke sowie Vererbungs-,
+// Implementierungs-, Kompositions-, Aggregations- und Assoziationskanten.
+// Qualifizierte Namen wie auth.User (bei Namenskollisionen) gehören zum
+// Paket des umgebenden package-Blocks, außerhalb davon zum Qualifizierer.
+// Nicht unterstützte Zeilen werden übersprungen.
+func parseSketch(r io.Reader, defaultPackage string) (*sketch, error) {
+	s := &sketch{byName: make(map[string]*sketchType)}
+	var packages []string // Verschachtelte package-Blöcke
+	var current *sketchType
+	var blocks []bool // true = package-Block, false = sonstiger Block
+	enumMethods := false
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "'") || strings.HasPrefix(line, "@") {
+			continue
+		}
+
+		// Klassenkörper
+		if current != nil {
+			if line == "}" {
+				current = nil
+				continue
+			}
+			if err := s.parseMember(current, line, &enumMethods); err != nil {
+				return nil, fmt.Errorf("Zeile %d: %v", lineNumber, err)
+			}
+			continue
+		}
+
+		if line == "}" {
+			if len(blocks) > 0 {
+				if blocks[len(blocks)-1] && len(packages) > 0 {
+					packages = packages[:len(packages)-1]
+				}
+				blocks = blocks[:len(blocks)-1]
+			}
+			continue
+		}
+
+		if match := sketchPackagePattern.FindStringSubmatch(line); match != nil {
+			packages = append(packages, match[1])
+			blocks = append(blocks, true)
+			continue
+		}
+
+		if match := sketchHeaderPattern.FindStringSubmatch(line); match != nil {
+			key := strings.Trim(match[2], `"`)
+			if match[3] != "" {
+				key = match[3]
+			}
+			name := bareTypeName(key)
+			if !sketchIdentPattern.MatchString(name) {
+				continue // z.B. Factory-Funktionen "NewX()"
+			}
+			pkg := defaultPackage
+			if len(packages) > 0 {
+				pkg = packages[len(packages)-1]
+			} else if qualifier := strings.TrimSuffix(key, "."+name); qualifier != key {
+				pkg = bareTypeName(qualifier) // Importpfad-Qualifizierer wie example.com.app.auth
+			}
+			t := &sketchType{Kind: match[1], Key: key, Name: name, Package: pkg}
+			if params := strings.Trim(match[4], "<>"); params != "" {
+				var parts []string
+				for _, param := range splitTopLevel(params) {
+					if fields := strings.Fields(param); len(fields) == 1 {
+						parts = append(parts, fields[0]+" any")
+					} else {
+						parts = append(parts, param)
+					}
+				}
+				t.TypeParams = strings.Join(parts, ", ")
+			}
+			if strings.Contains(match[5], "<<alias>>") {
+				t.Alias = true
+			}
+			if strings.Contains(match[5], "<<type>>") || t.Alias {
+				t.Underlying = "int"
+			}
+			if existing, ok := s.byName[key]; ok {
+				t = existing
+			} else {
+				s.types = append(s.types, t)
+				s.byName[key] = t
+			}
+			if match[7] != "" {
+				current = t
+				enumMethods = false
+			}
+			continue
+		}
+
+		if match := sketchRelationPattern.FindStringSubmatch(line); match != nil {
+			s.addRelation(match[1], match[2], match[3], match[4], match[5], strings.TrimSpace(match[6]))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// parseMember überträgt eine Zeile eines Klassenkörpers
+func (s *sketch) parseMember(t *sketchType, line string, enumMethods *bool) error {
+	// Trennlinien wie "--", "..", ".. <<options>> .." oder "=="
+	if strings.Trim(line, "-.=_ ") == "" || (strings.HasPrefix(line, "..") && strings.HasSuffix(line, "..")) {
+		if t.Kind == "enum" {
+			*enumMethods = true
+		}
+		return nil
+	}
+	if strings.Contains(line, "<<create>>") {
+		return nil // Konstruktoren entstehen nicht aus dem Klassenkörper
+	}
+	if strings.Contains(line, "<<promoted>>") {
+		return nil // Über Einbettung weitergereicht, nicht selbst deklariert
+	}
+	if term, ok := strings.CutPrefix(line, "<<typeset>>"); ok {
+		t.Embeds = append(t.Embeds, strings.TrimSpace(term)) // Typmenge eines Constraints, z.B. ~int | ~float64
+		return nil
+	}
+	line = strings.TrimLeft(line, "+-#~ ")
+	line = strings.TrimSpace(strings.NewReplacer("{static}", "", "{abstract}", "", "<<pointer>>", "").Replace(line))
+	line = strings.TrimPrefix(line, "*") // Pointer-Receiver aus --pointer-receivers prefix
+	if i := strings.Index(line, " = "); i > 0 {
+		line = line[:i] // Standardwert aus --field-defaults, z.B. make(map[string]int)
+	}
+
+	if t.Kind == "enum" && !*enumMethods && sketchIdentPattern.MatchString(line) {
+		t.Values = append(t.Values, line)
+		return nil
+	}
+
+	// Zugrundeliegender Funktionstyp bei «type», z.B. func(*Config)
+	if t.Underlying != "" && strings.HasPrefix(line, "func(") {
+		t.Underlying = line
+		return nil
+	}
+
+	// Methode: Name(p: Typ, q: Typ): Ergebnis; bei "name: func(...)" ist
+	// die Klammer Teil des Feldtyps
+	if open := strings.Index(line, "("); open > 0 && !strings.Contains(line[:open], ":") {
+		close := matchingParen(line, open)
+		if close < 0 {
+			return fmt.Errorf("unvollständige Methode %q", line)
+		}
+		name := strings.TrimSpace(line[:open])
+		var params []string
+		named := false
+		for _, param := range splitTopLevel(line[open+1 : close]) {
+			if i := strings.Index(param, ":"); i >= 0 {
+				params = append(params, strings.TrimSpace(param[:i])+" "+strings.TrimSpace(param[i+1:]))
+				named = true
+			} else {
+				params = append(params, param)
+			}
+		}
+		// Go erlaubt nur durchgehend benannte oder unbenannte Parameter
+		if named {
+			for i, param := range params {
+				if !strings.Contains(param, " ") || param == "func" || strings.HasPrefix(param, "func(") {
+					params[i] = fmt.Sprintf("p%d %s", i, param)
+				}
+			}
+		}
+		result := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line[close+1:]), ":"))
+		if strings.HasPrefix(result, "(") && strings.Contains(result, ":") {
+			// Benannte Rückgabewerte: (n: int, err: error) -> (n int, err error)
+			var results []string
+			for _, r := range splitTopLevel(result[1 : len(result)-1]) {
+				results = append(results, strings.Replace(r, ":", "", 1))
+			}
+			result = "(" + strings.Join(results, ", ") + ")"
+		} else if strings.Contains(result, ",") && !strings.HasPrefix(result, "(") {
+			result = "(" + result + ")"
+		}
+		t.Methods = append(t.Methods, strings.TrimSpace(name+"("+strings.Join(params, ", ")+") "+result))
+		return nil
+	}
+
+	// Alias: "= Ziel"; zugrundeliegender Typ bei «type»
+	if t.Underlying != "" && !strings.Contains(line, ":") {
+		t.Underlying = strings.TrimSpace(strings.TrimPrefix(line, "="))
+		return nil
+	}
+
+	// Feld: name: Typ, optional mit Tags aus --show-tags: «json:"name"»
+	tag := ""
+	if open := strings.Index(line, "«"); open > 0 && strings.HasSuffix(line, "»") {
+		tag = " `" + strings.TrimSuffix(line[open+len("«"):], "»") + "`"
+		line = strings.TrimSpace(line[:open])
+	}
+	if i := strings.Index(line, ":"); i > 0 {
+		t.Fields = append(t.Fields, strings.TrimSpace(line[:i])+" "+strings.TrimSpace(line[i+1:])+tag)
+		return nil
+	}
+	if fields := strings.Fields(line); len(fields) == 2 {
+		t.Fields = append(t.Fields, fields[0]+" "+fields[1])
+	}
+	return nil
+}
+
+// addRelation überträgt eine Kante in Einbettungen, Implementierungen oder
+// Felder. Kanten gegen die Leserichtung (--|>, --*, <--) werden gedreht.
+func (s *sketch) addRelation(from, fromCard, arrow, toCard, to, label string) {
+	switch arrow {
+	case "--|>", "..|>", "--*", "--o", "<--":
+		from, to = to, from
+		fromCard, toCard = toCard, fromCard
+		arrow = map[string]string{"--|>": "<|--", "..|>": "<|..", "--*": "*--", "--o": "o--", "<--": "-->"}[arrow]
+	}
+	source, target := s.byName[from], s.byName[to]
+	if source == nil || target == nil {
+		return
+	}
+
+	switch arrow {
+	case "<|--":
+		target.Embeds = appendUnique(target.Embeds, from)
+		return
+	case "<|..":
+		target.Implements = appendUnique(target.Implements, from)
+		return
+	case "*--", "o--", "-->":
+	default:
+		return // Abhängigkeiten (..>) und ungerichtete Kanten erzeugen kein Feld
+	}
+
+	typeName := to
+	if arrow != "*--" {
+		typeName = "*" + typeName
+	}
+	fieldName := strings.ToLower(target.Name[:1]) + target.Name[1:]
+	if strings.Contains(toCard, "*") || strings.Contains(toCard, "..n") {
+		typeName = "[]" + typeName
+		fieldName += "s"
+	}
+	if label != "" && sketchIdentPattern.MatchString(label) {
+		fieldName = label
+	}
+	// Kanten zu Feldern, die schon im Klassenkörper stehen, ergeben kein
+	// zweites Feld
+	for _, field := range source.Fields {
+		name, fieldType, _ := strings.Cut(field, " ")
+		refs := sketchTypePattern.FindAllString(fieldType, -1)
+		if name == fieldName || slices.Contains(refs, to) ||
+			(target.Package == source.Package && slices.Contains(refs, target.Name)) {
+			return
+		}
+	}
+	source.Fields = append(source.Fields, fieldName+" "+typeName)
+}
+
+// matchingParen liefert die Position der schließenden Klammer zu open
+func matchingParen(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel trennt an Kommas außerhalb von Klammern
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(', '[', '{', '<':
+			depth++
+		case ')', ']', '}', '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				if part := strings.TrimSpace(s[start:i]); part != "" {
+					parts = append(parts, part)
+				}
+				start = i + 1
+			}
+		}
+	}
+	if part := strings.TrimSpace(s[start:]); part != "" {
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// packages liefert die Pakete des Diagramms in Reihenfolge ihres Auftretens
+func (s *sketch) packages() []string {
+	var packages []string
+	for _, t := range s.types {
+		packages = appendUnique(packages, t.Package)
+	}
+	return packages
+}
+
+// qualify schreibt Typnamen des Diagramms so, wie sie im Paket pkg gelten:
+// Typen anderer Pakete erhalten den Paketnamen, Qualifizierer des eigenen
+// Pakets entfallen. Benötigte Importe werden vermerkt.
+func (s *sketch) qualify(typeString, pkg string, imports map[string]bool) string {
+	return sketchTypePattern.ReplaceAllStringFunc(typeString, func(name string) string {
+		if t, ok := s.byName[name]; ok {
+			if t.Package == pkg {
+				return t.Name
+			}
+			imports[t.Package] = true
+			return t.Package + "." + t.Name
+		}
+		qualifier, ident, ok := strings.Cut(name, ".")
+		if !ok {
+			return name
+		}
+		if qualifier == pkg {
+			return ident
+		}
+		if s.hasPackage(qualifier) || sketchStdlib[qualifier] != "" {
+			imports[qualifier] = true
+		}
+		return name
+	})
+}
+
+// qualifyMember qualifiziert die Typen eines Felds ("name Typ") oder einer
+// Methode ("Name(p Typ) Ergebnis"), nicht aber deren Namen
+func (s *sketch) qualifyMember(member, pkg string, imports map[string]bool) string {
+	end := strings.IndexAny(member, " (")
+	if end < 0 {
+		return member
+	}
+	return member[:end] + s.qualify(member[end:], pkg, imports)
+}
+
+// hasPackage prüft, ob das Diagramm Typen im Paket pkg enthält
+func (s *sketch) hasPackage(pkg string) bool {
+	for _, t := range s.types {
+		if t.Package == pkg {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateGo erzeugt den Quelltext eines Pakets. importPath bildet die
+// Paketnamen des Diagramms auf Importpfade ab; Pakete der
+// Standardbibliothek werden über sketchStdlib importiert.
+func (s *sketch) GenerateGo(pkg string, importPath func(string) string) ([]byte, error) {
+	imports := make(map[string]bool)
+	var body bytes.Buffer
+	receiver := func(t *sketchType) string {
+		name := t.Name
+		if t.TypeParams != "" {
+			var names []string
+			for _, param := range splitTopLevel(t.TypeParams) {
+				names = append(names, strings.Fields(param)[0])
+			}
+			name += "[" + strings.Join(names, ", ") + "]"
+		}
+		return strings.ToLower(t.Name[:1]) + " *" + name
+	}
+	typeParams := func(t *sketchType) string {
+		if t.TypeParams == "" {
+			return ""
+		}
+		return "[" + t.TypeParams + "]"
+	}
+
+	for _, t := range s.types {
+		if t.Package != pkg {
+			continue
+		}
+		switch {
+		case t.Kind == "interface":
+			body.WriteString(fmt.Sprintf("// %s TODO: beschreiben\ntype %s%s interface {\n", t.Name, t.Name, typeParams(t)))
+			for _, embed := range t.Embeds {
+				body.WriteString("\t" + s.qualify(embed, pkg, imports) + "\n")
+			}
+			for _, method := range t.Methods {
+				body.WriteString("\t" + s.qualifyMember(method, pkg, imports) + "\n")
+			}
+			body.WriteString("}\n\n")
+
+		case t.Kind == "enum":
+			body.WriteString(fmt.Sprintf("// %s TODO: beschreiben\ntype %s int\n\n", t.Name, t.Name))
+			if len(t.Values) > 0 {
+				body.WriteString("const (\n")
+				for i, value := range t.Values {
+					if i == 0 {
+						body.WriteString(fmt.Sprintf("\t%s %s = iota\n", value, t.Name))
+					} else {
+						body.WriteString("\t" + value + "\n")
+					}
+				}
+				body.WriteString(")\n\n")
+			}
+			s.writeMethods(&body, t, receiver(t), t.Methods, pkg, imports)
+
+		case t.Underlying != "":
+			assign := ""
+			if t.Alias {
+				assign = "= "
+			}
+			body.WriteString(fmt.Sprintf("// %s TODO: beschreiben\ntype %s%s %s%s\n\n", t.Name, t.Name, typeParams(t), assign, s.qualify(t.Underlying, pkg, imports)))
+			if !t.Alias {
+				s.writeMethods(&body, t, receiver(t), t.Methods, pkg, imports)
+			}
+
+		default:
+			body.WriteString(fmt.Sprintf("// %s TODO: beschreiben\ntype %s%s struct {\n", t.Name, t.Name, typeParams(t)))
+			for _, embed := range t.Embeds {
+				body.WriteString("\t" + s.qualify(embed, pkg, imports) + "\n")
+			}
+			for _, field := range t.Fields {
+				field, tag, _ := strings.Cut(field, " `")
+				if tag != "" {
+					tag = " `" + tag
+				}
+				body.WriteString("\t" + s.qualifyMember(field, pkg, imports) + tag + "\n")
+			}
+			body.WriteString("}\n\n")
+
+			// Eigene Methoden plus fehlende Methoden implementierter Interfaces
+			methods := append([]string{}, t.Methods...)
+			for _, name := range t.Implements {
+				iface := s.byName[name]
+				for _, method := range s.interfaceMethods(iface, make(map[*sketchType]bool)) {
+					if !hasSketchMethod(methods, method) {
+						methods = append(methods, method)
+					}
+				}
+				if iface.Package == pkg && iface.TypeParams == "" && t.TypeParams == "" {
+					body.WriteString(fmt.Sprintf("var _ %s = (*%s)(nil)\n\n", iface.Name, t.Name))
+				}
+			}
+			s.writeMethods(&body, t, receiver(t), methods, pkg, imports)
+		}
+	}
+
+	var src bytes.Buffer
+	src.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	if len(imports) > 0 {
+		src.WriteString("import (\n")
+		paths := make(map[string]bool)
+		for imp := range imports {
+			if s.hasPackage(imp) {
+				paths[importPath(imp)] = true
+			} else {
+				paths[sketchStdlib[imp]] = true
+			}
+		}
+		for _, path := range sortedMapKeys(paths) {
+			src.WriteString(fmt.Sprintf("\t%q\n", path))
+		}
+		src.WriteString(")\n\n")
+	}
+	src.Write(body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return src.Bytes(), fmt.Errorf("erzeugter Code für Paket %s ist ungültig: %v", pkg, err)
+	}
+	return formatted, nil
+}
+
+// writeMethods schreibt Methodenrümpfe, die bis zur Implementierung paniken
+func (s *sketch) writeMethods(body *bytes.Buffer, t *sketchType, receiver string, methods []string, pkg string, imports map[string]bool) {
+	for _, method := range methods {
+		body.WriteString(fmt.Sprintf("func (%s) %s {\n\tpanic(\"nicht implementiert\")\n}\n\n", receiver, s.qualifyMember(method, pkg, imports)))
+	}
+}
+
+// interfaceMethods liefert die Methoden eines Interfaces samt denen der
+// eingebetteten Interfaces des Diagramms
+func (s *sketch) interfaceMethods(t *sketchType, seen map[*sketchType]bool) []string {
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+	methods := append([]string{}, t.Methods...)
+	for _, embed := range t.Embeds {
+		if embedded, ok := s.byName[embed]; ok && embedded.Kind == "interface" {
+			methods = append(methods, s.interfaceMethods(embedded, seen)...)
+		}
+	}
+	return methods
+}
+
+// hasSketchMethod prüft, ob eine Methode gleichen Namens bereits vorhanden ist
+func hasSketchMethod(methods []string, method string) bool {
+	name := method[:strings.Index(method+"(", "(")]
+	for _, existing := range methods {
+		if existing[:strings.Index(existing+"(", "(")] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// runSkeleton erzeugt Go-Gerüste aus einem PlantUML-Klassendiagramm
+// (Unterbefehl "skeleton")
+func runSkeleton(args []string) int {
+	fs := flag.NewFlagSet("skeleton", flag.ExitOnError)
+	defaultPackage := fs.String("package", "model", "Paket für Typen außerhalb von package-Blöcken")
+	force := fs.Bool("force", false, "vorhandene Dateien überschreiben")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Verwendung: uml-watcher skeleton [Optionen] <diagramm.puml> [Zielverzeichnis]")
+		fs.PrintDefaults()
+	}
+	if err := applySubcommandConfig(fs); err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 2
+	}
+	outputDir := "."
+	if fs.NArg() > 1 {
+		outputDir = fs.Arg(1)
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Printf("Fehler beim Lesen des Diagramms: %v\n", err)
+		return 1
+	}
+	s, err := parseSketch(file, *defaultPackage)
+	file.Close()
+	if err != nil {
+		fmt.Printf("Fehler beim Lesen des Diagramms: %v\n", err)
+		return 1
+	}
+	if len(s.types) == 0 {
+		fmt.Println("Das Diagramm enthält keine Klassen, Interfaces oder Enums")
+		return 1
+	}
+
+	// Bei mehreren Paketen erhält jedes ein Unterverzeichnis; Importpfade
+	// ergeben sich aus der go.mod oberhalb des Zielverzeichnisses
+	packages := s.packages()
+	packageDir := func(pkg string) string {
+		if len(packages) == 1 {
+			return outputDir
+		}
+		return filepath.Join(outputDir, pkg)
+	}
+	root, modulePath := findModule(outputDir)
+	importPath := func(pkg string) string {
+		return packageImportPath(root, modulePath, packageDir(pkg))
+	}
+
+	for _, pkg := range packages {
+		src, err := s.GenerateGo(pkg, importPath)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		dir := packageDir(pkg)
+		target := filepath.Join(dir, pkg+".go")
+		if _, err := os.Stat(target); err == nil && !*force {
+			fmt.Printf("%s existiert bereits (mit -force überschreiben)\n", target)
+			return 1
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			fmt.Printf("Fehler beim Erstellen des Verzeichnisses: %v\n", err)
+			return 1
+		}
+		if err := writeFileFrom(target, bytes.NewReader(src), 0644); err != nil {
+			fmt.Printf("Fehler beim Speichern von %s: %v\n", target, err)
+			return 1
+		}
+		fmt.Printf("Gerüst erstellt: %s\n", target)
+	}
+	return 0
+}
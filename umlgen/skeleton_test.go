@@ -0,0 +1,131 @@
+package umlgen
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestSkeletonRoundTrip erzeugt aus Go-Code ein Diagramm (-puml-only), daraus
+// mit skeleton wieder Go-Code und prüft diesen mit go vet
+func TestSkeletonRoundTrip(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go nicht gefunden")
+	}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name: "Standardbibliothek",
+			files: map[string]string{
+				"store/store.go": `package store
+
+import (
+	"context"
+	"time"
+)
+
+type Getter interface {
+	Get(ctx context.Context, key string) (string, error)
+}
+
+type Store interface {
+	Getter
+	Put(key, value string) error
+}
+
+type Cache struct {
+	TTL   time.Duration
+	Hook  func(ctx context.Context) error
+	store Store
+}
+
+type Option func(*Cache)
+`,
+			},
+		},
+		{
+			name: "Namenskollision",
+			files: map[string]string{
+				"auth/user.go": `package auth
+
+type Number interface {
+	~int | ~float64
+}
+
+type Role struct{ Name string }
+
+type User struct {
+	ID   int
+	Role Role
+}
+
+func (u *User) Valid() bool { return true }
+`,
+				"billing/user.go": `package billing
+
+import "example.com/src/auth"
+
+type User struct {
+	Owner *auth.User
+	Total int
+}
+
+type Number int
+
+type Invoice struct {
+	Users  []*User
+	Amount Number
+}
+`,
+			},
+		},
+		{
+			name: "Parameternamen mit Präfix func",
+			files: map[string]string{
+				"walk/walk.go": `package walk
+
+type Walker interface {
+	Visit(funcDecl string, functions []string, fn func(int) error) error
+}
+`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, gen := t.TempDir(), t.TempDir()
+			tt.files["go.mod"] = "module example.com/src\n\ngo 1.22\n"
+			writeTree(t, src, tt.files)
+
+			g := NewUMLGenerator(Options{})
+			if err := g.GenerateUMLFromDirectory(src); err != nil {
+				t.Fatal(err)
+			}
+			s, err := parseSketch(strings.NewReader(g.GeneratePlantUML()), "model")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			generated := map[string]string{"go.mod": "module example.com/gen\n\ngo 1.22\n"}
+			for _, pkg := range s.packages() {
+				code, err := s.GenerateGo(pkg, func(name string) string { return "example.com/gen/" + name })
+				if err != nil {
+					t.Fatalf("%v\n%s", err, code)
+				}
+				generated[pkg+"/"+pkg+".go"] = string(code)
+			}
+			writeTree(t, gen, generated)
+
+			cmd := exec.Command(goTool, "vet", "./...")
+			cmd.Dir = gen
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Errorf("go vet: %v\n%s", err, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package umlgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Statistics fasst die Größe des generierten Modells zusammen
+type Statistics struct {
+	Packages   int            `json:"packages"`
+	Structs    int            `json:"structs"`
+	Interfaces int            `json:"interfaces"`
+	NamedTypes int            `json:"namedTypes"`
+	Methods    int            `json:"methods"`
+	Relations  map[string]int `json:"relations"` // Anzahl pro Beziehungsart
+	Warnings   int            `json:"warnings"`
+	Details    []Warning      `json:"warningDetails,omitempty"`
+}
+
+// Statistics berechnet die Kennzahlen des aktuellen Modells
+func (g *UMLGenerator) Statistics() Statistics {
+	stats := Statistics{
+		Structs:    len(g.structs),
+		Interfaces: len(g.interfaces),
+		NamedTypes: len(g.namedTypes),
+		Relations:  make(map[string]int),
+	}
+
+	packages := make(map[string]bool)
+	for _, structInfo := range g.structs {
+		packages[structInfo.Package] = true
+		stats.Methods += len(structInfo.Methods)
+	}
+	for _, interfaceInfo := range g.interfaces {
+		packages[interfaceInfo.Package] = true
+		stats.Methods += len(interfaceInfo.Methods)
+	}
+	for _, namedInfo := range g.namedTypes {
+		packages[namedInfo.Package] = true
+		stats.Methods += len(namedInfo.Methods)
+	}
+	stats.Packages = len(packages)
+
+	for _, relation := range g.relations {
+		stats.Relations[relation.Type]++
+	}
+
+	stats.Warnings = len(g.warnings)
+	stats.Details = g.warnings
+
+	return stats
+}
+
+// relationCount liefert die Gesamtzahl der Beziehungen
+func (s Statistics) relationCount() int {
+	total := 0
+	for _, count := range s.Relations {
+		total += count
+	}
+	return total
+}
+
+// String formatiert die Kennzahlen einzeilig, z.B. für den Diagramm-Footer
+func (s Statistics) String() string {
+	return fmt.Sprintf("%d Pakete | %d Structs | %d Interfaces | %d Typen | %d Methoden | %d Beziehungen | %d Warnungen",
+		s.Packages, s.Structs, s.Interfaces, s.NamedTypes, s.Methods, s.relationCount(), s.Warnings)
+}
+
+// Text formatiert die Kennzahlen mehrzeilig mit Aufschlüsselung der Beziehungen
+func (s Statistics) Text() string {
+	var sb strings.Builder
+	sb.WriteString("Statistik:\n")
+	sb.WriteString(fmt.Sprintf("  Pakete:       %d\n", s.Packages))
+	sb.WriteString(fmt.Sprintf("  Structs:      %d\n", s.Structs))
+	sb.WriteString(fmt.Sprintf("  Interfaces:   %d\n", s.Interfaces))
+	sb.WriteString(fmt.Sprintf("  Typen:        %d\n", s.NamedTypes))
+	sb.WriteString(fmt.Sprintf("  Methoden:     %d\n", s.Methods))
+	sb.WriteString(fmt.Sprintf("  Beziehungen:  %d\n", s.relationCount()))
+	for _, kind := range sortedMapKeys(s.Relations) {
+		sb.WriteString(fmt.Sprintf("    %-12s %d\n", kind+":", s.Relations[kind]))
+	}
+	sb.WriteString(fmt.Sprintf("  Warnungen:    %d\n", s.Warnings))
+	return sb.String()
+}
+
+// printStatistics gibt die Kennzahlen im gewählten Format (--stats) aus
+func printStatistics(stats Statistics, format string) {
+	switch format {
+	case "text":
+		fmt.Print(stats.Text())
+	case "json":
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			fmt.Printf("Fehler beim Erzeugen der Statistik: %v\n", err)
+			return
+		}
+		fmt.Println(string(data))
+	}
+}
@@ -0,0 +1,383 @@
+package umlgen
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// stubMethod ist eine Methode des Interfaces samt der Datei, in der sie
+// deklariert ist (für die Importe ihrer Typen)
+type stubMethod struct {
+	name string
+	fn   *ast.FuncType
+	file *ast.File
+}
+
+// interfaceKey liefert den Modellschlüssel eines Interfaces, das als
+// Modellschlüssel, als pkg.Name oder als bloßer Name angegeben ist. Ein
+// bloßer Name muss eindeutig sein.
+func (g *UMLGenerator) interfaceKey(name string) (string, error) {
+	if _, ok := g.interfaces[name]; ok {
+		return name, nil
+	}
+	var matches []string
+	for _, key := range sortedMapKeys(g.interfaces) {
+		qualified := g.interfaces[key].Package + "." + bareTypeName(key)
+		if qualified == name || (!strings.Contains(name, ".") && bareTypeName(key) == name) {
+			matches = append(matches, key)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("Interface %s nicht gefunden", name)
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("Interface %s ist mehrdeutig: %s", name, strings.Join(matches, ", "))
+}
+
+// interfaceSpec sucht die Deklaration eines Interfaces des Modells im AST
+func (g *UMLGenerator) interfaceSpec(name string) (*ast.TypeSpec, *ast.InterfaceType, *ast.File) {
+	info, ok := g.interfaces[name]
+	if !ok {
+		return nil, nil, nil
+	}
+	file := g.files[info.Pos.File]
+	if file == nil {
+		return nil, nil, nil
+	}
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok || typeSpec.Name.Name != bareTypeName(name) {
+				continue
+			}
+			if interfaceType, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+				return typeSpec, interfaceType, file
+			}
+		}
+	}
+	return nil, nil, nil
+}
+
+// stubMethods sammelt die Methoden eines Interfaces. Eingebettete Interfaces
+// des Modells werden aufgelöst, fremde (z.B. io.Reader) sind ohne
+// Typinformation nicht bekannt und ergeben einen Fehler.
+func (g *UMLGenerator) stubMethods(name string, seen map[string]bool) ([]stubMethod, error) {
+	if seen[name] {
+		return nil, nil
+	}
+	seen[name] = true
+
+	_, interfaceType, file := g.interfaceSpec(name)
+	if interfaceType == nil {
+		return nil, fmt.Errorf("Interface %s nicht gefunden", name)
+	}
+
+	var methods []stubMethod
+	for _, field := range interfaceType.Methods.List {
+		if fn, ok := field.Type.(*ast.FuncType); ok && len(field.Names) > 0 {
+			methods = append(methods, stubMethod{name: field.Names[0].Name, fn: fn, file: file})
+			continue
+		}
+		ident, ok := field.Type.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("%s: eingebettetes Interface %s kann nicht aufgelöst werden", name, types.ExprString(field.Type))
+		}
+		embedded, err := g.stubMethods(g.typeKey(g.fileScope(file), ident.Name), seen)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, embedded...)
+	}
+	return methods, nil
+}
+
+// GenerateStub erzeugt eine kompilierbare Stub-Implementierung eines
+// Interfaces im Paket des Interfaces. Jede Methode zählt ihre Aufrufe und
+// ruft, falls gesetzt, die gleichnamige Funktion <Methode>Func auf; sonst
+// liefert sie Nullwerte.
+func (g *UMLGenerator) GenerateStub(interfaceName, stubName string) ([]byte, error) {
+	key, err := g.interfaceKey(interfaceName)
+	if err != nil {
+		return nil, err
+	}
+	typeSpec, _, file := g.interfaceSpec(key)
+	if typeSpec == nil {
+		return nil, fmt.Errorf("Interface %s nicht gefunden", interfaceName)
+	}
+	interfaceName = typeSpec.Name.Name
+	methods, err := g.stubMethods(key, make(map[string]bool))
+	if err != nil {
+		return nil, err
+	}
+
+	// Typparameter generischer Interfaces übernehmen
+	typeParams, typeArgs := "", ""
+	if typeSpec.TypeParams != nil {
+		var decls, names []string
+		for _, field := range typeSpec.TypeParams.List {
+			var fieldNames []string
+			for _, name := range field.Names {
+				fieldNames = append(fieldNames, name.Name)
+			}
+			decls = append(decls, strings.Join(fieldNames, ", ")+" "+types.ExprString(field.Type))
+			names = append(names, fieldNames...)
+		}
+		typeParams = "[" + strings.Join(decls, ", ") + "]"
+		typeArgs = "[" + strings.Join(names, ", ") + "]"
+	}
+
+	imports := make(map[string]string) // Importpfad -> Importname
+	var fields, bodies strings.Builder
+	for _, method := range methods {
+		params, args, results := stubSignature(method.fn)
+		for _, list := range []*ast.FieldList{method.fn.Params, method.fn.Results} {
+			if list == nil {
+				continue
+			}
+			if err := addStubImports(imports, method.file, list); err != nil {
+				return nil, err
+			}
+		}
+
+		signature := "(" + strings.Join(params, ", ") + ")"
+		if len(results) == 1 {
+			signature += " " + results[0]
+		} else if len(results) > 1 {
+			signature += " (" + strings.Join(results, ", ") + ")"
+		}
+
+		fields.WriteString(fmt.Sprintf("\t%sFunc func%s\n", method.name, signature))
+		fields.WriteString(fmt.Sprintf("\t%sCalls int\n", method.name))
+
+		bodies.WriteString(fmt.Sprintf("\nfunc (stub *%s%s) %s%s {\n", stubName, typeArgs, method.name, signature))
+		bodies.WriteString(fmt.Sprintf("\tstub.%sCalls++\n", method.name))
+		call := fmt.Sprintf("stub.%sFunc(%s)", method.name, strings.Join(args, ", "))
+		bodies.WriteString(fmt.Sprintf("\tif stub.%sFunc != nil {\n", method.name))
+		if len(results) > 0 {
+			bodies.WriteString("\t\treturn " + call + "\n\t}\n")
+			var zeros []string
+			for i, result := range results {
+				bodies.WriteString(fmt.Sprintf("\tvar r%d %s\n", i, result))
+				zeros = append(zeros, fmt.Sprintf("r%d", i))
+			}
+			bodies.WriteString("\treturn " + strings.Join(zeros, ", ") + "\n")
+		} else {
+			bodies.WriteString("\t\t" + call + "\n\t}\n")
+		}
+		bodies.WriteString("}\n")
+	}
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by go-uml-generator stub. DO NOT EDIT.\n\n")
+	src.WriteString(fmt.Sprintf("package %s\n\n", file.Name.Name))
+	if len(imports) > 0 {
+		src.WriteString("import (\n")
+		for _, path := range sortedMapKeys(imports) {
+			if imports[path] != importName(path) {
+				src.WriteString(fmt.Sprintf("\t%s %q\n", imports[path], path))
+			} else {
+				src.WriteString(fmt.Sprintf("\t%q\n", path))
+			}
+		}
+		src.WriteString(")\n\n")
+	}
+	src.WriteString(fmt.Sprintf("// %s ist eine Stub-Implementierung von %s. Für jede Methode kann eine\n", stubName, interfaceName))
+	src.WriteString("// Funktion <Methode>Func hinterlegt werden; ohne sie liefert die Methode\n")
+	src.WriteString("// Nullwerte. <Methode>Calls zählt die Aufrufe.\n")
+	src.WriteString(fmt.Sprintf("type %s%s struct {\n%s}\n", stubName, typeParams, fields.String()))
+	src.WriteString(bodies.String())
+	if typeParams == "" {
+		src.WriteString(fmt.Sprintf("\nvar _ %s = (*%s)(nil)\n", interfaceName, stubName))
+	}
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Formatieren des Stubs: %v", err)
+	}
+	return formatted, nil
+}
+
+// stubSignature liefert die Parameter mit eindeutigen Namen, die Argumente
+// für den Weiterruf (variadisch mit ...) und die Ergebnistypen
+func stubSignature(fn *ast.FuncType) (params, args, results []string) {
+	if fn.Params != nil {
+		for _, field := range fn.Params.List {
+			typeString := types.ExprString(field.Type)
+			names := field.Names
+			if len(names) == 0 {
+				names = []*ast.Ident{{Name: "_"}}
+			}
+			for _, name := range names {
+				paramName := name.Name
+				// Kollisionen mit dem Receiver und den Nullwerten r0, r1, ... vermeiden
+				if _, err := strconv.Atoi(strings.TrimPrefix(paramName, "r")); paramName == "_" || paramName == "stub" || err == nil {
+					paramName = fmt.Sprintf("p%d", len(params))
+				}
+				params = append(params, paramName+" "+typeString)
+				if _, variadic := field.Type.(*ast.Ellipsis); variadic {
+					paramName += "..."
+				}
+				args = append(args, paramName)
+			}
+		}
+	}
+	if fn.Results != nil {
+		for _, field := range fn.Results.List {
+			count := len(field.Names)
+			if count == 0 {
+				count = 1
+			}
+			for i := 0; i < count; i++ {
+				results = append(results, types.ExprString(field.Type))
+			}
+		}
+	}
+	return params, args, results
+}
+
+// addStubImports ergänzt die Importe, die die Typen einer Feldliste
+// über pkg.Name referenzieren. Bringen zwei Methoden denselben Importnamen
+// mit verschiedenen Pfaden mit, ist der Stub nicht eindeutig.
+func addStubImports(imports map[string]string, file *ast.File, list *ast.FieldList) error {
+	byName := make(map[string]string)
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := importName(path)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		byName[name] = path
+	}
+
+	var err error
+	ast.Inspect(list, func(n ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		path, ok := byName[ident.Name]
+		if !ok {
+			return true
+		}
+		for other, name := range imports {
+			if name == ident.Name && other != path {
+				err = fmt.Errorf("Importname %s ist mehrdeutig: %q und %q", name, other, path)
+				return false
+			}
+		}
+		imports[path] = ident.Name
+		return true
+	})
+	return err
+}
+
+// importName liefert den Namen, unter dem ein Import ohne expliziten Namen
+// im Code erscheint. Wie bei goimports ist das das letzte Pfadelement ohne
+// Major-Version (/v5), ohne Präfix go- und bis zum ersten Zeichen, das in
+// Bezeichnern nicht vorkommt (yaml.v3, go-sqlite3).
+func importName(path string) string {
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && isMajorVersion(name) {
+		name = elems[len(elems)-2]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	if i := strings.IndexFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// isMajorVersion prüft, ob ein Pfadelement eine Major-Version wie v2 ist
+func isMajorVersion(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(elem[1:])
+	return err == nil
+}
+
+// runStub erzeugt eine Stub-Implementierung eines Interfaces (Unterbefehl "stub")
+func runStub(args []string) int {
+	fs := flag.NewFlagSet("stub", flag.ExitOnError)
+	var options Options
+	interfaceName := fs.String("interface", "", "Name des Interfaces (erforderlich)")
+	stubName := fs.String("type", "", "Name des Stub-Typs (Standard: <Interface>Stub)")
+	outPath := fs.String("out", "", "Zieldatei (Standard: <interface>_stub.go neben dem Interface)")
+	fs.StringVar(&options.SkipDirs, "skip-dirs", "vendor,testdata", "kommagetrennte Verzeichnisnamen, die nicht durchsucht werden")
+	fs.StringVar(&options.Ignore, "ignore", "", "zusätzliche kommagetrennte Muster für zu ignorierende Dateien/Verzeichnisse")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Verwendung: uml-watcher stub -interface <Name> [Optionen] [Verzeichnis]")
+		fs.PrintDefaults()
+	}
+	if err := applySubcommandConfig(fs); err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	fs.Parse(args)
+	if *interfaceName == "" {
+		fs.Usage()
+		return 2
+	}
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = packagePatternDir(fs.Arg(0))
+	}
+
+	g := NewUMLGenerator(options)
+	if err := g.GenerateUMLFromDirectory(dir); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	key, err := g.interfaceKey(*interfaceName)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	name := bareTypeName(key)
+	if *stubName == "" {
+		*stubName = name + "Stub"
+	}
+	src, err := g.GenerateStub(key, *stubName)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	target := *outPath
+	if target == "" {
+		target = filepath.Join(filepath.Dir(g.interfaces[key].Pos.File), strings.ToLower(name)+"_stub.go")
+	}
+	if err := writeFileFrom(target, bytes.NewReader(src), 0644); err != nil {
+		fmt.Printf("Fehler beim Speichern des Stubs: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Stub erstellt: %s\n", target)
+	return 0
+}
@@ -0,0 +1,177 @@
+package umlgen
+
+import (
+	"os/exec"
+	"testing"
+)
+
+// TestGenerateStub erzeugt Stubs, legt sie neben das Interface und prüft das
+// Paket mit go vet, also auch die Zusicherung var _ Interface = (*Stub)(nil)
+func TestGenerateStub(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go nicht gefunden")
+	}
+
+	tests := []struct {
+		name      string
+		files     map[string]string
+		iface     string
+		wantDir   string // Verzeichnis des Interfaces
+		wantError bool
+	}{
+		{
+			name: "Standardbibliothek und mehrere Ergebnisse",
+			files: map[string]string{"store/store.go": `package store
+
+import (
+	"context"
+	"time"
+)
+
+type Store interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Put(ctx context.Context, key, value string, ttl time.Duration) error
+	Keys(prefix string, more ...string) []string
+	Close()
+}
+`},
+			iface:   "Store",
+			wantDir: "store",
+		},
+		{
+			name: "eingebettetes Interface des Modells",
+			files: map[string]string{"store/store.go": `package store
+
+type Getter interface {
+	Get(key string) (string, error)
+}
+
+type Store interface {
+	Getter
+	Put(key, value string) error
+}
+`},
+			iface:   "Store",
+			wantDir: "store",
+		},
+		{
+			name: "generisches Interface",
+			files: map[string]string{"store/store.go": `package store
+
+type Repo[K comparable, V any] interface {
+	Find(id K) (V, error)
+	All() map[K]V
+}
+`},
+			iface:   "Repo",
+			wantDir: "store",
+		},
+		{
+			name: "qualifizierter Name bei Kollision",
+			files: map[string]string{
+				"auth/store.go":    "package auth\n\ntype Store interface {\n\tLogin(user string) error\n}\n",
+				"billing/store.go": "package billing\n\ntype Store interface {\n\tCharge(amount int) error\n}\n",
+			},
+			iface:   "billing.Store",
+			wantDir: "billing",
+		},
+		{
+			name: "mehrdeutiger Name",
+			files: map[string]string{
+				"auth/store.go":    "package auth\n\ntype Store interface {\n\tLogin(user string) error\n}\n",
+				"billing/store.go": "package billing\n\ntype Store interface {\n\tCharge(amount int) error\n}\n",
+			},
+			iface:     "Store",
+			wantError: true,
+		},
+		{
+			name: "versionierte Importpfade",
+			files: map[string]string{
+				"lib/v2/lib.go":   "package lib\n\ntype Item struct{}\n",
+				"conf.v3/conf.go": "package conf\n\ntype Config struct{}\n",
+				"store/store.go": `package store
+
+import (
+	"example.com/src/conf.v3"
+	"example.com/src/lib/v2"
+)
+
+type Store interface {
+	Load(cfg conf.Config) (*lib.Item, error)
+}
+`},
+			iface:   "Store",
+			wantDir: "store",
+		},
+		{
+			name: "gleicher Importname mit verschiedenen Pfaden",
+			files: map[string]string{
+				"a/model/model.go": "package model\n\ntype Item struct{}\n",
+				"b/model/model.go": "package model\n\ntype Item struct{}\n",
+				"store/get.go":     "package store\n\nimport \"example.com/src/a/model\"\n\ntype Getter interface {\n\tGet() model.Item\n}\n",
+				"store/store.go":   "package store\n\nimport \"example.com/src/b/model\"\n\ntype Store interface {\n\tGetter\n\tPut(item model.Item)\n}\n",
+			},
+			iface:     "Store",
+			wantError: true,
+		},
+		{
+			name:      "fremdes eingebettetes Interface",
+			files:     map[string]string{"store/store.go": "package store\n\nimport \"io\"\n\ntype Store interface {\n\tio.Reader\n}\n"},
+			iface:     "Store",
+			wantError: true,
+		},
+		{
+			name:      "unbekanntes Interface",
+			files:     map[string]string{"store/store.go": "package store\n\ntype Store struct{}\n"},
+			iface:     "Store",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.files["go.mod"] = "module example.com/src\n\ngo 1.22\n"
+			writeTree(t, dir, tt.files)
+
+			g := NewUMLGenerator(Options{})
+			if err := g.GenerateUMLFromDirectory(dir); err != nil {
+				t.Fatal(err)
+			}
+			code, err := g.GenerateStub(tt.iface, "StubImpl")
+			if (err != nil) != tt.wantError {
+				t.Fatalf("GenerateStub() Fehler = %v, erwartet Fehler: %v", err, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+
+			writeTree(t, dir, map[string]string{tt.wantDir + "/stub_impl.go": string(code)})
+			cmd := exec.Command(goTool, "vet", "./"+tt.wantDir)
+			cmd.Dir = dir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Errorf("go vet: %v\n%s\n%s", err, out, code)
+			}
+		})
+	}
+}
+
+func TestImportName(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"context", "context"},
+		{"net/http", "http"},
+		{"github.com/go-chi/chi/v5", "chi"},
+		{"gopkg.in/yaml.v3", "yaml"},
+		{"github.com/mattn/go-sqlite3", "sqlite3"},
+		{"example.com/v2", "example"},
+	}
+	for _, tt := range tests {
+		if got := importName(tt.path); got != tt.want {
+			t.Errorf("importName(%q) = %q, erwartet %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package umlgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// TestSubject ordnet einem Typ die Testfunktionen zu, die ihn direkt
+// verwenden, sowie pro Methode die Tests, die sie aufrufen
+type TestSubject struct {
+	Type    string
+	Package string
+	Tests   []string
+	Methods map[string][]string // Methode -> aufrufende Tests
+}
+
+// isTestFile prüft, ob eine Datei eine Testdatei ist
+func isTestFile(filePath string) bool {
+	return strings.HasSuffix(filePath, "_test.go")
+}
+
+// isTestFunc prüft, ob eine Funktion vom go-Tool als Test ausgeführt wird
+func isTestFunc(funcDecl *ast.FuncDecl) bool {
+	if funcDecl.Recv != nil || funcDecl.Body == nil {
+		return false
+	}
+	for _, prefix := range []string{"Test", "Benchmark", "Fuzz", "Example"} {
+		if strings.HasPrefix(funcDecl.Name.Name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// TestSubjects untersucht die Testfunktionen aller _test.go-Dateien und
+// ordnet ihnen die verwendeten Typen und aufgerufenen Methoden zu. Als
+// Verwendung zählen Instanziierungen, Konstruktoraufrufe (NewT), Variablen-
+// und Parametertypen sowie Methodenaufrufe auf so erzeugten Variablen. Im
+// Lademodus "types" werden Methodenaufrufe über go/types zugeordnet. Typen,
+// die selbst in Testdateien deklariert sind (Mocks, Helfer), sind keine
+// Testobjekte.
+func (g *UMLGenerator) TestSubjects() []*TestSubject {
+	subjects := make(map[string]*TestSubject)
+	add := func(name, pkg string) {
+		if !isTestFile(g.typePosition(name).File) {
+			subjects[name] = &TestSubject{Type: name, Package: pkg, Methods: make(map[string][]string)}
+		}
+	}
+	for name, structInfo := range g.structs {
+		add(name, structInfo.Package)
+	}
+	for name, namedInfo := range g.namedTypes {
+		add(name, namedInfo.Package)
+	}
+
+	for _, filePath := range sortedMapKeys(g.files) {
+		if !isTestFile(filePath) {
+			continue
+		}
+		for _, decl := range g.files[filePath].Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || !isTestFunc(funcDecl) {
+				continue
+			}
+			test := funcDecl.Name.Name
+			used, calls := g.exercised(funcDecl.Body)
+			for _, name := range used {
+				if subject, ok := subjects[name]; ok {
+					subject.Tests = appendUnique(subject.Tests, test)
+				}
+			}
+			for _, call := range calls {
+				if subject, ok := subjects[call[0]]; ok {
+					subject.Tests = appendUnique(subject.Tests, test)
+					subject.Methods[call[1]] = appendUnique(subject.Methods[call[1]], test)
+				}
+			}
+		}
+	}
+
+	result := make([]*TestSubject, 0, len(subjects))
+	for _, name := range sortedMapKeys(subjects) {
+		result = append(result, subjects[name])
+	}
+	return result
+}
+
+// exercised liefert die in einem Testrumpf verwendeten Typen und die
+// aufgerufenen Methoden als Paare aus Typ und Methode
+func (g *UMLGenerator) exercised(body *ast.BlockStmt) (typeNames []string, calls [][2]string) {
+	variables := make(map[string]string) // Variable -> Typ
+	useType := func(expr ast.Expr) string {
+		target, _ := containerTarget(expr)
+		target = g.resolvedTarget(expr, target)
+		if i := strings.LastIndex(target, "."); i >= 0 {
+			target = target[i+1:]
+		}
+		if !g.isClassType(target) {
+			return ""
+		}
+		typeNames = appendUnique(typeNames, target)
+		return target
+	}
+	// Typ eines Ausdrucks, der einer Variable zugewiesen wird
+	valueType := func(expr ast.Expr) string {
+		if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+			expr = unary.X
+		}
+		switch e := expr.(type) {
+		case *ast.CompositeLit:
+			if e.Type != nil {
+				return useType(e.Type)
+			}
+		case *ast.CallExpr:
+			name := ""
+			switch fun := e.Fun.(type) {
+			case *ast.Ident:
+				name = fun.Name
+			case *ast.SelectorExpr:
+				name = fun.Sel.Name
+			}
+			if name == "new" && len(e.Args) == 1 {
+				return useType(e.Args[0])
+			}
+			if typeName := strings.TrimPrefix(name, "New"); typeName != name && g.isClassType(typeName) {
+				typeNames = appendUnique(typeNames, typeName)
+				return typeName
+			}
+		}
+		return ""
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range node.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok || i >= len(node.Rhs) {
+					continue
+				}
+				if typeName := valueType(node.Rhs[i]); typeName != "" {
+					variables[ident.Name] = typeName
+				}
+			}
+		case *ast.ValueSpec:
+			for i, name := range node.Names {
+				if node.Type != nil {
+					if typeName := useType(node.Type); typeName != "" {
+						variables[name.Name] = typeName
+					}
+				} else if i < len(node.Values) {
+					if typeName := valueType(node.Values[i]); typeName != "" {
+						variables[name.Name] = typeName
+					}
+				}
+			}
+		case *ast.CompositeLit:
+			if node.Type != nil {
+				useType(node.Type)
+			}
+		case *ast.CallExpr:
+			sel, ok := node.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if typeName := g.methodReceiver(sel); typeName != "" {
+				calls = append(calls, [2]string{typeName, sel.Sel.Name})
+			} else if ident, ok := sel.X.(*ast.Ident); ok && variables[ident.Name] != "" {
+				calls = append(calls, [2]string{variables[ident.Name], sel.Sel.Name})
+			}
+		}
+		return true
+	})
+	return typeNames, calls
+}
+
+// methodReceiver liefert im Lademodus "types" den Modelltyp, dessen Methode
+// ein Selektor aufruft
+func (g *UMLGenerator) methodReceiver(sel *ast.SelectorExpr) string {
+	if g.typesInfo == nil {
+		return ""
+	}
+	fn, ok := g.typesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok {
+		return ""
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return ""
+	}
+	t := recv.Type()
+	if pointer, ok := t.(*types.Pointer); ok {
+		t = pointer.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || !g.isClassType(named.Obj().Name()) {
+		return ""
+	}
+	return named.Obj().Name()
+}
+
+// GenerateTestMapPlantUML zeichnet die Typen mit ihren Methoden und den
+// Tests, die sie verwenden. Ungetestete Typen und Methoden werden
+// hervorgehoben.
+func (g *UMLGenerator) GenerateTestMapPlantUML(subjects []*TestSubject) string {
+	var sb strings.Builder
+	sb.WriteString("@startuml\n\n")
+	sb.WriteString("skinparam class {\n")
+	sb.WriteString("    BackgroundColor<<ungetestet>> #FFCCCC\n")
+	sb.WriteString("    BackgroundColor<<test>> #E8F0FF\n")
+	sb.WriteString("}\n\n")
+
+	tests := make(map[string]map[string][]string) // Test -> Typ -> Methoden
+	for _, subject := range subjects {
+		stereotype := ""
+		if len(subject.Tests) == 0 {
+			stereotype = " <<ungetestet>>"
+		}
+		sb.WriteString(fmt.Sprintf("class %s%s {\n", subject.Type, stereotype))
+		for _, method := range g.methodNames(subject.Type) {
+			if count := len(subject.Methods[method]); count > 0 {
+				sb.WriteString(fmt.Sprintf("    +%s() : %d Tests\n", method, count))
+			} else {
+				sb.WriteString(fmt.Sprintf("    -%s() : ungetestet\n", method))
+			}
+		}
+		sb.WriteString("}\n")
+
+		for _, test := range subject.Tests {
+			if tests[test] == nil {
+				tests[test] = make(map[string][]string)
+			}
+			tests[test][subject.Type] = nil
+		}
+		for _, method := range sortedMapKeys(subject.Methods) {
+			for _, test := range subject.Methods[method] {
+				tests[test][subject.Type] = append(tests[test][subject.Type], method)
+			}
+		}
+	}
+	sb.WriteString("\n")
+
+	for _, test := range sortedMapKeys(tests) {
+		sb.WriteString(fmt.Sprintf("class %s <<test>>\n", test))
+	}
+	sb.WriteString("\n")
+
+	for _, test := range sortedMapKeys(tests) {
+		for _, typeName := range sortedMapKeys(tests[test]) {
+			if methods := tests[test][typeName]; len(methods) > 0 {
+				sb.WriteString(fmt.Sprintf("%s ..> %s : %s\n", test, typeName, strings.Join(methods, ", ")))
+			} else {
+				sb.WriteString(fmt.Sprintf("%s ..> %s\n", test, typeName))
+			}
+		}
+	}
+
+	sb.WriteString("\n@enduml")
+	return sb.String()
+}
+
+// methodNames liefert die Methodennamen eines Structs oder benannten Typs
+func (g *UMLGenerator) methodNames(typeName string) []string {
+	var methods []MethodInfo
+	if structInfo, ok := g.structs[typeName]; ok {
+		methods = structInfo.Methods
+	} else if namedInfo, ok := g.namedTypes[typeName]; ok {
+		methods = namedInfo.Methods
+	}
+	var names []string
+	for _, method := range methods {
+		names = appendUnique(names, method.Name)
+	}
+	return names
+}
+
+// GenerateTestMap schreibt das Testzuordnungsdiagramm (uml_tests) und nennt
+// die Typen ohne direkten Test
+func (g *UMLGenerator) GenerateTestMap(outputDir string) error {
+	subjects := g.TestSubjects()
+
+	var untested []string
+	for _, subject := range subjects {
+		if len(subject.Tests) == 0 {
+			untested = append(untested, subject.Type)
+		}
+	}
+	fmt.Printf("Typen ohne direkten Test: %d von %d\n", len(untested), len(subjects))
+	if len(untested) > 0 {
+		fmt.Printf("  %s\n", strings.Join(untested, ", "))
+	}
+
+	return g.writeDiagram(outputDir, "uml_tests", g.GenerateTestMapPlantUML(subjects))
+}
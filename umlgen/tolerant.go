@@ -0,0 +1,98 @@
+package umlgen
+
+import (
+	"bytes"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"os"
+)
+
+// maxRepairPasses begrenzt, wie viele fehlerhafte Deklarationen ausgeblendet
+// werden, bevor der Rest der Datei verloren gegeben wird
+const maxRepairPasses = 10
+
+// declKeywords leiten eine Deklaration auf oberster Ebene ein, wenn sie am
+// Zeilenanfang stehen
+var declKeywords = [][]byte{[]byte("func "), []byte("func("), []byte("type "), []byte("var "), []byte("const ")}
+
+// parseTolerant parst eine Datei auch mit Syntaxfehlern. Der Parser von Go
+// findet nach einem Fehler in einem Funktionsrumpf oft nicht zurück und
+// verliert alle folgenden Deklarationen. Deshalb wird die Deklaration mit
+// dem ersten Fehler durch Leerzeichen ersetzt und die Datei erneut geparst,
+// bis keine Fehler mehr auftreten; spätere Fehler sind oft nur Folgefehler.
+// Zeilenumbrüche bleiben erhalten, so dass alle Positionen stimmen.
+// Geliefert werden der reparierte AST und die Fehler der Originaldatei.
+func parseTolerant(fset *token.FileSet, filePath string) (*ast.File, scanner.ErrorList, error) {
+	src, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	node, err := parser.ParseFile(fset, filePath, src, parser.ParseComments|parser.AllErrors)
+	var syntaxErrors scanner.ErrorList
+	if err == nil || !errors.As(err, &syntaxErrors) {
+		return node, nil, err
+	}
+
+	// Reparatur auf einem eigenen FileSet, nur das Ergebnis kommt in fset
+	repaired, list := src, syntaxErrors
+	for pass := 0; pass < maxRepairPasses; pass++ {
+		next := blankDecl(repaired, list[0].Pos.Offset)
+		if bytes.Equal(next, repaired) {
+			break
+		}
+		repaired = next
+		_, err := parser.ParseFile(token.NewFileSet(), filePath, repaired, parser.AllErrors)
+		if err == nil || !errors.As(err, &list) {
+			break
+		}
+	}
+	if !bytes.Equal(repaired, src) {
+		node, _ = parser.ParseFile(fset, filePath, repaired, parser.ParseComments|parser.AllErrors)
+	}
+	return node, syntaxErrors, nil
+}
+
+// blankDecl ersetzt die Deklaration auf oberster Ebene, die offset enthält,
+// durch Leerzeichen. Eine Deklaration reicht von ihrem Schlüsselwort am
+// Zeilenanfang bis zur nächsten. Fehler vor der ersten Deklaration
+// (package-Klausel, Importe) lassen sich so nicht beheben.
+func blankDecl(src []byte, offset int) []byte {
+	var starts []int
+	for offset := 0; offset < len(src); {
+		for _, keyword := range declKeywords {
+			if bytes.HasPrefix(src[offset:], keyword) {
+				starts = append(starts, offset)
+				break
+			}
+		}
+		next := bytes.IndexByte(src[offset:], '\n')
+		if next < 0 {
+			break
+		}
+		offset += next + 1
+	}
+
+	start, end := -1, len(src)
+	for _, s := range starts {
+		if s > offset {
+			end = s
+			break
+		}
+		start = s
+	}
+	if start < 0 {
+		return src
+	}
+
+	result := bytes.Clone(src)
+	for i := start; i < end; i++ {
+		if result[i] != '\n' {
+			result[i] = ' '
+		}
+	}
+	return result
+}
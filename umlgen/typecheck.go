@@ -0,0 +1,409 @@
+package umlgen
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Lademodi (--load)
+const (
+	LoadAST   = "ast"   // Nur Syntaxbaum, Typen werden über ihre Namen zugeordnet
+	LoadTypes = "types" // Zusätzlich Typprüfung mit go/types samt aufgelöster Importe
+)
+
+// WarningTypeCheck kennzeichnet Fehler der Typprüfung im Lademodus "types"
+const WarningTypeCheck = "type-check"
+
+// packageLoader prüft die geparsten Pakete mit go/types. Pakete des
+// analysierten Codes werden aus den bereits geparsten Dateien geprüft, alle
+// übrigen Importe (Standardbibliothek, andere Module und nicht analysierte
+// Pakete desselben Moduls) über go/packages.
+type packageLoader struct {
+	g        *UMLGenerator
+	files    map[string][]*ast.File    // Importpfad -> geparste Dateien
+	packages map[string]*types.Package // Geprüfte Pakete, nil während der Prüfung
+	fallback types.ImporterFrom
+}
+
+// typeCheck prüft alle geparsten Dateien und legt die Typinformationen im
+// Generator ab. Fehler der Typprüfung werden als Warnungen erfasst, die
+// Generierung läuft mit den bis dahin ermittelten Typen weiter.
+func (g *UMLGenerator) typeCheck() {
+	g.typesInfo = &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	g.typesPackages = make(map[string]bool)
+	g.typeObjects = make(map[Position]*types.TypeName)
+	if g.importer == nil {
+		g.importer = newPackagesImporter(g.options)
+	}
+
+	loader := &packageLoader{
+		g:        g,
+		files:    make(map[string][]*ast.File),
+		packages: make(map[string]*types.Package),
+		fallback: g.importer,
+	}
+	modules := make(map[string][2]string)
+	for _, filePath := range sortedMapKeys(g.files) {
+		file := g.files[filePath]
+		dir := filepath.Dir(filePath)
+		module, ok := modules[dir]
+		if !ok {
+			root, modulePath := findModule(dir)
+			module = [2]string{root, modulePath}
+			modules[dir] = module
+		}
+		importPath := packageImportPath(module[0], module[1], dir)
+		// Externe Testpakete (package foo_test) bilden ein eigenes Paket
+		if strings.HasSuffix(file.Name.Name, "_test") {
+			importPath += "_test"
+		}
+		loader.files[importPath] = append(loader.files[importPath], file)
+		g.typesPackages[importPath] = true
+	}
+
+	// Übrige Importe je Modul in einem Aufruf von go list vorab laden
+	imports := make(map[string][]string) // Modulverzeichnis -> Importpfade
+	for _, filePath := range sortedMapKeys(g.files) {
+		root := modules[filepath.Dir(filePath)][0]
+		for _, spec := range g.files[filePath].Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if _, analyzed := loader.files[importPath]; err == nil && !analyzed {
+				imports[root] = appendUnique(imports[root], importPath)
+			}
+		}
+	}
+	for _, root := range sortedMapKeys(imports) {
+		g.importer.load(root, imports[root])
+	}
+
+	for _, importPath := range sortedMapKeys(loader.files) {
+		loader.ImportFrom(importPath, "", 0)
+	}
+
+	// Paketweite Typdeklarationen über ihre Fundstelle zuordnen, da das
+	// Modell Typen nur über den Namen kennt
+	for ident, obj := range g.typesInfo.Defs {
+		if typeName, ok := obj.(*types.TypeName); ok && obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope() {
+			g.typeObjects[g.position(ident.Pos())] = typeName
+		}
+	}
+}
+
+// typeObject liefert das geprüfte Objekt eines Modelltyps
+func (g *UMLGenerator) typeObject(name string) *types.TypeName {
+	obj := g.typeObjects[g.typePosition(name)]
+	if obj == nil || obj.Name() != bareTypeName(name) {
+		return nil
+	}
+	return obj
+}
+
+// typesImplements prüft mit go/types, ob ein Typ oder ein Pointer darauf das
+// Interface implementiert. checked ist false, wenn keine Typinformation
+// vorliegt.
+func (g *UMLGenerator) typesImplements(typeName, interfaceName string) (implements, checked bool) {
+	if g.typesInfo == nil {
+		return false, false
+	}
+	typeObj, interfaceObj := g.typeObject(typeName), g.typeObject(interfaceName)
+	if typeObj == nil || interfaceObj == nil {
+		return false, false
+	}
+	iface, ok := interfaceObj.Type().Underlying().(*types.Interface)
+	if !ok || iface.NumMethods() == 0 {
+		return false, true
+	}
+	t := typeObj.Type()
+	return types.Implements(t, iface) || types.Implements(types.NewPointer(t), iface), true
+}
+
+// Import liefert ein geprüftes Paket und implementiert types.Importer
+func (l *packageLoader) Import(importPath string) (*types.Package, error) {
+	return l.ImportFrom(importPath, "", 0)
+}
+
+// ImportFrom implementiert types.ImporterFrom. Nicht analysierte Pakete
+// werden relativ zu dir gesucht, damit go/packages das Modul des importierenden
+// Pakets verwendet.
+func (l *packageLoader) ImportFrom(importPath, dir string, mode types.ImportMode) (*types.Package, error) {
+	if pkg, ok := l.packages[importPath]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("Importzyklus über %s", importPath)
+		}
+		return pkg, nil
+	}
+	files, ok := l.files[importPath]
+	if !ok {
+		return l.fallback.ImportFrom(importPath, dir, mode)
+	}
+
+	l.packages[importPath] = nil
+	reported := make(map[string]bool)
+	config := types.Config{
+		Importer: l,
+		Error: func(err error) {
+			typeErr, ok := err.(types.Error)
+			if !ok || reported[typeErr.Msg] {
+				return
+			}
+			reported[typeErr.Msg] = true
+			l.g.warn(WarningTypeCheck, l.g.position(typeErr.Pos), "%s", typeErr.Msg)
+		},
+	}
+	pkg, _ := config.Check(importPath, l.g.fset, files, l.g.typesInfo)
+	l.packages[importPath] = pkg
+	return pkg, nil
+}
+
+// packagesImporter lädt nicht analysierte Pakete (Standardbibliothek, andere
+// Module und nicht analysierte Pakete desselben Moduls) über go/packages. Das
+// go-Tool löst Importpfade dabei wie beim Bauen auf, samt replace-Direktiven,
+// Vendoring und go.work. Geprüft wird aus den Quellen, aber ohne
+// Funktionsrümpfe, die für Deklarationen nicht gebraucht werden. Geladene
+// Pakete bleiben über Neuaufbauten hinweg erhalten und haben ein eigenes
+// FileSet, da ihre Positionen im Modell nicht vorkommen.
+type packagesImporter struct {
+	options  Options
+	fset     *token.FileSet
+	packages map[[2]string]*types.Package // (Modulverzeichnis, Importpfad) -> Paket
+	failed   map[[2]string]error          // Nicht ladbare Pakete, werden nicht erneut versucht
+}
+
+// newPackagesImporter erzeugt einen Importer für die Build-Konfiguration
+// von options (--goos, --goarch, --tags)
+func newPackagesImporter(options Options) *packagesImporter {
+	return &packagesImporter{
+		options:  options,
+		fset:     token.NewFileSet(),
+		packages: make(map[[2]string]*types.Package),
+		failed:   make(map[[2]string]error),
+	}
+}
+
+// Import implementiert types.Importer
+func (p *packagesImporter) Import(importPath string) (*types.Package, error) {
+	return p.ImportFrom(importPath, ".", 0)
+}
+
+// ImportFrom implementiert types.ImporterFrom. Pakete, die load nicht schon
+// vorab geladen hat, werden einzeln im Modul von dir nachgeladen.
+func (p *packagesImporter) ImportFrom(importPath, dir string, mode types.ImportMode) (*types.Package, error) {
+	if importPath == "unsafe" {
+		return types.Unsafe, nil
+	}
+	root, _ := findModule(dir)
+	key := [2]string{root, importPath}
+	if _, ok := p.packages[key]; !ok {
+		p.load(root, []string{importPath})
+	}
+	if err := p.failed[key]; err != nil {
+		return nil, err
+	}
+	return p.packages[key], nil
+}
+
+// load lädt Pakete samt ihren Abhängigkeiten in einem Aufruf von go list
+// aus dem Modulverzeichnis root
+func (p *packagesImporter) load(root string, importPaths []string) {
+	var missing []string
+	for _, importPath := range importPaths {
+		key := [2]string{root, importPath}
+		if _, ok := p.packages[key]; ok || p.failed[key] != nil || importPath == "unsafe" || importPath == "C" {
+			continue
+		}
+		missing = append(missing, importPath)
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	ctx := buildContext(p.options)
+	config := &packages.Config{
+		Mode:      packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedImports | packages.NeedDeps,
+		Dir:       root,
+		Env:       append(os.Environ(), "GOOS="+ctx.GOOS, "GOARCH="+ctx.GOARCH),
+		Fset:      p.fset,
+		ParseFile: parseDeclarations,
+	}
+	if len(ctx.BuildTags) > 0 {
+		config.BuildFlags = []string{"-tags=" + strings.Join(ctx.BuildTags, ",")}
+	}
+	pkgs, err := packages.Load(config, missing...)
+	if err != nil {
+		for _, importPath := range missing {
+			p.failed[[2]string{root, importPath}] = err
+		}
+		return
+	}
+
+	// Nicht auffindbare Pakete liefert go/packages leer, aber mit ListError
+	for _, pkg := range pkgs {
+		for _, pkgErr := range pkg.Errors {
+			if pkgErr.Kind == packages.ListError {
+				p.failed[[2]string{root, pkg.PkgPath}] = errors.New(pkgErr.Msg)
+				break
+			}
+		}
+	}
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		key := [2]string{root, pkg.PkgPath}
+		if pkg.Types != nil && p.failed[key] == nil {
+			p.packages[key] = pkg.Types
+		}
+	})
+	for _, importPath := range missing {
+		key := [2]string{root, importPath}
+		if _, ok := p.packages[key]; !ok && p.failed[key] == nil {
+			p.failed[key] = fmt.Errorf("Paket %s nicht gefunden", importPath)
+		}
+	}
+}
+
+// parseDeclarations parst eine Datei eines importierten Pakets ohne
+// Funktionsrümpfe. Typfehler, die dadurch entstehen (ungenutzte Importe),
+// betreffen nur die Rümpfe und bleiben folgenlos.
+func parseDeclarations(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+	file, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	if file != nil {
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok {
+				funcDecl.Body = nil
+			}
+		}
+	}
+	return file, err
+}
+
+// findModule sucht ab dir aufwärts die go.mod und liefert deren Verzeichnis
+// und Modulpfad. Ohne go.mod sind beide leer.
+func findModule(dir string) (root, modulePath string) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", ""
+	}
+	for {
+		file, err := os.Open(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				fields := strings.Fields(scanner.Text())
+				if len(fields) >= 2 && fields[0] == "module" {
+					modulePath = strings.Trim(fields[1], "\"`")
+					break
+				}
+			}
+			file.Close()
+			return dir, modulePath
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// packageImportPath bildet den Importpfad eines Paketverzeichnisses. Außerhalb
+// eines Moduls dient der Verzeichnispfad als Importpfad.
+func packageImportPath(root, modulePath, dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	if root == "" || modulePath == "" {
+		return filepath.ToSlash(abs)
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == "." {
+		return modulePath
+	}
+	return path.Join(modulePath, filepath.ToSlash(rel))
+}
+
+// objectKey liefert den Modellschlüssel eines Typs aus einem analysierten
+// Paket
+func (g *UMLGenerator) objectKey(obj *types.TypeName) string {
+	file := g.files[g.fset.Position(obj.Pos()).Filename]
+	if file == nil {
+		return obj.Name()
+	}
+	return g.typeKey(g.fileScope(file), obj.Name())
+}
+
+// resolvedTarget bestimmt im Lademodus "types" den Elementtyp eines
+// Typausdrucks über go/types. Aliase fremder Pakete werden aufgelöst, und
+// Typen aus anderen analysierten Paketen (other.Handler) erhalten ihren
+// Modellnamen. Ohne
+// Typinformation bleibt das syntaktische Ergebnis target erhalten.
+func (g *UMLGenerator) resolvedTarget(expr ast.Expr, target string) string {
+	if g.typesInfo == nil {
+		return target
+	}
+	tv, ok := g.typesInfo.Types[expr]
+	if !ok || tv.Type == nil {
+		return target
+	}
+
+	t := tv.Type
+	for {
+		// Aliase aus analysierten Paketen sind selbst Teil des Modells
+		if alias, ok := t.(*types.Alias); ok {
+			if obj := alias.Obj(); obj.Pkg() != nil && g.typesPackages[obj.Pkg().Path()] {
+				return g.objectKey(obj)
+			}
+			t = types.Unalias(t)
+		}
+		switch u := t.(type) {
+		case *types.Pointer:
+			t = u.Elem()
+			continue
+		case *types.Slice:
+			t = u.Elem()
+			continue
+		case *types.Array:
+			t = u.Elem()
+			continue
+		case *types.Map:
+			t = u.Elem()
+			continue
+		case *types.Chan:
+			t = u.Elem()
+			continue
+		}
+		break
+	}
+
+	switch u := t.(type) {
+	case *types.Basic:
+		if u.Kind() == types.Invalid {
+			return target
+		}
+		return u.Name()
+	case *types.Named:
+		obj := u.Obj()
+		if obj.Pkg() == nil {
+			return obj.Name()
+		}
+		if g.typesPackages[obj.Pkg().Path()] {
+			return g.objectKey(obj)
+		}
+		return obj.Pkg().Name() + "." + obj.Name()
+	}
+	return target
+}
@@ -0,0 +1,225 @@
+package umlgen
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// uploadCacheFileName merkt sich im Ausgabeverzeichnis, welche Inhalte
+// bereits hochgeladen wurden (Ziel samt Endpunkt und Bucket -> SHA-256)
+const uploadCacheFileName = ".upload-cache.json"
+
+// uploadTarget ist ein Ziel in einem Objektspeicher (s3://bucket/prefix
+// oder gs://bucket/prefix)
+type uploadTarget struct {
+	scheme   string
+	bucket   string
+	prefix   string
+	endpoint string // Basis-URL, Bucket wird als Pfad angehängt
+	region   string
+	access   string
+	secret   string
+	token    string
+}
+
+// parseUploadTarget zerlegt die Ziel-URL und liest die Zugangsdaten aus der
+// Umgebung: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN,
+// AWS_REGION und AWS_ENDPOINT_URL (z.B. MinIO) für S3 bzw.
+// GCS_HMAC_ACCESS_KEY und GCS_HMAC_SECRET für die S3-kompatible
+// XML-API von Google Cloud Storage
+func parseUploadTarget(raw string) (*uploadTarget, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("ungültiges Upload-Ziel %q (erwartet s3://bucket/prefix oder gs://bucket/prefix)", raw)
+	}
+
+	target := &uploadTarget{scheme: u.Scheme, bucket: u.Host, prefix: strings.Trim(u.Path, "/")}
+	switch u.Scheme {
+	case "s3":
+		target.region = optionOr(os.Getenv("AWS_REGION"), "us-east-1")
+		target.endpoint = optionOr(os.Getenv("AWS_ENDPOINT_URL"), "https://s3."+target.region+".amazonaws.com")
+		target.access = os.Getenv("AWS_ACCESS_KEY_ID")
+		target.secret = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		target.token = os.Getenv("AWS_SESSION_TOKEN")
+	case "gs":
+		target.region = "auto"
+		target.endpoint = "https://storage.googleapis.com"
+		target.access = os.Getenv("GCS_HMAC_ACCESS_KEY")
+		target.secret = os.Getenv("GCS_HMAC_SECRET")
+	default:
+		return nil, fmt.Errorf("ungültiges Upload-Ziel %q (erlaubt: s3://, gs://)", raw)
+	}
+	if target.access == "" || target.secret == "" {
+		return nil, fmt.Errorf("keine Zugangsdaten für %s:// in der Umgebung gefunden", u.Scheme)
+	}
+	return target, nil
+}
+
+// objectURL liefert die URL eines Objekts (Pfad-Adressierung)
+func (t *uploadTarget) objectURL(key string) string {
+	return strings.TrimSuffix(t.endpoint, "/") + "/" + t.bucket + "/" + key
+}
+
+// cacheKey kennzeichnet ein Objekt im Upload-Cache. Schema, Endpunkt und
+// Bucket gehören dazu, damit ein Wechsel des Ziels bei gleichem Präfix
+// nicht als unverändert gilt.
+func (t *uploadTarget) cacheKey(key string) string {
+	return t.scheme + ":" + t.objectURL(key)
+}
+
+// UploadArtifacts lädt die Dateien des aktuellen Laufs samt Manifest hoch.
+// Dateien, deren Inhalts-Hash seit dem letzten Upload unverändert ist,
+// werden übersprungen.
+func (g *UMLGenerator) UploadArtifacts(outputDir, rawTarget string) error {
+	target, err := parseUploadTarget(rawTarget)
+	if err != nil {
+		return err
+	}
+
+	cachePath := filepath.Join(outputDir, uploadCacheFileName)
+	cache := make(map[string]string)
+	if data, err := os.ReadFile(cachePath); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+
+	paths := []string{manifestFileName}
+	for _, artifact := range g.artifacts {
+		paths = append(paths, artifact.Path)
+	}
+
+	uploaded := 0
+	for _, relPath := range paths {
+		data, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(relPath)))
+		if err != nil {
+			return fmt.Errorf("Fehler beim Lesen von %s: %v", relPath, err)
+		}
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+
+		key := path.Join(target.prefix, relPath)
+		if cache[target.cacheKey(key)] == hash {
+			continue
+		}
+		if err := target.put(key, data, hash); err != nil {
+			return fmt.Errorf("Fehler beim Hochladen von %s: %v", relPath, err)
+		}
+		cache[target.cacheKey(key)] = hash
+		uploaded++
+	}
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileFrom(cachePath, bytes.NewReader(data), g.fileMode()); err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Upload-Caches: %v", err)
+	}
+
+	fmt.Printf("Hochgeladen nach %s: %d Dateien (%d unverändert)\n", rawTarget, uploaded, len(paths)-uploaded)
+	return nil
+}
+
+// put lädt ein Objekt per signiertem PUT hoch
+func (t *uploadTarget) put(key string, data []byte, hash string) error {
+	req, err := http.NewRequest(http.MethodPut, t.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType(key))
+	req.Header.Set("X-Amz-Meta-Sha256", hash)
+	if t.token != "" {
+		req.Header.Set("X-Amz-Security-Token", t.token)
+	}
+	signV4(req, hash, t.access, t.secret, t.region, "s3", time.Now())
+
+	client := &http.Client{Timeout: renderTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// contentType bestimmt den Content-Type nach der Dateiendung
+func contentType(key string) string {
+	switch path.Ext(key) {
+	case ".png":
+		return "image/png"
+	case ".json":
+		return "application/json"
+	case ".svg":
+		return "image/svg+xml"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// signV4 signiert eine Anfrage nach AWS Signature Version 4. Signiert werden
+// Host und alle gesetzten Header; payloadHash ist die SHA-256 des Inhalts.
+func signV4(req *http.Request, payloadHash, access, secret, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		access, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,127 @@
+package umlgen
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// Arten von Warnungen für heuristische Unsicherheiten
+const (
+	WarningUnresolvedType     = "unresolved-type"     // Feldtyp weder bekannt noch eingebaut
+	WarningUnresolvedReceiver = "unresolved-receiver" // Methode ohne bekannten Receiver-Typ
+	WarningNameOnlyMatch      = "name-only-match"     // Methodennamen passen zum Interface, Signaturen nicht
+	WarningSkippedFile        = "skipped-file"        // Datei wurde nicht verarbeitet
+	WarningSyntaxError        = "syntax-error"        // Datei enthält Syntaxfehler und wurde nur teilweise verarbeitet
+	WarningBudget             = "budget"              // Zeitbudget überschritten, Diagramm ist unvollständig
+)
+
+// Warning beschreibt eine Unsicherheit bei der Analyse
+type Warning struct {
+	Kind    string   `json:"kind"`
+	Message string   `json:"message"`
+	Pos     Position `json:"pos"`
+}
+
+// String formatiert die Warnung für die Konsole
+func (w Warning) String() string {
+	if w.Pos.File == "" {
+		return fmt.Sprintf("Warnung [%s]: %s", w.Kind, w.Message)
+	}
+	return fmt.Sprintf("Warnung [%s] %s: %s", w.Kind, w.Pos, w.Message)
+}
+
+// warn erfasst eine Warnung
+func (g *UMLGenerator) warn(kind string, pos Position, format string, args ...any) {
+	g.warnings = append(g.warnings, Warning{Kind: kind, Message: fmt.Sprintf(format, args...), Pos: pos})
+}
+
+// Warnings liefert alle Warnungen der letzten Generierung
+func (g *UMLGenerator) Warnings() []Warning {
+	return g.warnings
+}
+
+// printWarnings gibt Warnungen auf der Konsole aus
+func printWarnings(warnings []Warning) {
+	for _, w := range warnings {
+		fmt.Println(w)
+	}
+}
+
+// builtinTypes enthält die vordeklarierten Typen von Go
+var builtinTypes = map[string]bool{
+	"bool": true, "string": true, "error": true, "any": true, "comparable": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+	"byte": true, "rune": true,
+}
+
+// isUnresolvedType prüft, ob ein unqualifizierter Typname weder eingebaut,
+// noch Typparameter, noch im Modell bekannt ist
+func (g *UMLGenerator) isUnresolvedType(name string, typeParams []string) bool {
+	if name == "" || builtinTypes[name] || g.isKnownType(name) {
+		return false
+	}
+	// Typen aus anderen Paketen (pkg.Typ) werden nicht aufgelöst
+	for _, r := range name {
+		if r == '.' {
+			return false
+		}
+	}
+	for _, param := range typeParams {
+		if param == name {
+			return false
+		}
+	}
+	return true
+}
+
+// sameSignature vergleicht Parameter- und Rückgabetypen zweier Methoden.
+// Jeder Typ wird im Gültigkeitsbereich der deklarierenden Datei auf seinen
+// Modellschlüssel abgebildet, sodass *store.User in Paket mem und *User in
+// Paket store übereinstimmen.
+func (g *UMLGenerator) sameSignature(a, b MethodInfo) bool {
+	return slices.Equal(g.signatureTypes(a), g.signatureTypes(b))
+}
+
+// signatureTypes liefert die normalisierten Parameter- und Rückgabetypen
+// einer Methode, getrennt durch "->"
+func (g *UMLGenerator) signatureTypes(m MethodInfo) []string {
+	var types []string
+	for _, param := range m.Parameters {
+		types = append(types, param.Type)
+	}
+	types = append(types, "->")
+	types = append(types, m.resultTypes()...)
+
+	// Methoden aus --merge oder Overlay haben keine analysierte Datei
+	file, ok := g.files[m.Pos.File]
+	if !ok {
+		return types
+	}
+	for i, typeName := range types {
+		types[i] = qualifiedIdentPattern.ReplaceAllStringFunc(typeName, func(name string) string {
+			return g.modelTarget(file, name)
+		})
+	}
+	return types
+}
+
+// qualifiedIdentPattern findet Typnamen samt Paketqualifizierer in einer
+// Typangabe, z.B. store.User in map[string]*store.User
+var qualifiedIdentPattern = regexp.MustCompile(`[\pL_][\pL\pN_]*(?:\.[\pL_][\pL\pN_]*)?`)
+
+// typePosition liefert die Definitionsstelle eines Typs
+func (g *UMLGenerator) typePosition(name string) Position {
+	if structInfo, ok := g.structs[name]; ok {
+		return structInfo.Pos
+	}
+	if interfaceInfo, ok := g.interfaces[name]; ok {
+		return interfaceInfo.Pos
+	}
+	if namedInfo, ok := g.namedTypes[name]; ok {
+		return namedInfo.Pos
+	}
+	return Position{}
+}
@@ -0,0 +1,94 @@
+package umlgen
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// workspaceModules liest die go.work in dir und liefert die Verzeichnisse der
+// Module aus ihren use-Direktiven, sowohl einzeln (use ./a) als auch im Block
+// (use ( ./a ./b )). Ohne go.work ist das Ergebnis leer.
+func workspaceModules(dir string) ([]string, error) {
+	file, err := os.Open(filepath.Join(dir, "go.work"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Lesen der go.work: %v", err)
+	}
+	defer file.Close()
+
+	var modules []string
+	use := func(modulePath string) {
+		modulePath = strings.Trim(modulePath, "\"`")
+		if !filepath.IsAbs(modulePath) {
+			modulePath = filepath.Join(dir, modulePath)
+		}
+		modules = appendUnique(modules, filepath.Clean(modulePath))
+	}
+
+	inBlock := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case inBlock && fields[0] == ")":
+			inBlock = false
+		case inBlock:
+			use(fields[0])
+		case fields[0] == "use" && len(fields) >= 2 && fields[1] == "(":
+			inBlock = true
+		case fields[0] == "use" && len(fields) >= 2:
+			use(fields[1])
+		}
+	}
+	return modules, scanner.Err()
+}
+
+// moduleFileName liefert den Dateinamen des Diagramms eines Moduls. Er
+// enthält den vollständigen Modulpfad, weil das letzte Element allein nicht
+// eindeutig ist (shop/api und billing/api, api und api/v2).
+func moduleFileName(modulePath string) string {
+	return "uml_module_" + strings.NewReplacer("/", "_", ".", "_", "-", "_").Replace(modulePath)
+}
+
+// GenerateModuleDiagrams schreibt ein Diagramm je Modul (uml_module_<modulpfad>,
+// --workspace modules). Typen anderer Module, mit denen ein Modul verbunden
+// ist, erscheinen dort nur mit Namen.
+func (g *UMLGenerator) GenerateModuleDiagrams(outputDir string) error {
+	modulePaths := make(map[string]string) // Verzeichnis -> Modulpfad
+	byModule := make(map[string]map[string]bool)
+	for _, typeInfo := range g.Types() {
+		dir := filepath.Dir(typeInfo.Pos.File)
+		modulePath, ok := modulePaths[dir]
+		if !ok {
+			_, modulePath = findModule(dir)
+			modulePaths[dir] = modulePath
+		}
+		if byModule[modulePath] == nil {
+			byModule[modulePath] = make(map[string]bool)
+		}
+		byModule[modulePath][typeInfo.Name] = true
+	}
+
+	for _, modulePath := range sortedMapKeys(byModule) {
+		if modulePath == "" {
+			continue // Dateien außerhalb eines Moduls
+		}
+		sub := g.neighborhood(byModule[modulePath])
+		err := sub.writeDiagram(outputDir, moduleFileName(modulePath), sub.GeneratePlantUML())
+		g.artifacts = append(g.artifacts, sub.artifacts...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Package umltrace zeichnet Methodenaufrufe eines laufenden Programms auf,
+// damit go-uml-generator daraus ein Sequenzdiagramm erzeugen kann.
+//
+// Einbinden mit
+//
+//	go get github.com/nichtaru64/go-uml-generator/umltrace
+//
+// Verwendung:
+//
+//	umltrace.Start("trace.jsonl")
+//	defer umltrace.Stop()
+//
+//	func (r *Repo) Add(u User) {
+//		defer umltrace.Enter("Repo", "Add")()
+//		...
+//	}
+//
+// Das Diagramm entsteht anschließend mit
+//
+//	go-uml-generator sequence trace.jsonl
+package umltrace
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event ist ein aufgezeichneter Methodeneintritt oder -austritt. Die
+// Aufzeichnung enthält ein Event pro Zeile als JSON.
+type Event struct {
+	Kind      string `json:"kind"` // "enter" oder "exit"
+	Goroutine uint64 `json:"goroutine"`
+	Type      string `json:"type"`
+	Method    string `json:"method"`
+	Time      int64  `json:"time"` // Nanosekunden seit Start
+}
+
+var (
+	mu      sync.Mutex
+	file    *os.File
+	out     *bufio.Writer
+	encoder *json.Encoder
+	started time.Time
+)
+
+// Start beginnt die Aufzeichnung in die angegebene Datei. Ohne Start sind
+// Enter und die zurückgegebenen Funktionen wirkungslos.
+func Start(path string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if file != nil {
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	file = f
+	out = bufio.NewWriter(f)
+	encoder = json.NewEncoder(out)
+	started = time.Now()
+	return nil
+}
+
+// Stop beendet die Aufzeichnung und schreibt alle Events auf die Platte
+func Stop() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == nil {
+		return nil
+	}
+	err := out.Flush()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	file, out, encoder = nil, nil, nil
+	return err
+}
+
+// Enter zeichnet den Eintritt in typeName.method auf und liefert die
+// Funktion, die den Austritt aufzeichnet, typischerweise per
+// defer umltrace.Enter("Repo", "Add")()
+func Enter(typeName, method string) func() {
+	if !record("enter", typeName, method) {
+		return func() {}
+	}
+	return func() {
+		record("exit", typeName, method)
+	}
+}
+
+// record schreibt ein Event, sofern die Aufzeichnung läuft
+func record(kind, typeName, method string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if encoder == nil {
+		return false
+	}
+	encoder.Encode(Event{
+		Kind:      kind,
+		Goroutine: goroutineID(),
+		Type:      typeName,
+		Method:    method,
+		Time:      time.Since(started).Nanoseconds(),
+	})
+	return true
+}
+
+// goroutineID liest die Nummer der aktuellen Goroutine aus dem Stack-Kopf
+// ("goroutine 7 [running]:"). Die Laufzeit bietet dafür keine API; die
+// Nummer dient nur dazu, verschachtelte Aufrufe je Goroutine zuzuordnen.
+func goroutineID() uint64 {
+	var buf [64]byte
+	stack := buf[:runtime.Stack(buf[:], false)]
+	stack = bytes.TrimPrefix(stack, []byte("goroutine "))
+	if i := bytes.IndexByte(stack, ' '); i >= 0 {
+		stack = stack[:i]
+	}
+	id, _ := strconv.ParseUint(string(stack), 10, 64)
+	return id
+}